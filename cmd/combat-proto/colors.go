@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"text-adventure-v2/pixelbuf"
+)
+
+// With -colors auto, the frame is drawn with as many colors as the terminal
+// reports it can show; any other mode is used whatever the terminal says.
+// -encoding picks the characters the pixels are packed into.
+
+var (
+	colorsFlag   = flag.String("colors", "auto", "color mode: auto, "+strings.Join(pixelbuf.ModeNames(), ", "))
+	encodingFlag = flag.String("encoding", "half", "characters to draw with: "+strings.Join(pixelbuf.EncodingNames(), ", "))
+)
+
+// renderOpts is how to draw as chosen with -colors and -encoding. With
+// -colors auto its depth is replaced once the terminal's profile is known.
+var renderOpts pixelbuf.Options
+
+// loadRenderOpts reads the color mode and encoding named by -colors and
+// -encoding.
+func loadRenderOpts() error {
+	var err error
+	if *colorsFlag != "auto" {
+		if renderOpts, err = pixelbuf.ParseOptions(*colorsFlag); err != nil {
+			return err
+		}
+	}
+	renderOpts.Encoding, err = pixelbuf.ParseEncoding(*encodingFlag)
+	return err
+}
@@ -1,12 +1,22 @@
 // Combat prototype testbed — standalone Bubble Tea app for tuning the
-// combat engine. Wires combat/engine to pixelbuf for half-block rendering.
+// combat engine. Draws combat/engine with combat/scene and shows it with
+// pixelbuf's half-block rendering.
 //
-// Keys: A/D = move, Space = jump, F = attack, R = restart, Q/Esc = quit
+// Keys: A/D = move, Space = jump, F = attack, R = restart, T = tuning
+// overlay (see tuning.go), Q/Esc = quit
+//
+// Flags: -arena NAME picks an arena, -enemies N fights N enemies at once (up
+// to the arena's number of enemy spawns), -tuning FILE starts from a tuning
+// file and -tuning-out FILE is where the overlay exports to. -record FILE
+// records the fight and -replay FILE plays a recording back (see replay.go).
+// -colors MODE draws with fewer colors and -encoding quadrant or braille
+// with more pixels to a character (see colors.go).
 package main
 
 import (
 	"bytes"
 	"encoding/binary"
+	"flag"
 	"fmt"
 	"math"
 	"os"
@@ -14,24 +24,13 @@ import (
 	"time"
 
 	"text-adventure-v2/combat/engine"
+	"text-adventure-v2/combat/scene"
 	"text-adventure-v2/pixelbuf"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/ebitengine/oto/v3"
 )
 
-// Colors.
-var (
-	bgColor    = pixelbuf.Color{R: 20, G: 20, B: 30, A: 255}
-	platColor  = pixelbuf.Color{R: 80, G: 80, B: 100, A: 255}
-	playerCol  = pixelbuf.Color{R: 100, G: 200, B: 255, A: 255}
-	enemyCol   = pixelbuf.Color{R: 255, G: 80, B: 80, A: 255}
-	attackCol  = pixelbuf.Color{R: 255, G: 255, B: 100, A: 255}
-	hpFullCol  = pixelbuf.Color{R: 80, G: 220, B: 80, A: 255}
-	hpEmptyCol = pixelbuf.Color{R: 80, G: 20, B: 20, A: 255}
-	whiteCol   = pixelbuf.Color{R: 255, G: 255, B: 255, A: 255}
-)
-
 const (
 	tickDuration = time.Second / time.Duration(engine.TickRate)
 	hudRows      = 3 // rows reserved below the frame for HUD text
@@ -52,6 +51,29 @@ const (
 	keyReset = "r"
 )
 
+var (
+	arenaFlag   = flag.String("arena", engine.DefaultArena, "arena: "+strings.Join(engine.Arenas(), ", "))
+	enemiesFlag = flag.Int("enemies", 1, "number of enemies to fight")
+)
+
+// arena is the arena chosen with -arena, loaded in main.
+var arena engine.Arena
+
+// newEngine creates an engine in the chosen arena with as many enemies as
+// -enemies asks for.
+func newEngine(tuning engine.Tuning) *engine.Engine {
+	return engine.NewEngineIn(arena, max(*enemiesFlag, 1), tuning)
+}
+
+// enemyHP totals the health of every enemy, to notice hits.
+func enemyHP(eng *engine.Engine) int {
+	total := 0
+	for _, enemy := range eng.Enemies {
+		total += enemy.HP
+	}
+	return total
+}
+
 type tickMsg time.Time
 
 func tick() tea.Cmd {
@@ -143,7 +165,8 @@ func initSounds() {
 type model struct {
 	eng   *engine.Engine
 	buf   *pixelbuf.Buffer
-	scale float64 // render scale: engine pixels -> buffer pixels
+	scale float64          // render scale: engine pixels -> buffer pixels
+	opts  pixelbuf.Options // see colors.go
 
 	// Input mode (set once when KeyboardEnhancementsMsg arrives).
 	hasKeyReleases bool
@@ -156,20 +179,32 @@ type model struct {
 	fallbackKeys map[string]time.Time // last-seen time per key
 	prevHeld     map[string]bool      // previous tick's held snapshot (edge detection)
 
+	// Tuning overlay.
+	showTuning bool
+	tuneSel    int    // index into Tuning.Params
+	tuneStatus string // result of the last export
+
+	// Recording and playback.
+	rec       *engine.Replay // the fight being recorded, if any
+	played    int            // inputs played back so far
+	recStatus string         // result of the last save
+
 	width  int
 	height int
 	frame  string
 }
 
-func newModel() model {
-	return model{
-		eng:          engine.NewEngine(),
+func newModel(tuning engine.Tuning) model {
+	m := model{
 		held:         make(map[string]bool),
 		pressed:      make(map[string]bool),
 		prevHeld:     make(map[string]bool),
 		fallbackKeys: make(map[string]time.Time),
 		scale:        1.0,
+		opts:         renderOpts,
 	}
+	m.startFight(tuning)
+	return m
 }
 
 // resizeBuf recomputes the pixel buffer and scale to fit the terminal.
@@ -177,24 +212,33 @@ func (m *model) resizeBuf() {
 	if m.width < 20 || m.height < 5 {
 		return
 	}
-	// Available pixel space: full width, height minus HUD rows (half-block = 2 px/row).
-	maxW := m.width
-	maxH := (m.height - hudRows) * 2
-
-	scaleX := float64(maxW) / float64(engine.ArenaWidth)
-	scaleY := float64(maxH) / float64(engine.ArenaHeight)
+	// Available pixel space: full width, height minus HUD rows, in the
+	// encoding's pixels per cell (half-block = 1×2).
+	cw, ch := m.opts.Encoding.CellSize()
+	maxW := m.width * cw
+	maxH := (m.height - m.reservedRows()) * ch
+
+	scaleX := float64(maxW) / arena.Width
+	scaleY := float64(maxH) / arena.Height
 	m.scale = min(scaleX, scaleY)
 
-	bufW := int(float64(engine.ArenaWidth) * m.scale)
-	bufH := int(float64(engine.ArenaHeight) * m.scale)
-	// Half-block rendering needs even height.
-	bufH = bufH &^ 1
+	// Whole cells only: a part-filled one would show transparent black.
+	bufW := int(arena.Width*m.scale) / cw * cw
+	bufH := int(arena.Height*m.scale) / ch * ch
 
 	if m.buf == nil || m.buf.Width != bufW || m.buf.Height != bufH {
 		m.buf = pixelbuf.NewBuffer(bufW, bufH)
 	}
 }
 
+// reservedRows is how many rows below the frame are kept for text.
+func (m *model) reservedRows() int {
+	if m.showTuning {
+		return hudRows + tuningRows
+	}
+	return hudRows
+}
+
 func (m model) Init() tea.Cmd {
 	return tick()
 }
@@ -205,13 +249,32 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.hasKeyReleases = msg.SupportsEventTypes()
 		return m, nil
 
+	case tea.ColorProfileMsg:
+		if *colorsFlag == "auto" {
+			m.opts = pixelbuf.OptionsFor(msg.Profile, m.opts.Encoding)
+		}
+		return m, nil
+
 	case tea.KeyPressMsg:
 		key := msg.String()
 		switch key {
 		case "q", "esc", "ctrl+c":
+			if m.eng.Result == engine.ResultNone {
+				m.saveRecording()
+			}
 			return m, tea.Quit
 		case keyReset:
-			m.eng.Reset()
+			if m.eng.Result == engine.ResultNone {
+				m.saveRecording()
+			}
+			m.startFight(m.eng.Tuning) // keep live tuning across restarts
+			return m, nil
+		case keyTuning:
+			m.showTuning = !m.showTuning
+			m.resizeBuf()
+			return m, nil
+		}
+		if m.showTuning && m.tuningKey(key) {
 			return m, nil
 		}
 		if m.hasKeyReleases {
@@ -237,16 +300,28 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tickMsg:
 		now := time.Now()
 		input := m.buildInput(now)
+		if playback != nil {
+			var ok bool
+			if input, ok = m.nextInput(); !ok {
+				return m, tick() // the recording is over; hold the last frame
+			}
+		}
+		m.record(input)
 
 		prevState := m.eng.Player.State
-		prevHP := m.eng.Enemy.HP
+		prevHP := enemyHP(m.eng)
+		prevPlayerHP := m.eng.Player.HP
+		prevResult := m.eng.Result
 
 		m.eng.Tick(input)
+		if prevResult == engine.ResultNone && m.eng.Result != engine.ResultNone {
+			m.saveRecording()
+		}
 
 		if m.eng.Player.State == engine.StateJump && prevState != engine.StateJump {
 			playSound(jumpSamples)
 		}
-		if m.eng.Enemy.HP < prevHP {
+		if enemyHP(m.eng) < prevHP || m.eng.Player.HP < prevPlayerHP {
 			playSound(hitSamples)
 		}
 
@@ -290,75 +365,12 @@ func (m *model) buildInput(now time.Time) engine.InputState {
 	return input
 }
 
-// s scales an engine coordinate to buffer pixels.
-func (m *model) s(v float64) int {
-	return int(v * m.scale)
-}
-
 func (m *model) renderFrame() {
 	if m.buf == nil {
 		return
 	}
-	e := m.eng
-	buf := m.buf
-
-	buf.Clear(bgColor)
-
-	// Platforms.
-	for _, p := range e.Platforms {
-		r := p.Rect
-		pixelbuf.FillRect(buf, m.s(r.X), m.s(r.Y), m.s(r.W), m.s(r.H), platColor)
-	}
-
-	// Enemy.
-	if e.Enemy.Alive {
-		col := enemyCol
-		if e.Enemy.HurtTimer > 0 {
-			if int(e.Enemy.HurtTimer*20)%2 == 0 {
-				col = whiteCol
-			}
-		}
-		pixelbuf.FillRect(buf, m.s(e.Enemy.Pos.X), m.s(e.Enemy.Pos.Y),
-			m.s(e.Enemy.Pos.W), m.s(e.Enemy.Pos.H), col)
-	}
-
-	// Player.
-	col := playerCol
-	if e.Player.InvincTimer > 0 {
-		if int(e.Player.InvincTimer*10)%2 == 0 {
-			col = pixelbuf.Color{R: 40, G: 80, B: 100, A: 255}
-		}
-	}
-	pixelbuf.FillRect(buf, m.s(e.Player.Pos.X), m.s(e.Player.Pos.Y),
-		m.s(e.Player.Pos.W), m.s(e.Player.Pos.H), col)
-
-	// Attack hitbox.
-	hb := engine.AttackHitbox(&e.Player)
-	if hb.W > 0 {
-		pixelbuf.FillRect(buf, m.s(hb.X), m.s(hb.Y), m.s(hb.W), m.s(hb.H), attackCol)
-	}
-
-	// HP pips at top of screen.
-	pipW := max(2, m.s(3))
-	pipH := max(2, m.s(3))
-	pipGap := max(1, m.s(1))
-	drawHP(buf, m.s(6), m.s(2), pipW, pipH, pipGap, e.Player.HP, e.Player.MaxHP)
-	if e.Enemy.Alive {
-		enemyHPx := buf.Width - m.s(6) - e.Enemy.MaxHP*(pipW+pipGap)
-		drawHP(buf, enemyHPx, m.s(2), pipW, pipH, pipGap, e.Enemy.HP, e.Enemy.MaxHP)
-	}
-
-	m.frame = pixelbuf.Render(buf)
-}
-
-func drawHP(buf *pixelbuf.Buffer, x, y, pipW, pipH, pipGap, hp, maxHP int) {
-	for i := 0; i < maxHP; i++ {
-		col := hpEmptyCol
-		if i < hp {
-			col = hpFullCol
-		}
-		pixelbuf.FillRect(buf, x+i*(pipW+pipGap), y, pipW, pipH, col)
-	}
+	scene.Draw(m.buf, m.eng, m.scale)
+	m.frame = pixelbuf.RenderWith(m.buf, m.opts)
 }
 
 func (m model) View() tea.View {
@@ -383,9 +395,17 @@ func (m model) View() tea.View {
 				mode = "KR"
 			}
 			sb.WriteString(fmt.Sprintf(
-				"  A/D move  SPACE jump  F attack  R restart  Q quit  [%s]", mode))
+				"  A/D move  SPACE jump  F attack  R restart  T tuning  Q quit  [%s]", mode))
+		}
+		sb.WriteByte('\n')
+		if status := m.replayMode() + "  " + m.recStatus; strings.TrimSpace(status) != "" {
+			sb.WriteString(status)
 		}
 		sb.WriteByte('\n')
+		if m.showTuning {
+			sb.WriteString(m.tuningView())
+			sb.WriteByte('\n')
+		}
 
 		content = sb.String()
 	}
@@ -397,10 +417,30 @@ func (m model) View() tea.View {
 }
 
 func main() {
+	flag.Parse()
+	var err error
+	if arena, err = engine.LoadArena(*arenaFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	tuning, err := loadTuning()
+	if err == nil {
+		err = arena.ValidateFor(tuning)
+	}
+	if err == nil {
+		err = loadPlayback()
+	}
+	if err == nil {
+		err = loadRenderOpts()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
 	initAudio()
 	initSounds()
 
-	p := tea.NewProgram(newModel())
+	p := tea.NewProgram(newModel(tuning))
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"text-adventure-v2/combat/engine"
+)
+
+// With -record, every tick's input is recorded and the fight is written out
+// when it ends, on restart and on quit. With -replay, a recording is played
+// back instead of the keyboard; it sets its own arena, enemies and tuning.
+// Changing the tuning mid-fight would make a recording unreproducible, so it
+// stops the recording until the next restart.
+
+var (
+	recordFlag = flag.String("record", "", "file to record the fight to")
+	replayFlag = flag.String("replay", "", "recorded fight to play back")
+)
+
+// playback is the recording loaded with -replay, if any.
+var playback *engine.Replay
+
+// loadPlayback loads the recording named by -replay, if any, and makes its
+// arena the one to fight in.
+func loadPlayback() error {
+	if *replayFlag == "" {
+		return nil
+	}
+	var err error
+	if playback, err = engine.LoadReplay(*replayFlag); err != nil {
+		return err
+	}
+	arena = playback.Arena
+	return nil
+}
+
+// startFight sets up a fresh engine, and a fresh recording or playback.
+func (m *model) startFight(tuning engine.Tuning) {
+	m.rec, m.played = nil, 0
+	if playback != nil {
+		m.eng = playback.Engine()
+		return
+	}
+	m.eng = newEngine(tuning)
+	if *recordFlag != "" {
+		m.rec = engine.NewReplay(m.eng)
+	}
+}
+
+// nextInput returns the next recorded input during playback, and whether
+// there was one.
+func (m *model) nextInput() (engine.InputState, bool) {
+	if m.played >= len(playback.Inputs) {
+		return engine.InputState{}, false
+	}
+	m.played++
+	return playback.Inputs[m.played-1], true
+}
+
+// record notes the input for the coming tick. Ticks after the fight is over
+// change nothing, so they are left out.
+func (m *model) record(input engine.InputState) {
+	if m.rec != nil && m.eng.Result == engine.ResultNone {
+		m.rec.Record(input)
+	}
+}
+
+// saveRecording writes the recording made so far to -record.
+func (m *model) saveRecording() {
+	if m.rec == nil || len(m.rec.Inputs) == 0 {
+		return
+	}
+	outcome := m.eng.Outcome()
+	m.rec.Outcome = &outcome
+	if err := m.rec.Save(*recordFlag); err != nil {
+		m.recStatus = "recording not saved: " + err.Error()
+	} else {
+		m.recStatus = fmt.Sprintf("recorded %d ticks to %s", len(m.rec.Inputs), *recordFlag)
+	}
+}
+
+// tuningChanged stops the recording, which no longer matches the fight.
+func (m *model) tuningChanged() {
+	if m.rec != nil {
+		m.rec = nil
+		m.recStatus = "recording stopped: tuning changed (R to record again)"
+	}
+}
+
+// replayMode describes recording or playback for the HUD.
+func (m *model) replayMode() string {
+	switch {
+	case playback != nil:
+		return fmt.Sprintf("  REPLAY %d/%d", m.played, len(playback.Inputs))
+	case m.rec != nil:
+		return fmt.Sprintf("  REC %d", len(m.rec.Inputs))
+	}
+	return ""
+}
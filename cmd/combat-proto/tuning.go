@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"text-adventure-v2/combat/engine"
+)
+
+// The tuning overlay edits the engine's Tuning while the fight runs. T opens
+// and closes it; while it is open, Up/Down pick a value, Left/Right nudge it,
+// 0 restores the default and X exports the lot to -tuning-out. Movement keys
+// keep working, so a change can be felt straight away.
+
+const (
+	keyTuning  = "t"
+	keyExport  = "x"
+	tuningRows = 7 // rows the overlay adds below the HUD
+	tuningShow = 5 // values listed around the selected one
+)
+
+var (
+	tuningFlag    = flag.String("tuning", "", "tuning file to start from (default: built-in tuning)")
+	tuningOutFlag = flag.String("tuning-out", "tuning.json", "file the tuning overlay exports to")
+)
+
+// loadTuning returns the tuning named by -tuning, or the default.
+func loadTuning() (engine.Tuning, error) {
+	if *tuningFlag == "" {
+		return engine.DefaultTuning(), nil
+	}
+	return engine.LoadTuning(*tuningFlag)
+}
+
+// tuningKey handles a key press while the overlay is open, reporting
+// whether it was one of the overlay's keys.
+func (m *model) tuningKey(key string) bool {
+	params := m.eng.Tuning.Params()
+	p := params[m.tuneSel]
+	switch key {
+	case "left", "right", "0":
+		if playback != nil {
+			m.tuneStatus = "a replay keeps its recorded tuning"
+			return true
+		}
+		defer m.tuningChanged()
+	}
+	switch key {
+	case "up":
+		m.tuneSel = (m.tuneSel + len(params) - 1) % len(params)
+	case "down":
+		m.tuneSel = (m.tuneSel + 1) % len(params)
+	case "left":
+		*p.Value = max(p.Step, *p.Value-p.Step) // never zero or below
+	case "right":
+		*p.Value += p.Step
+	case "0":
+		defaults := engine.DefaultTuning()
+		*p.Value = *defaults.Params()[m.tuneSel].Value
+	case keyExport:
+		if err := m.eng.Tuning.Validate(); err != nil {
+			m.tuneStatus = err.Error()
+		} else if err := m.eng.Tuning.Save(*tuningOutFlag); err != nil {
+			m.tuneStatus = "export failed: " + err.Error()
+		} else {
+			m.tuneStatus = "exported to " + *tuningOutFlag
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// tuningView renders the overlay: a help line, the values around the
+// selected one, and the last export's status. Changed values are starred.
+func (m *model) tuningView() string {
+	params := m.eng.Tuning.Params()
+	defaults := engine.DefaultTuning()
+	defaultParams := defaults.Params()
+
+	var sb strings.Builder
+	sb.WriteString("  TUNING  Up/Down pick  Left/Right adjust  0 default  X export  T close\n")
+	first := min(max(m.tuneSel-tuningShow/2, 0), len(params)-tuningShow)
+	for i := first; i < first+tuningShow; i++ {
+		cursor, changed := "  ", " "
+		if i == m.tuneSel {
+			cursor = "> "
+		}
+		if *params[i].Value != *defaultParams[i].Value {
+			changed = "*"
+		}
+		fmt.Fprintf(&sb, "  %s%-20s %10.4g%s (default %.4g)\n",
+			cursor, params[i].Name, *params[i].Value, changed, *defaultParams[i].Value)
+	}
+	sb.WriteString("  " + m.tuneStatus)
+	return sb.String()
+}
@@ -0,0 +1,156 @@
+// Combat simulator — plays combat/engine fights headless, with bots in the
+// player's place, and reports how they went: win rate, time to kill, damage
+// taken and hit accuracy for each bot in each arena. Use it to tune enemy
+// health, damage and timings from data.
+//
+//	go run ./cmd/combat-sim -fights 2000 -enemies 2 -enemy-hp 4
+//
+// Flags: -fights N per bot and arena, -bots and -arenas pick which (comma
+// separated, default all), -enemies N at once, -seed N, -tuning FILE,
+// -enemy-hp N and -damage N override the engine's defaults, -max-time caps
+// a fight, and -csv writes CSV instead of a table.
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"text-adventure-v2/combat/engine"
+	"text-adventure-v2/combat/sim"
+)
+
+var (
+	fightsFlag  = flag.Int("fights", 1000, "fights per bot and arena")
+	botsFlag    = flag.String("bots", "", "comma-separated bots to run: "+strings.Join(sim.BotNames(), ", ")+" (default all)")
+	arenasFlag  = flag.String("arenas", "", "comma-separated arenas to fight in (default all)")
+	enemiesFlag = flag.Int("enemies", 1, "enemies per fight, up to the arena's spawns")
+	seedFlag    = flag.Int64("seed", 1, "random seed; the same seed plays the same fights")
+	tuningFlag  = flag.String("tuning", "", "tuning file (default: built-in tuning)")
+	enemyHPFlag = flag.Int("enemy-hp", engine.EnemyHP, "enemy health")
+	damageFlag  = flag.Int("damage", engine.AttackDamage, "damage per player hit")
+	maxTimeFlag = flag.Duration("max-time", time.Minute, "game time after which a fight counts as a timeout")
+	csvFlag     = flag.Bool("csv", false, "write CSV instead of a table")
+)
+
+// row is one line of the report: a bot's fights in one arena.
+type row struct {
+	bot, arena string
+	enemies    int
+	sim.Summary
+}
+
+func main() {
+	flag.Parse()
+	if err := run(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer) error {
+	if *fightsFlag < 1 || *enemiesFlag < 1 || *enemyHPFlag < 1 || *damageFlag < 1 {
+		return fmt.Errorf("-fights, -enemies, -enemy-hp and -damage must be at least 1")
+	}
+	tuning := engine.DefaultTuning()
+	if *tuningFlag != "" {
+		var err error
+		if tuning, err = engine.LoadTuning(*tuningFlag); err != nil {
+			return err
+		}
+	}
+	bots, err := pick(*botsFlag, sim.BotNames())
+	if err != nil {
+		return fmt.Errorf("-bots: %w", err)
+	}
+	arenas, err := pick(*arenasFlag, engine.Arenas())
+	if err != nil {
+		return fmt.Errorf("-arenas: %w", err)
+	}
+
+	var rows []row
+	for _, name := range arenas {
+		arena, err := engine.LoadArena(name)
+		if err == nil {
+			err = arena.ValidateFor(tuning)
+		}
+		if err != nil {
+			return err
+		}
+		c := sim.Config{
+			Arena:    arena,
+			Enemies:  min(*enemiesFlag, len(arena.Enemies)),
+			Tuning:   tuning,
+			EnemyHP:  *enemyHPFlag,
+			Damage:   *damageFlag,
+			MaxTicks: int(maxTimeFlag.Seconds() * engine.TickRate),
+		}
+		for _, bot := range bots {
+			fights := sim.Batch(c, sim.Bots[bot], *fightsFlag, *seedFlag)
+			rows = append(rows, row{bot, name, c.Enemies, sim.Summarize(fights)})
+		}
+	}
+	if *csvFlag {
+		return writeCSV(w, rows)
+	}
+	return writeTable(w, rows)
+}
+
+// pick splits a comma-separated list of names, checking each is one of
+// known. An empty list picks them all.
+func pick(list string, known []string) ([]string, error) {
+	if list == "" {
+		return known, nil
+	}
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(known, name) {
+			return nil, fmt.Errorf("unknown %q (have: %s)", name, strings.Join(known, ", "))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+var header = []string{"bot", "arena", "enemies", "fights", "win%", "loss%", "timeout%",
+	"ttk_mean_s", "ttk_median_s", "damage_taken", "accuracy%"}
+
+// fields formats r for the report, in header's order.
+func (r row) fields() []string {
+	pct := func(n int) string { return strconv.FormatFloat(100*float64(n)/float64(r.Fights), 'f', 1, 64) }
+	num := func(f float64, prec int) string { return strconv.FormatFloat(f, 'f', prec, 64) }
+	ttk, median := "-", "-"
+	if r.Wins > 0 {
+		ttk, median = num(r.TTK, 2), num(r.TTKMedian, 2)
+	}
+	return []string{r.bot, r.arena, strconv.Itoa(r.enemies), strconv.Itoa(r.Fights),
+		pct(r.Wins), pct(r.Losses), pct(r.Timeouts),
+		ttk, median, num(r.Damage, 2), num(100*r.Accuracy, 1)}
+}
+
+func writeTable(w io.Writer, rows []row) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+	for _, r := range rows {
+		fmt.Fprintln(tw, strings.Join(r.fields(), "\t")+"\t")
+	}
+	return tw.Flush()
+}
+
+func writeCSV(w io.Writer, rows []row) error {
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for _, r := range rows {
+		cw.Write(r.fields())
+	}
+	cw.Flush()
+	return cw.Error()
+}
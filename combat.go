@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"text-adventure-v2/combat/engine"
+	"text-adventure-v2/combat/scene"
+	"text-adventure-v2/game"
+	"text-adventure-v2/pixelbuf"
+	"text-adventure-v2/world"
+)
+
+const (
+	combatTick    = time.Second / time.Duration(engine.TickRate)
+	combatHUDRows = 3 // rows reserved below the frame for HUD text
+
+	// Without key release events a key counts as held for this long after
+	// its last press or auto-repeat; see cmd/combat-proto.
+	combatKeyTimeout = 100 * time.Millisecond
+)
+
+// Key names as returned by KeyPressMsg.String().
+const (
+	combatKeyLeft  = "a"
+	combatKeyRight = "d"
+	combatKeyJump  = "space"
+	combatKeyAtk   = "f"
+)
+
+type combatTickMsg time.Time
+
+func combatTickCmd() tea.Cmd {
+	return tea.Tick(combatTick, func(t time.Time) tea.Msg {
+		return combatTickMsg(t)
+	})
+}
+
+// combatModel runs one fight in a combat/engine.Engine. It ticks until the
+// fight is decided, then waits for a key and reports the result.
+type combatModel struct {
+	eng       *engine.Engine
+	enemyName string
+	buf       *pixelbuf.Buffer
+	scale     float64          // engine pixels -> buffer pixels
+	opts      pixelbuf.Options // how the frame is written to the terminal
+
+	hasKeyReleases bool
+	held           map[string]bool      // press/release mode: keys currently down
+	pressed        map[string]bool      // press/release mode: keys pressed since the last tick
+	lastSeen       map[string]time.Time // fallback mode: last press per key
+	prevHeld       map[string]bool      // fallback mode: keys held on the last tick
+
+	width, height int
+	frame         string
+}
+
+// newCombatModel sets up a fight with the player's current health against the
+// enemy's.
+func newCombatModel(start startCombatMsg, width, height int, hasKeyReleases bool, opts pixelbuf.Options) combatModel {
+	eng := engine.NewEngineIn(engine.MustLoadArena(start.arena), 1, tuningFor(start.enemy.Profile))
+	eng.Player.HP, eng.Player.MaxHP = start.playerHP, start.playerMaxHP
+	enemy := eng.Enemies[0]
+	enemy.HP, enemy.MaxHP, enemy.Damage = start.enemy.HP, start.enemy.HP, start.enemy.Damage
+
+	m := combatModel{
+		eng:            eng,
+		enemyName:      start.enemy.Name,
+		scale:          1,
+		opts:           opts,
+		hasKeyReleases: hasKeyReleases,
+		held:           make(map[string]bool),
+		pressed:        make(map[string]bool),
+		lastSeen:       make(map[string]time.Time),
+		prevHeld:       make(map[string]bool),
+		width:          width,
+		height:         height,
+	}
+	m.resize()
+	m.render()
+	return m
+}
+
+func (m combatModel) Init() tea.Cmd {
+	return combatTickCmd()
+}
+
+func (m combatModel) Update(msg tea.Msg) (combatModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyboardEnhancementsMsg:
+		m.hasKeyReleases = msg.SupportsEventTypes()
+
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.resize()
+		m.render()
+
+	case tea.KeyPressMsg:
+		key := msg.String()
+		if key == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.eng.Result != engine.ResultNone {
+			result := game.FightResult{Won: m.eng.Result == engine.ResultPlayerWin, PlayerHP: m.eng.Player.HP}
+			return m, func() tea.Msg { return combatResultMsg{result: result} }
+		}
+		if m.hasKeyReleases {
+			m.held[key] = true
+			if !msg.IsRepeat {
+				m.pressed[key] = true
+			}
+		} else {
+			m.lastSeen[key] = time.Now()
+		}
+
+	case tea.KeyReleaseMsg:
+		delete(m.held, msg.String())
+
+	case combatTickMsg:
+		m.eng.Tick(m.input(time.Now()))
+		m.render()
+		if m.eng.Result != engine.ResultNone {
+			return m, nil // the fight is over; wait for a key
+		}
+		return m, combatTickCmd()
+	}
+	return m, nil
+}
+
+// input builds this tick's input snapshot from the keys seen since the last.
+func (m *combatModel) input(now time.Time) engine.InputState {
+	var in engine.InputState
+	if m.hasKeyReleases {
+		in.Left = m.held[combatKeyLeft]
+		in.Right = m.held[combatKeyRight]
+		in.JumpHeld = m.held[combatKeyJump]
+		in.JumpPress = m.pressed[combatKeyJump]
+		in.Attack = m.pressed[combatKeyAtk]
+		clear(m.pressed)
+		return in
+	}
+
+	held := make(map[string]bool, len(m.lastSeen))
+	for key, seen := range m.lastSeen {
+		if now.Sub(seen) <= combatKeyTimeout {
+			held[key] = true
+		}
+	}
+	in.Left = held[combatKeyLeft]
+	in.Right = held[combatKeyRight]
+	in.JumpHeld = held[combatKeyJump]
+	in.JumpPress = held[combatKeyJump] && !m.prevHeld[combatKeyJump]
+	in.Attack = held[combatKeyAtk] && !m.prevHeld[combatKeyAtk]
+	m.prevHeld = held
+	return in
+}
+
+// resize fits the pixel buffer to the terminal, keeping the arena's aspect.
+func (m *combatModel) resize() {
+	if m.width < 20 || m.height < 5 {
+		return
+	}
+	arena := m.eng.Arena
+	cw, ch := m.opts.Encoding.CellSize()
+	scaleX := float64(m.width*cw) / arena.Width
+	scaleY := float64((m.height-combatHUDRows)*ch) / arena.Height
+	m.scale = min(scaleX, scaleY)
+
+	// Whole cells only: a part-filled one would show transparent black.
+	w := int(arena.Width*m.scale) / cw * cw
+	h := int(arena.Height*m.scale) / ch * ch
+	if m.buf == nil || m.buf.Width != w || m.buf.Height != h {
+		m.buf = pixelbuf.NewBuffer(w, h)
+	}
+}
+
+// render draws the fight and writes it out for View.
+func (m *combatModel) render() {
+	if m.buf == nil {
+		return
+	}
+	scene.Draw(m.buf, m.eng, m.scale)
+	m.frame = pixelbuf.RenderWith(m.buf, m.opts)
+}
+
+// tuningFor adjusts the default tuning to an enemy's fighting style. Aggressive
+// enemies spot the player from further off and strike sooner, cautious ones
+// hang back and take longer to commit, and sentries barely leave their post
+// and only react to a player within reach.
+func tuningFor(profile world.EnemyProfile) engine.Tuning {
+	t := engine.DefaultTuning()
+	switch profile {
+	case world.ProfileAggressive:
+		t.EnemyDetectRange *= 1.5
+		t.EnemyLoseRange *= 1.5
+		t.EnemyChaseSpeed *= 1.25
+		t.EnemyWindupTime *= 0.75
+		t.EnemyRecoverTime *= 0.75
+	case world.ProfileCautious:
+		t.EnemyDetectTime *= 2
+		t.EnemyChaseSpeed *= 0.75
+		t.EnemyLungeRange *= 0.75
+		t.EnemyWindupTime *= 1.5
+	case world.ProfileSentry:
+		t.EnemyPatrolRange *= 0.25
+		t.EnemyPatrolSpeed *= 0.5
+		t.EnemyDetectRange = t.EnemyLungeRange
+		t.EnemyLoseRange = t.EnemyLungeRange * 1.5
+	}
+	return t
+}
+
+// arenaFor picks the arena a room's fights take place in. A room always uses
+// the same one, chosen from its position on the map.
+func arenaFor(room *world.Room) string {
+	names := engine.Arenas()
+	i := (room.X*7 + room.Y*13) % len(names)
+	if i < 0 {
+		i += len(names)
+	}
+	return names[i]
+}
+
+func (m combatModel) View() string {
+	if m.buf == nil {
+		return fmt.Sprintf("\n  Terminal too small to fight (%dx%d). Please resize.\n", m.width, m.height)
+	}
+	var sb strings.Builder
+	sb.WriteString(m.frame)
+	sb.WriteByte('\n')
+	switch m.eng.Result {
+	case engine.ResultPlayerWin:
+		fmt.Fprintf(&sb, "  You defeated the %s!  Press any key to continue", m.enemyName)
+	case engine.ResultPlayerDead:
+		fmt.Fprintf(&sb, "  The %s overpowers you...  Press any key to continue", m.enemyName)
+	default:
+		fmt.Fprintf(&sb, "  %s  |  A/D move  SPACE jump  F attack", strings.ToUpper(m.enemyName))
+	}
+	sb.WriteByte('\n')
+	return sb.String()
+}
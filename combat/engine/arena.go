@@ -0,0 +1,256 @@
+package engine
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultArena is the arena NewEngine fights in.
+const DefaultArena = "hall"
+
+//go:embed arenas/*.json
+var arenaFiles embed.FS
+
+// Arena is the stage a fight takes place on. Arenas live as JSON files in the
+// arenas directory and are embedded in the binary; the file name (without
+// .json) selects the arena. Rects are written {"x", "y", "w", "h"}.
+type Arena struct {
+	Name   string  `json:"name"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+
+	// Walls are solid but not meant to be stood on, so they are left out of
+	// the reachability check. Platforms include the floor.
+	Walls     []Rect   `json:"walls"`
+	Platforms []Rect   `json:"platforms"`
+	Hazards   []Hazard `json:"hazards"`
+
+	// Spawns are the points under the middle of an entity's feet.
+	Player  Point   `json:"player"`
+	Enemies []Point `json:"enemies"` // the first is used when there is one enemy
+}
+
+// Point is a position in the arena.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Hazard is an area, such as spikes, that hurts the player on contact.
+type Hazard struct {
+	Rect
+	Damage int `json:"damage"`
+}
+
+// Arenas returns the names of the embedded arenas in alphabetical order.
+func Arenas() []string {
+	entries, err := arenaFiles.ReadDir("arenas")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadArena reads and checks an embedded arena by name, e.g. "pit".
+func LoadArena(name string) (Arena, error) {
+	name = strings.ToLower(name)
+	data, err := arenaFiles.ReadFile(path.Join("arenas", name+".json"))
+	if err != nil {
+		return Arena{}, fmt.Errorf("unknown arena %q (available: %s)", name, strings.Join(Arenas(), ", "))
+	}
+	return ParseArena(data)
+}
+
+// ParseArena decodes an arena from JSON and validates it.
+func ParseArena(data []byte) (Arena, error) {
+	var a Arena
+	if err := json.Unmarshal(data, &a); err != nil {
+		return Arena{}, fmt.Errorf("arena is not valid JSON: %w", err)
+	}
+	if err := a.Validate(); err != nil {
+		return Arena{}, err
+	}
+	return a, nil
+}
+
+// MustLoadArena loads an embedded arena that is known to be good, such as one
+// named by Arenas. It panics if the arena is missing or invalid.
+func MustLoadArena(name string) Arena {
+	a, err := LoadArena(name)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Validate checks the arena against the default tuning; see ValidateFor.
+func (a Arena) Validate() error {
+	return a.ValidateFor(DefaultTuning())
+}
+
+// ValidateFor checks that the arena has a size, that everything lies inside
+// it, that every spawn stands on solid ground with room to stand, and that
+// the player can reach every platform from their spawn with tuning t.
+func (a Arena) ValidateFor(t Tuning) error {
+	if a.Name == "" {
+		return errors.New("arena has no name")
+	}
+	if a.Width <= 0 || a.Height <= 0 {
+		return fmt.Errorf("arena %q: needs a width and a height", a.Name)
+	}
+	if len(a.Platforms) == 0 || len(a.Enemies) == 0 {
+		return fmt.Errorf("arena %q: needs platforms and at least one enemy spawn", a.Name)
+	}
+
+	var errs []error
+	bounds := Rect{0, 0, a.Width, a.Height}
+	check := func(what string, r Rect) {
+		if r.W <= 0 || r.H <= 0 || r.X < 0 || r.Y < 0 || r.X+r.W > bounds.W || r.Y+r.H > bounds.H {
+			errs = append(errs, fmt.Errorf("arena %q: %s %v is empty or outside the arena", a.Name, what, r))
+		}
+	}
+	for _, r := range a.Walls {
+		check("wall", r)
+	}
+	for _, r := range a.Platforms {
+		check("platform", r)
+	}
+	for _, h := range a.Hazards {
+		check("hazard", h.Rect)
+		if h.Damage <= 0 {
+			errs = append(errs, fmt.Errorf("arena %q: hazard %v does no damage", a.Name, h.Rect))
+		}
+	}
+
+	start := a.standingOn(a.Player, PlayerWidth, PlayerHeight)
+	if start < 0 {
+		errs = append(errs, fmt.Errorf("arena %q: player spawn %v is not on solid ground", a.Name, a.Player))
+	}
+	for _, p := range a.Enemies {
+		if a.standingOn(p, EnemyWidth, EnemyHeight) < 0 {
+			errs = append(errs, fmt.Errorf("arena %q: enemy spawn %v is not on solid ground", a.Name, p))
+		}
+	}
+	if start >= 0 {
+		reached := a.reachable(start, &t)
+		for i, r := range a.Platforms {
+			if !reached[i] {
+				errs = append(errs, fmt.Errorf("arena %q: platform %v cannot be reached from the player spawn", a.Name, r))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// standingOn returns the index of the platform an entity of size w×h would
+// stand on at spawn point p, or -1 if it would not stand on one, or would be
+// stuck in a wall or platform.
+func (a Arena) standingOn(p Point, w, h float64) int {
+	body := Rect{p.X - w/2, p.Y - h, w, h}
+	for _, r := range a.solids() {
+		if body.Overlaps(r) {
+			return -1
+		}
+	}
+	for i, r := range a.Platforms {
+		if p.Y == r.Y && p.X >= r.X && p.X <= r.X+r.W {
+			return i
+		}
+	}
+	return -1
+}
+
+// reachable walks the platforms the player can get to from platform start,
+// jumping from one top to another.
+func (a Arena) reachable(start int, t *Tuning) []bool {
+	seen := make([]bool, len(a.Platforms))
+	seen[start] = true
+	queue := []int{start}
+	for len(queue) > 0 {
+		from := a.Platforms[queue[0]]
+		queue = queue[1:]
+		for i, to := range a.Platforms {
+			if !seen[i] && canJump(from, to, t) {
+				seen[i] = true
+				queue = append(queue, i)
+			}
+		}
+	}
+	return seen
+}
+
+// PlatformUnder returns the index of the platform an entity with bounds r
+// stands on, or -1 if it isn't standing on one.
+func (a Arena) PlatformUnder(r Rect) int {
+	for i, p := range a.Platforms {
+		if math.Abs(r.Y+r.H-p.Y) < 0.5 && r.X < p.X+p.W && r.X+r.W > p.X {
+			return i
+		}
+	}
+	return -1
+}
+
+// Route returns the platforms to jump to, in order, to get from platform
+// from to platform to, ending with to. It returns nil if to can't be reached,
+// and an empty route if from is to.
+func (a Arena) Route(from, to int, t *Tuning) []int {
+	prev := make([]int, len(a.Platforms))
+	for i := range prev {
+		prev[i] = -1
+	}
+	prev[from] = from
+	queue := []int{from}
+	for len(queue) > 0 && prev[to] == -1 {
+		i := queue[0]
+		queue = queue[1:]
+		for j, p := range a.Platforms {
+			if prev[j] == -1 && canJump(a.Platforms[i], p, t) {
+				prev[j] = i
+				queue = append(queue, j)
+			}
+		}
+	}
+	if prev[to] == -1 {
+		return nil
+	}
+	route := []int{}
+	for i := to; i != from; i = prev[i] {
+		route = append([]int{i}, route...)
+	}
+	return route
+}
+
+// canJump reports whether a full-height jump at RunSpeed carries the player
+// from the top of one platform to the top of another. The jump is stepped
+// tick by tick as Tick moves the player, since that falls a few pixels short
+// of the smooth arc. Ceilings are ignored.
+func canJump(from, to Rect, t *Tuning) bool {
+	rise := from.Y - to.Y // positive when to is higher
+	gap := max(0, to.X-(from.X+from.W), from.X-(to.X+to.W))
+	height, velY, run := 0.0, -t.JumpForce, 0.0
+	for velY < 0 || height >= rise {
+		applyGravity(&velY, t)
+		height -= velY * DT
+		run += t.RunSpeed * DT
+		if height >= rise && run >= gap {
+			return true
+		}
+	}
+	return false
+}
+
+// solids returns every rect that blocks movement.
+func (a Arena) solids() []Rect {
+	return append(append([]Rect(nil), a.Platforms...), a.Walls...)
+}
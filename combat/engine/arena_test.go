@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestArenas_AllLoadAndSettle(t *testing.T) {
+	names := Arenas()
+	if len(names) < 3 {
+		t.Fatalf("Expected at least 3 embedded arenas, got %v", names)
+	}
+	for _, name := range names {
+		arena, err := LoadArena(name)
+		if err != nil {
+			t.Fatalf("LoadArena(%q) failed: %v", name, err)
+		}
+		e := NewEngineIn(arena, len(arena.Enemies), DefaultTuning())
+		if len(e.Enemies) != len(arena.Enemies) {
+			t.Errorf("%s: %d enemies, want one per spawn (%d)", name, len(e.Enemies), len(arena.Enemies))
+		}
+		e.Tick(emptyInput())
+		if !e.Player.Grounded {
+			t.Errorf("%s: player is not standing after one tick: %+v", name, e.Player.Pos)
+		}
+		for i, enemy := range e.Enemies {
+			if !enemy.Grounded {
+				t.Errorf("%s: enemy %d is not standing after one tick: %+v", name, i, enemy.Pos)
+			}
+		}
+	}
+}
+
+func TestLoadArena_Unknown(t *testing.T) {
+	_, err := LoadArena("moon")
+	if err == nil || !strings.Contains(err.Error(), "hall") {
+		t.Errorf("Expected an error listing the available arenas, got: %v", err)
+	}
+}
+
+func TestNewEngineIn_CapsEnemiesAtSpawns(t *testing.T) {
+	arena := MustLoadArena("hall")
+	e := NewEngineIn(arena, 10, DefaultTuning())
+	if len(e.Enemies) != len(arena.Enemies) {
+		t.Errorf("%d enemies, want %d", len(e.Enemies), len(arena.Enemies))
+	}
+	e.Reset()
+	if len(e.Enemies) != len(arena.Enemies) {
+		t.Errorf("%d enemies after reset, want %d", len(e.Enemies), len(arena.Enemies))
+	}
+}
+
+func TestParseArena_Rejects(t *testing.T) {
+	floor := `{"x":0,"y":112,"w":160,"h":8}`
+	base := `"name":"Test","width":160,"height":120,"player":{"x":20,"y":112},"enemies":[{"x":120,"y":112}]`
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"not json", `{`, "JSON"},
+		{"no name", `{"width":160,"height":120}`, "no name"},
+		{"no size", `{"name":"Test","platforms":[` + floor + `],"enemies":[{"x":1,"y":112}]}`, "width"},
+		{"no enemy spawns", `{"name":"Test","width":160,"height":120,"platforms":[` + floor + `]}`, "enemy spawn"},
+		{"platform outside", `{` + base + `,"platforms":[` + floor + `,{"x":150,"y":50,"w":20,"h":6}]}`, "outside"},
+		{"hazard without damage", `{` + base + `,"platforms":[` + floor + `],"hazards":[{"x":50,"y":108,"w":10,"h":4}]}`, "no damage"},
+		{"player in midair", `{"name":"Test","width":160,"height":120,"platforms":[` + floor + `],
+			"player":{"x":20,"y":100},"enemies":[{"x":120,"y":112}]}`, "player spawn"},
+		{"enemy off the edge", `{` + base + `,"platforms":[{"x":0,"y":112,"w":100,"h":8}],
+			"enemies":[{"x":120,"y":112}]}`, "enemy spawn"},
+		{"enemy in a wall", `{` + base + `,"platforms":[` + floor + `],"walls":[{"x":115,"y":0,"w":10,"h":112}]}`, "enemy spawn"},
+		{"platform too high", `{` + base + `,"platforms":[` + floor + `,{"x":60,"y":40,"w":20,"h":6}]}`, "cannot be reached"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseArena([]byte(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error about %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCanJump(t *testing.T) {
+	from := Rect{0, 100, 40, 6} // a jump tops out 51⅓ above it
+	tests := []struct {
+		name string
+		to   Rect
+		want bool
+	}{
+		{"overlapping, a little higher", Rect{20, 80, 40, 6}, true},
+		{"just within the rise", Rect{20, 49, 40, 6}, true},
+		{"too high", Rect{20, 48, 40, 6}, false},
+		{"just within the rise, with a gap", Rect{60, 49, 40, 6}, true},
+		{"level, short gap", Rect{100, 100, 40, 6}, true},
+		{"level, gap too wide", Rect{200, 100, 40, 6}, false},
+		{"far below, wide gap", Rect{200, 400, 40, 6}, true},
+		{"to the left", Rect{-80, 90, 40, 6}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canJump(from, tt.to, &tuning); got != tt.want {
+				t.Errorf("canJump(%v, %v, &tuning) = %v, want %v", from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArena_PlatformUnder(t *testing.T) {
+	arena := MustLoadArena("pit")
+	tests := []struct {
+		name string
+		r    Rect
+		want int
+	}{
+		{"on the left floor", Rect{20, 92, 12, 20}, 0},
+		{"in the pit", Rect{70, 96, 12, 20}, 2},
+		{"on a ledge", Rect{30, 60, 12, 20}, 3},
+		{"in the air", Rect{30, 50, 12, 20}, -1},
+		{"beside a ledge", Rect{60, 60, 12, 20}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := arena.PlatformUnder(tt.r); got != tt.want {
+				t.Errorf("PlatformUnder(%v) = %d, want %d", tt.r, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArena_Route(t *testing.T) {
+	arena := MustLoadArena("pit") // 0 floor, 3 and 4 ledges, 5 the top
+	tests := []struct {
+		name     string
+		from, to int
+		want     []int
+	}{
+		{"already there", 3, 3, []int{}},
+		{"one jump", 0, 3, []int{3}},
+		{"by a ledge", 0, 5, []int{3, 5}},
+		{"down", 5, 0, []int{0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := arena.Route(tt.from, tt.to, &tuning)
+			if !slices.Equal(got, tt.want) || got == nil {
+				t.Errorf("Route(%d, %d) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+
+	arena.Platforms = append(arena.Platforms, Rect{X: 70, Y: 4, W: 20, H: 4})
+	if got := arena.Route(0, len(arena.Platforms)-1, &tuning); got != nil {
+		t.Errorf("Route to a platform out of reach = %v, want nil", got)
+	}
+}
+
+func TestEngine_HazardHurtsAndBounces(t *testing.T) {
+	e := NewEngineIn(MustLoadArena("pit"), 1, DefaultTuning())
+	e.Player.Pos.X, e.Player.Pos.Y = 74, 116-PlayerHeight // on the pit floor, in the spikes
+
+	e.Tick(emptyInput())
+
+	if e.Player.HP != PlayerHP-1 || e.Player.State != StateHurt {
+		t.Errorf("HP = %d, State = %v; want %d, StateHurt", e.Player.HP, e.Player.State, PlayerHP-1)
+	}
+	if e.Player.VelY >= 0 {
+		t.Errorf("VelY = %v, want the player bounced upward", e.Player.VelY)
+	}
+	for range int(InvincTime/DT) - 1 {
+		e.Tick(emptyInput())
+	}
+	if e.Player.HP != PlayerHP-1 {
+		t.Errorf("HP = %d, want no more damage while invincible", e.Player.HP)
+	}
+}
@@ -2,64 +2,78 @@ package engine
 
 // AttackHitbox returns the active attack hitbox for the player, or a zero
 // Rect if the player is not attacking. Exported for testbed rendering.
-func AttackHitbox(p *Player) Rect {
+func AttackHitbox(p *Player, t *Tuning) Rect {
 	if p.State != StateAttack || p.AttackTimer <= 0 {
 		return Rect{}
 	}
 	cx, cy := p.Pos.Center()
 	hb := Rect{
-		W: AttackWidth,
-		H: AttackHeight,
-		Y: cy - AttackHeight/2,
+		W: t.AttackWidth,
+		H: t.AttackHeight,
+		Y: cy - t.AttackHeight/2,
 	}
 	if p.Facing == DirRight {
-		hb.X = cx + AttackOffsetX - AttackWidth/2
+		hb.X = cx + t.AttackOffsetX - t.AttackWidth/2
 	} else {
-		hb.X = cx - AttackOffsetX - AttackWidth/2
+		hb.X = cx - t.AttackOffsetX - t.AttackWidth/2
 	}
 	return hb
 }
 
-// processAttack checks the attack hitbox against the enemy and applies
-// damage + knockback on hit.
-func processAttack(p *Player, e *Enemy) {
-	if !e.Alive {
-		return
-	}
-	hb := AttackHitbox(p)
+// processAttack checks the attack hitbox against every enemy and applies
+// damage + knockback to each one it hits. One swing can hit several enemies,
+// but each only once.
+func processAttack(p *Player, enemies []*Enemy, t *Tuning) {
+	hb := AttackHitbox(p, t)
 	if hb.W == 0 {
 		return
 	}
 	if p.AttackHit {
 		return
 	}
+	hit := false
+	for _, e := range enemies {
+		if hitEnemy(p, hb, e, t) {
+			hit = true
+		}
+	}
+	if hit {
+		p.AttackHit = true
+	}
+}
+
+// hitEnemy applies an attack with hitbox hb to one enemy, reporting whether
+// it landed.
+func hitEnemy(p *Player, hb Rect, e *Enemy, t *Tuning) bool {
+	if !e.Alive {
+		return false
+	}
 	if e.InvincTimer > 0 {
-		return
+		return false
 	}
 	if !hb.Overlaps(e.Pos) {
-		return
+		return false
 	}
 
 	// Hit confirmed.
-	e.HP -= AttackDamage
+	e.HP -= p.Damage
 	if e.HP < 0 {
 		e.HP = 0
 	}
-	e.HurtTimer = HurtDuration
-	e.InvincTimer = InvincTime
+	e.HurtTimer = t.HurtDuration
+	e.InvincTimer = t.InvincTime
 
 	// Knockback: push enemy away from player.
 	pcx, _ := p.Pos.Center()
 	ecx, _ := e.Pos.Center()
 	if ecx >= pcx {
-		e.VelX = KnockbackVel
+		e.VelX = t.KnockbackVel
 	} else {
-		e.VelX = -KnockbackVel
+		e.VelX = -t.KnockbackVel
 	}
 
-	p.AttackHit = true
-
 	if e.HP <= 0 {
 		e.Alive = false
 	}
+	return true
 }
@@ -10,6 +10,7 @@ func attackingPlayer(facing Dir) Player {
 		AttackTimer: AttackDuration,
 		HP:          PlayerHP,
 		MaxHP:       PlayerHP,
+		Damage:      AttackDamage,
 	}
 }
 
@@ -25,7 +26,7 @@ func aliveEnemy(x float64) Enemy {
 
 func TestAttackHitbox_FacingRight(t *testing.T) {
 	p := attackingPlayer(DirRight)
-	hb := AttackHitbox(&p)
+	hb := AttackHitbox(&p, &tuning)
 	if hb.W == 0 {
 		t.Fatal("expected non-zero hitbox")
 	}
@@ -38,7 +39,7 @@ func TestAttackHitbox_FacingRight(t *testing.T) {
 
 func TestAttackHitbox_FacingLeft(t *testing.T) {
 	p := attackingPlayer(DirLeft)
-	hb := AttackHitbox(&p)
+	hb := AttackHitbox(&p, &tuning)
 	if hb.W == 0 {
 		t.Fatal("expected non-zero hitbox")
 	}
@@ -52,7 +53,7 @@ func TestAttackHitbox_FacingLeft(t *testing.T) {
 func TestAttackHitbox_NotAttacking(t *testing.T) {
 	p := attackingPlayer(DirRight)
 	p.State = StateIdle
-	hb := AttackHitbox(&p)
+	hb := AttackHitbox(&p, &tuning)
 	if hb.W != 0 || hb.H != 0 {
 		t.Errorf("expected zero hitbox when not attacking, got %+v", hb)
 	}
@@ -61,7 +62,7 @@ func TestAttackHitbox_NotAttacking(t *testing.T) {
 func TestAttackHitbox_TimerExpired(t *testing.T) {
 	p := attackingPlayer(DirRight)
 	p.AttackTimer = 0
-	hb := AttackHitbox(&p)
+	hb := AttackHitbox(&p, &tuning)
 	if hb.W != 0 {
 		t.Error("expected zero hitbox when timer expired")
 	}
@@ -69,10 +70,10 @@ func TestAttackHitbox_TimerExpired(t *testing.T) {
 
 func TestProcessAttack_HitsEnemy(t *testing.T) {
 	p := attackingPlayer(DirRight)
-	hb := AttackHitbox(&p)
+	hb := AttackHitbox(&p, &tuning)
 	// Place enemy overlapping with hitbox.
 	e := aliveEnemy(hb.X)
-	processAttack(&p, &e)
+	processAttack(&p, []*Enemy{&e}, &tuning)
 	if e.HP != EnemyHP-AttackDamage {
 		t.Errorf("enemy HP = %d, want %d", e.HP, EnemyHP-AttackDamage)
 	}
@@ -85,10 +86,10 @@ func TestProcessAttack_HitsEnemy(t *testing.T) {
 
 func TestProcessAttack_EnemyInvincible(t *testing.T) {
 	p := attackingPlayer(DirRight)
-	hb := AttackHitbox(&p)
+	hb := AttackHitbox(&p, &tuning)
 	e := aliveEnemy(hb.X)
 	e.InvincTimer = 0.5
-	processAttack(&p, &e)
+	processAttack(&p, []*Enemy{&e}, &tuning)
 	if e.HP != EnemyHP {
 		t.Errorf("enemy HP = %d, should be unchanged at %d", e.HP, EnemyHP)
 	}
@@ -96,13 +97,13 @@ func TestProcessAttack_EnemyInvincible(t *testing.T) {
 
 func TestProcessAttack_MultiHitPrevention(t *testing.T) {
 	p := attackingPlayer(DirRight)
-	hb := AttackHitbox(&p)
+	hb := AttackHitbox(&p, &tuning)
 	e := aliveEnemy(hb.X)
 
-	processAttack(&p, &e)
+	processAttack(&p, []*Enemy{&e}, &tuning)
 	hpAfterFirst := e.HP
 	e.InvincTimer = 0 // clear invincibility to isolate AttackHit check
-	processAttack(&p, &e)
+	processAttack(&p, []*Enemy{&e}, &tuning)
 	if e.HP != hpAfterFirst {
 		t.Errorf("enemy HP = %d, expected no change due to AttackHit = %d", e.HP, hpAfterFirst)
 	}
@@ -110,10 +111,10 @@ func TestProcessAttack_MultiHitPrevention(t *testing.T) {
 
 func TestProcessAttack_EnemyDies(t *testing.T) {
 	p := attackingPlayer(DirRight)
-	hb := AttackHitbox(&p)
+	hb := AttackHitbox(&p, &tuning)
 	e := aliveEnemy(hb.X)
 	e.HP = 1
-	processAttack(&p, &e)
+	processAttack(&p, []*Enemy{&e}, &tuning)
 	if e.HP != 0 {
 		t.Errorf("enemy HP = %d, want 0", e.HP)
 	}
@@ -124,9 +125,9 @@ func TestProcessAttack_EnemyDies(t *testing.T) {
 
 func TestProcessAttack_KnockbackDirection_Right(t *testing.T) {
 	p := attackingPlayer(DirRight)
-	hb := AttackHitbox(&p)
+	hb := AttackHitbox(&p, &tuning)
 	e := aliveEnemy(hb.X) // enemy to the right of player
-	processAttack(&p, &e)
+	processAttack(&p, []*Enemy{&e}, &tuning)
 	if e.VelX <= 0 {
 		t.Errorf("enemy VelX = %v, expected positive (pushed right)", e.VelX)
 	}
@@ -134,10 +135,10 @@ func TestProcessAttack_KnockbackDirection_Right(t *testing.T) {
 
 func TestProcessAttack_KnockbackDirection_Left(t *testing.T) {
 	p := attackingPlayer(DirLeft)
-	hb := AttackHitbox(&p)
+	hb := AttackHitbox(&p, &tuning)
 	// Place enemy to the left of player.
 	e := aliveEnemy(hb.X)
-	processAttack(&p, &e)
+	processAttack(&p, []*Enemy{&e}, &tuning)
 	if e.VelX >= 0 {
 		t.Errorf("enemy VelX = %v, expected negative (pushed left)", e.VelX)
 	}
@@ -166,12 +167,12 @@ func TestProcessAttack_KnockbackWall(t *testing.T) {
 	p.Facing = DirRight
 
 	// Verify hitbox actually overlaps.
-	hb := AttackHitbox(&p)
+	hb := AttackHitbox(&p, &tuning)
 	if !hb.Overlaps(e.Pos) {
 		t.Skipf("hitbox %+v doesn't overlap enemy %+v — adjust positions", hb, e.Pos)
 	}
 
-	processAttack(&p, &e)
+	processAttack(&p, []*Enemy{&e}, &tuning)
 	if e.VelX <= 0 {
 		t.Fatalf("expected positive knockback, got VelX=%v", e.VelX)
 	}
@@ -189,11 +190,11 @@ func TestProcessAttack_KnockbackWall(t *testing.T) {
 
 func TestProcessAttack_DeadEnemy_NoHit(t *testing.T) {
 	p := attackingPlayer(DirRight)
-	hb := AttackHitbox(&p)
+	hb := AttackHitbox(&p, &tuning)
 	e := aliveEnemy(hb.X)
 	e.Alive = false
 	e.HP = 0
-	processAttack(&p, &e)
+	processAttack(&p, []*Enemy{&e}, &tuning)
 	if p.AttackHit {
 		t.Error("should not hit dead enemy")
 	}
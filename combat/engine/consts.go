@@ -37,6 +37,9 @@ const (
 
 // Physics constants (pixels/sec or pixels/sec^2).
 // Y increases downward. Positive VelY = falling.
+//
+// These and the timing, attack, hurt and enemy AI values below are the
+// defaults for Tuning; the engine reads the live values from Engine.Tuning.
 const (
 	Gravity      = 800.0
 	MaxFallSpeed = 400.0
@@ -74,13 +77,37 @@ const (
 
 // Hurt / invincibility.
 const (
-	HurtDuration = 0.5
-	InvincTime   = 1.0
-	KnockbackVel = 150.0
+	HurtDuration  = 0.5
+	InvincTime    = 1.0
+	KnockbackVel  = 150.0
+	KnockbackTime = 0.15 // the part of HurtDuration spent sliding back
 )
 
-// Arena dimensions (pixels).
+// EnemyState represents the enemy AI's current state.
+type EnemyState int
+
+const (
+	EnemyPatrol  EnemyState = iota // walk back and forth around HomeX
+	EnemyDetect                    // noticed the player; pause, then close in
+	EnemyWindup                    // stand still, telegraphing the lunge
+	EnemyLunge                     // dash forward
+	EnemyRecover                   // stand still after a lunge or a hit
+)
+
+// Enemy AI (pixels, pixels/sec, seconds). Distances are between centers.
 const (
-	ArenaWidth  = 160
-	ArenaHeight = 120
+	EnemyDamage       = 1 // contact damage
+	EnemyHitboxInset  = 2.0
+	EnemyPatrolSpeed  = 30.0
+	EnemyPatrolRange  = 24.0 // either side of HomeX
+	EnemyChaseSpeed   = 60.0
+	EnemyDetectRange  = 64.0
+	EnemyLoseRange    = 96.0
+	EnemyDetectHeight = 24.0 // the player must be roughly level to be seen
+	EnemyDetectTime   = 0.3
+	EnemyLungeRange   = 36.0
+	EnemyWindupTime   = 0.4
+	EnemyLungeSpeed   = 240.0
+	EnemyLungeTime    = 0.2
+	EnemyRecoverTime  = 0.6
 )
@@ -0,0 +1,195 @@
+package engine
+
+import "math"
+
+// updateEnemy runs the enemy state machine. Called at step 1 of Tick, after
+// updatePlayer, so it sees where the player stood at the end of the last
+// frame.
+//
+//	Patrol  --player in range-->  Detect  --close enough-->  Windup
+//	  ^                             |  ^                        |
+//	  +--------player lost----------+  |                        v
+//	  +--------player lost-------- Recover <--time up------  Lunge
+//
+// Being hit interrupts any state: the enemy slides back, then recovers.
+func updateEnemy(e *Enemy, p *Player, platforms []Platform, t *Tuning) {
+	if e.HurtTimer > 0 {
+		if e.HurtTimer < t.HurtDuration-t.KnockbackTime {
+			e.VelX = 0
+		}
+		e.State = EnemyRecover
+		e.StateTimer = t.EnemyRecoverTime
+		return
+	}
+	if !e.Grounded && e.State != EnemyLunge {
+		e.VelX = 0 // no steering in the air
+		return
+	}
+
+	e.StateTimer = max(0, e.StateTimer-DT)
+	dx, seen := sightline(e, p, t)
+
+	switch e.State {
+	case EnemyPatrol:
+		if seen && math.Abs(dx) <= t.EnemyDetectRange {
+			e.State = EnemyDetect
+			e.StateTimer = t.EnemyDetectTime
+			e.Facing = dirOf(dx)
+			e.VelX = 0
+			return
+		}
+		cx, _ := e.Pos.Center()
+		switch {
+		case e.Facing == DirRight && cx > e.HomeX+t.EnemyPatrolRange,
+			e.Facing == DirLeft && cx < e.HomeX-t.EnemyPatrolRange,
+			!canWalk(e.Pos, e.Facing, platforms):
+			e.Facing = -e.Facing
+		}
+		e.VelX = float64(e.Facing) * t.EnemyPatrolSpeed
+
+	case EnemyDetect:
+		if !seen || math.Abs(dx) > t.EnemyLoseRange {
+			e.State = EnemyPatrol
+			return
+		}
+		e.Facing = dirOf(dx)
+		e.VelX = 0
+		switch {
+		case e.StateTimer > 0:
+			// Still reacting.
+		case math.Abs(dx) <= t.EnemyLungeRange:
+			e.State = EnemyWindup
+			e.StateTimer = t.EnemyWindupTime
+		case canWalk(e.Pos, e.Facing, platforms):
+			e.VelX = float64(e.Facing) * t.EnemyChaseSpeed
+		}
+
+	case EnemyWindup:
+		e.VelX = 0
+		if e.StateTimer <= 0 {
+			e.State = EnemyLunge
+			e.StateTimer = t.EnemyLungeTime
+			e.VelX = float64(e.Facing) * t.EnemyLungeSpeed
+		}
+
+	case EnemyLunge:
+		e.VelX = float64(e.Facing) * t.EnemyLungeSpeed
+		if e.StateTimer <= 0 {
+			e.State = EnemyRecover
+			e.StateTimer = t.EnemyRecoverTime
+			e.VelX = 0
+		}
+
+	case EnemyRecover:
+		e.VelX = 0
+		if e.StateTimer > 0 {
+			return
+		}
+		if seen && math.Abs(dx) <= t.EnemyLoseRange {
+			e.State = EnemyDetect // already alert: no pause
+		} else {
+			e.State = EnemyPatrol
+		}
+	}
+}
+
+// sightline returns the horizontal distance from the enemy's centre to the
+// player's, and whether the player is level enough to be seen.
+func sightline(e *Enemy, p *Player, t *Tuning) (float64, bool) {
+	ecx, ecy := e.Pos.Center()
+	pcx, pcy := p.Pos.Center()
+	return pcx - ecx, math.Abs(pcy-ecy) <= t.EnemyDetectHeight
+}
+
+func dirOf(dx float64) Dir {
+	if dx < 0 {
+		return DirLeft
+	}
+	return DirRight
+}
+
+// canWalk reports whether a grounded entity can take a step in dir: there is
+// no wall in the way and there is ground ahead, so it won't walk off a ledge.
+func canWalk(pos Rect, dir Dir, platforms []Platform) bool {
+	x := pos.X + pos.W
+	if dir == DirLeft {
+		x = pos.X - 1
+	}
+	wall := Rect{x, pos.Y, 1, pos.H - 1}
+	ground := Rect{x, pos.Y + pos.H, 1, 1}
+	hasGround := false
+	for _, p := range platforms {
+		if wall.Overlaps(p.Rect) {
+			return false
+		}
+		if ground.Overlaps(p.Rect) {
+			hasGround = true
+		}
+	}
+	return hasGround
+}
+
+// EnemyHitbox returns the part of the enemy that hurts on contact, or a zero
+// Rect if it is dead. Exported for testbed rendering.
+func EnemyHitbox(e *Enemy) Rect {
+	if !e.Alive {
+		return Rect{}
+	}
+	return Rect{
+		X: e.Pos.X + EnemyHitboxInset,
+		Y: e.Pos.Y + EnemyHitboxInset,
+		W: e.Pos.W - 2*EnemyHitboxInset,
+		H: e.Pos.H - 2*EnemyHitboxInset,
+	}
+}
+
+// processContact checks the enemy's hitbox against the player and applies
+// contact damage + knockback on hit. A staggered enemy does no harm.
+func processContact(e *Enemy, p *Player, t *Tuning) {
+	if e.HurtTimer > 0 {
+		return
+	}
+	if p.InvincTimer > 0 || p.HP <= 0 {
+		return
+	}
+	if !EnemyHitbox(e).Overlaps(p.Pos) {
+		return
+	}
+
+	ecx, _ := e.Pos.Center()
+	hurtPlayer(p, e.Damage, ecx, t)
+}
+
+// processHazards hurts the player if they touch a hazard, and bounces them
+// up out of it.
+func processHazards(p *Player, hazards []Hazard, t *Tuning) {
+	if p.InvincTimer > 0 || p.HP <= 0 {
+		return
+	}
+	for _, h := range hazards {
+		if h.Overlaps(p.Pos) {
+			hcx, _ := h.Center()
+			hurtPlayer(p, h.Damage, hcx, t)
+			p.VelY = -t.KnockbackVel
+			return
+		}
+	}
+}
+
+// hurtPlayer applies damage from something centred at fromX: the player is
+// knocked away from it and any attack in progress is cancelled.
+func hurtPlayer(p *Player, damage int, fromX float64, t *Tuning) {
+	p.HP = max(0, p.HP-damage)
+	p.State = StateHurt
+	p.HurtTimer = t.HurtDuration
+	p.InvincTimer = t.InvincTime
+	p.AttackTimer = 0
+	p.AttackHit = false
+
+	pcx, _ := p.Pos.Center()
+	if pcx >= fromX {
+		p.VelX = t.KnockbackVel
+	} else {
+		p.VelX = -t.KnockbackVel
+	}
+}
@@ -0,0 +1,229 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+// duel returns a settled engine with the enemy patrolling from its home on
+// the floor, and the player standing on the floor at playerX.
+func duel(playerX float64) *Engine {
+	e := testEngine()
+	enemy := e.Enemies[0]
+	enemy.Pos.X, enemy.Pos.Y, enemy.HomeX = 120, 112-EnemyHeight, 120+EnemyWidth/2
+	enemy.VelX, enemy.Facing = 0, DirLeft
+	enemy.State, enemy.StateTimer = EnemyPatrol, 0
+	e.Player.Pos.X, e.Player.Pos.Y = playerX, 112-PlayerHeight
+	return e
+}
+
+// step holds one input for a number of ticks.
+type step struct {
+	in    InputState
+	ticks int
+}
+
+// play runs a script and returns the enemy states seen, starting with the
+// state before the first tick, with repeats collapsed.
+func play(e *Engine, script []step) []EnemyState {
+	states := []EnemyState{e.Enemies[0].State}
+	for _, s := range script {
+		for range s.ticks {
+			e.Tick(s.in)
+			if e.Enemies[0].State != states[len(states)-1] {
+				states = append(states, e.Enemies[0].State)
+			}
+		}
+	}
+	return states
+}
+
+var (
+	idle   = InputState{}
+	attack = InputState{Attack: true}
+	left   = InputState{Left: true}
+	right  = InputState{Right: true}
+)
+
+func TestEnemyAI_Scripts(t *testing.T) {
+	tests := []struct {
+		name         string
+		playerX      float64
+		playerHP     int
+		script       []step
+		wantStates   []EnemyState
+		wantPlayerHP int
+		wantEnemyHP  int
+		wantResult   Result
+	}{
+		{
+			name:         "far player is ignored",
+			playerX:      20,
+			script:       []step{{idle, 90}},
+			wantStates:   []EnemyState{EnemyPatrol},
+			wantPlayerHP: PlayerHP,
+			wantEnemyHP:  EnemyHP,
+		},
+		{
+			name:         "idle player is lunged at",
+			playerX:      71,
+			script:       []step{{idle, 45}},
+			wantStates:   []EnemyState{EnemyPatrol, EnemyDetect, EnemyWindup, EnemyLunge, EnemyRecover},
+			wantPlayerHP: PlayerHP - EnemyDamage,
+			wantEnemyHP:  EnemyHP,
+		},
+		{
+			name:         "backing out of reach during the windup",
+			playerX:      71,
+			script:       []step{{idle, 24}, {left, 6}, {idle, 17}},
+			wantStates:   []EnemyState{EnemyPatrol, EnemyDetect, EnemyWindup, EnemyLunge, EnemyRecover},
+			wantPlayerHP: PlayerHP,
+			wantEnemyHP:  EnemyHP,
+		},
+		{
+			name:         "a hit interrupts the windup",
+			playerX:      94,
+			script:       []step{{idle, 12}, {attack, 1}, {idle, 5}},
+			wantStates:   []EnemyState{EnemyPatrol, EnemyDetect, EnemyWindup, EnemyRecover},
+			wantPlayerHP: PlayerHP,
+			wantEnemyHP:  EnemyHP - AttackDamage,
+		},
+		{
+			name:         "invincible through the next lunge",
+			playerX:      118,
+			script:       []step{{idle, 16}, {right, 12}},
+			wantStates:   []EnemyState{EnemyPatrol, EnemyDetect, EnemyWindup, EnemyLunge},
+			wantPlayerHP: PlayerHP - EnemyDamage,
+			wantEnemyHP:  EnemyHP,
+		},
+		{
+			name:         "the last hit kills",
+			playerX:      71,
+			playerHP:     1,
+			script:       []step{{idle, 45}},
+			wantStates:   []EnemyState{EnemyPatrol, EnemyDetect, EnemyWindup, EnemyLunge},
+			wantPlayerHP: 0,
+			wantEnemyHP:  EnemyHP,
+			wantResult:   ResultPlayerDead,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := duel(tt.playerX)
+			if tt.playerHP > 0 {
+				e.Player.HP = tt.playerHP
+			}
+			states := play(e, tt.script)
+			if !slices.Equal(states, tt.wantStates) {
+				t.Errorf("states = %v, want %v", states, tt.wantStates)
+			}
+			if e.Player.HP != tt.wantPlayerHP {
+				t.Errorf("Player HP = %d, want %d", e.Player.HP, tt.wantPlayerHP)
+			}
+			if e.Enemies[0].HP != tt.wantEnemyHP {
+				t.Errorf("Enemy HP = %d, want %d", e.Enemies[0].HP, tt.wantEnemyHP)
+			}
+			if e.Result != tt.wantResult {
+				t.Errorf("Result = %v, want %v", e.Result, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestEnemyAI_PatrolStaysNearHome(t *testing.T) {
+	e := duel(20)
+	minX, maxX := e.Enemies[0].Pos.X, e.Enemies[0].Pos.X
+	for range 300 {
+		e.Tick(idle)
+		minX, maxX = min(minX, e.Enemies[0].Pos.X), max(maxX, e.Enemies[0].Pos.X)
+	}
+	home := e.Enemies[0].HomeX - EnemyWidth/2
+	if minX < home-EnemyPatrolRange-2 || maxX > home+EnemyPatrolRange+2 {
+		t.Errorf("patrol ranged over X %v..%v, want within %v of %v", minX, maxX, EnemyPatrolRange, home)
+	}
+	if maxX-minX < EnemyPatrolRange {
+		t.Errorf("patrol only covered X %v..%v", minX, maxX)
+	}
+}
+
+func TestEnemyAI_PatrolKeepsToItsPlatform(t *testing.T) {
+	e := testEngine() // the enemy lands on Platform 3, x 104..140
+	for range 300 {
+		e.Tick(idle)
+		if e.Enemies[0].Pos.X < 104 || e.Enemies[0].Pos.X+EnemyWidth > 140 || !e.Enemies[0].Grounded {
+			t.Fatalf("enemy walked off its platform: %+v", e.Enemies[0].Pos)
+		}
+	}
+}
+
+func TestCanWalk(t *testing.T) {
+	platforms := []Platform{
+		{Rect{0, 112, 160, 8}}, // floor
+		{Rect{156, 0, 4, 120}}, // right wall
+		{Rect{20, 84, 36, 6}},  // ledge
+	}
+	tests := []struct {
+		name string
+		pos  Rect
+		dir  Dir
+		want bool
+	}{
+		{"open floor", Rect{80, 92, EnemyWidth, EnemyHeight}, DirRight, true},
+		{"wall ahead", Rect{156 - EnemyWidth, 92, EnemyWidth, EnemyHeight}, DirRight, false},
+		{"ledge edge", Rect{56 - EnemyWidth, 64, EnemyWidth, EnemyHeight}, DirRight, false},
+		{"back onto ledge", Rect{56 - EnemyWidth, 64, EnemyWidth, EnemyHeight}, DirLeft, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canWalk(tt.pos, tt.dir, platforms); got != tt.want {
+				t.Errorf("canWalk = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessContact(t *testing.T) {
+	tests := []struct {
+		name      string
+		playerX   float64
+		setup     func(*Player, *Enemy)
+		wantHP    int
+		wantState PlayerState
+		wantPush  Dir
+	}{
+		{"touching from the left", 44, nil, PlayerHP - 1, StateHurt, DirLeft},
+		{"touching from the right", 58, nil, PlayerHP - 1, StateHurt, DirRight},
+		{"out of reach", 30, nil, PlayerHP, StateIdle, 0},
+		{"grazing the inset", 50 - PlayerWidth + EnemyHitboxInset, nil, PlayerHP, StateIdle, 0},
+		{"invincible player", 40, func(p *Player, _ *Enemy) { p.InvincTimer = 0.5 }, PlayerHP, StateIdle, 0},
+		{"staggered enemy", 40, func(_ *Player, e *Enemy) { e.HurtTimer = 0.2 }, PlayerHP, StateIdle, 0},
+		{"dead enemy", 40, func(_ *Player, e *Enemy) { e.Alive = false }, PlayerHP, StateIdle, 0},
+		{"attack is cancelled", 44, func(p *Player, _ *Enemy) { p.State, p.AttackTimer = StateAttack, AttackDuration }, PlayerHP - 1, StateHurt, DirLeft},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := groundedPlayer()
+			p.Pos.X = tt.playerX
+			e := aliveEnemy(50)
+			e.Damage = 1
+			if tt.setup != nil {
+				tt.setup(&p, &e)
+			}
+
+			processContact(&e, &p, &tuning)
+
+			if p.HP != tt.wantHP {
+				t.Errorf("HP = %d, want %d", p.HP, tt.wantHP)
+			}
+			if p.State != tt.wantState {
+				t.Errorf("State = %v, want %v", p.State, tt.wantState)
+			}
+			if got := p.VelX * float64(tt.wantPush); tt.wantPush != 0 && got != KnockbackVel {
+				t.Errorf("VelX = %v, want a push of %v toward %v", p.VelX, KnockbackVel, tt.wantPush)
+			}
+			if tt.wantState == StateHurt && (p.InvincTimer != InvincTime || p.AttackTimer != 0) {
+				t.Errorf("InvincTimer = %v, AttackTimer = %v after a hit", p.InvincTimer, p.AttackTimer)
+			}
+		})
+	}
+}
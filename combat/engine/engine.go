@@ -3,49 +3,94 @@ package engine
 // Engine is the deterministic combat simulation. It has no UI dependencies
 // and imports only stdlib.
 type Engine struct {
+	Arena     Arena
+	Tuning    Tuning // may be changed between ticks
 	Player    Player
-	Enemy     Enemy
-	Platforms []Platform
+	Enemies   []*Enemy   // dead ones stay in the slice with Alive false
+	Platforms []Platform // the arena's walls and platforms
 	Result    Result
 	TickCount int
+
+	numEnemies int // how many enemies Reset spawns
+}
+
+// NewEngine creates an engine with the default arena, player, and one enemy,
+// tuned by tuning. More enemies can be added with SpawnEnemy.
+func NewEngine(tuning Tuning) *Engine {
+	return NewEngineIn(MustLoadArena(DefaultArena), 1, tuning)
 }
 
-// NewEngine creates an engine with the standard arena, player, and enemy.
-func NewEngine() *Engine {
-	e := &Engine{}
+// NewEngineIn creates an engine in the given arena, with an enemy on each of
+// its first numEnemies enemy spawns.
+func NewEngineIn(arena Arena, numEnemies int, tuning Tuning) *Engine {
+	e := &Engine{Arena: arena, Tuning: tuning, numEnemies: min(numEnemies, len(arena.Enemies))}
 	e.init()
 	return e
 }
 
 func (e *Engine) init() {
+	spawn := e.Arena.Player
 	e.Player = Player{
-		Pos:             Rect{20, 0, PlayerWidth, PlayerHeight},
+		Pos:             Rect{spawn.X - PlayerWidth/2, spawn.Y - PlayerHeight, PlayerWidth, PlayerHeight},
 		Facing:          DirRight,
 		State:           StateIdle,
 		HP:              PlayerHP,
 		MaxHP:           PlayerHP,
-		JumpBufferTimer: JumpBufferTime, // prevent false first-frame trigger
+		Damage:          AttackDamage,
+		JumpBufferTimer: e.Tuning.JumpBufferTime, // prevent false first-frame trigger
 	}
-	e.Enemy = Enemy{
-		Pos:   Rect{120, 0, EnemyWidth, EnemyHeight},
-		HP:    EnemyHP,
-		MaxHP: EnemyHP,
-		Facing: DirLeft,
-		Alive: true,
+	e.Enemies = nil
+	for _, spawn := range e.Arena.Enemies[:e.numEnemies] {
+		e.SpawnEnemy(spawn.X-EnemyWidth/2, spawn.Y-EnemyHeight)
 	}
-	e.Platforms = []Platform{
-		{Rect{0, 112, 160, 8}},    // Floor
-		{Rect{0, 0, 4, 120}},      // Left wall
-		{Rect{156, 0, 4, 120}},    // Right wall
-		{Rect{20, 84, 36, 6}},     // Platform 1
-		{Rect{62, 64, 36, 6}},     // Platform 2
-		{Rect{104, 84, 36, 6}},    // Platform 3
-		{Rect{70, 44, 20, 6}},     // Platform 4
+	e.Platforms = nil
+	for _, r := range e.Arena.solids() {
+		e.Platforms = append(e.Platforms, Platform{r})
 	}
 	e.Result = ResultNone
 	e.TickCount = 0
 }
 
+// SpawnEnemy adds a standard enemy with its top-left corner at (x, y),
+// patrolling around where it stands, and returns it.
+func (e *Engine) SpawnEnemy(x, y float64) *Enemy {
+	enemy := &Enemy{
+		Pos:    Rect{x, y, EnemyWidth, EnemyHeight},
+		HP:     EnemyHP,
+		MaxHP:  EnemyHP,
+		Damage: EnemyDamage,
+		Facing: DirLeft,
+		Alive:  true,
+		State:  EnemyPatrol,
+		HomeX:  x + EnemyWidth/2,
+	}
+	e.Enemies = append(e.Enemies, enemy)
+	return enemy
+}
+
+// Clone returns a copy of the engine that can be ticked without changing e,
+// to look ahead.
+func (e *Engine) Clone() *Engine {
+	c := *e
+	c.Enemies = make([]*Enemy, len(e.Enemies))
+	for i, enemy := range e.Enemies {
+		copied := *enemy
+		c.Enemies[i] = &copied
+	}
+	return &c
+}
+
+// EnemiesLeft returns how many enemies are still alive.
+func (e *Engine) EnemiesLeft() int {
+	n := 0
+	for _, enemy := range e.Enemies {
+		if enemy.Alive {
+			n++
+		}
+	}
+	return n
+}
+
 // Reset restores the engine to its initial state.
 func (e *Engine) Reset() {
 	e.init()
@@ -58,21 +103,31 @@ func (e *Engine) Tick(input InputState) {
 	}
 	e.TickCount++
 
-	// 1. Player input + state machine.
-	updatePlayer(&e.Player, input)
+	// 1. Player input + state machine, then the enemy AI.
+	t := &e.Tuning
+	updatePlayer(&e.Player, input, t)
+	for _, enemy := range e.Enemies {
+		if enemy.Alive {
+			updateEnemy(enemy, &e.Player, e.Platforms, t)
+		}
+	}
 
-	// 2. Apply gravity to player and enemy.
-	applyGravity(&e.Player.VelY)
-	if e.Enemy.Alive {
-		applyGravity(&e.Enemy.VelY)
+	// 2. Apply gravity to player and enemies.
+	applyGravity(&e.Player.VelY, t)
+	for _, enemy := range e.Enemies {
+		if enemy.Alive {
+			applyGravity(&enemy.VelY, t)
+		}
 	}
 
 	// 3. Move and resolve collisions.
 	moveAndResolve(&e.Player.Pos, &e.Player.VelX, &e.Player.VelY,
 		&e.Player.Grounded, e.Platforms)
-	if e.Enemy.Alive {
-		moveAndResolve(&e.Enemy.Pos, &e.Enemy.VelX, &e.Enemy.VelY,
-			&e.Enemy.Grounded, e.Platforms)
+	for _, enemy := range e.Enemies {
+		if enemy.Alive {
+			moveAndResolve(&enemy.Pos, &enemy.VelX, &enemy.VelY,
+				&enemy.Grounded, e.Platforms)
+		}
 	}
 
 	// 4. Update coyote timer.
@@ -82,12 +137,18 @@ func (e *Engine) Tick(input InputState) {
 		e.Player.CoyoteTimer += DT
 	}
 
-	// 5. Process attack hitbox / damage.
-	processAttack(&e.Player, &e.Enemy)
+	// 5. Process attack hitbox / damage, then enemy contact damage.
+	processAttack(&e.Player, e.Enemies, t)
+	for _, enemy := range e.Enemies {
+		processContact(enemy, &e.Player, t)
+	}
+	processHazards(&e.Player, e.Arena.Hazards, t)
 
 	// 6. Decrement enemy timers.
-	e.Enemy.HurtTimer = max(0, e.Enemy.HurtTimer-DT)
-	e.Enemy.InvincTimer = max(0, e.Enemy.InvincTimer-DT)
+	for _, enemy := range e.Enemies {
+		enemy.HurtTimer = max(0, enemy.HurtTimer-DT)
+		enemy.InvincTimer = max(0, enemy.InvincTimer-DT)
+	}
 
 	// 7. Decrement player timers.
 	e.Player.AttackTimer = max(0, e.Player.AttackTimer-DT)
@@ -104,8 +165,8 @@ func (e *Engine) Tick(input InputState) {
 		}
 	}
 
-	// 8. Check win/lose conditions (enemy death first).
-	if !e.Enemy.Alive {
+	// 8. Check win/lose conditions (enemy deaths first).
+	if e.EnemiesLeft() == 0 {
 		e.Result = ResultPlayerWin
 	} else if e.Player.HP <= 0 {
 		e.Result = ResultPlayerDead
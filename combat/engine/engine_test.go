@@ -3,7 +3,7 @@ package engine
 import "testing"
 
 func TestEngine_PlayerFallsToGround(t *testing.T) {
-	e := NewEngine()
+	e := NewEngine(DefaultTuning())
 	for i := 0; i < 60; i++ {
 		e.Tick(emptyInput())
 		if e.Player.Grounded {
@@ -14,10 +14,10 @@ func TestEngine_PlayerFallsToGround(t *testing.T) {
 }
 
 func TestEngine_EnemyFallsToGround(t *testing.T) {
-	e := NewEngine()
+	e := NewEngine(DefaultTuning())
 	for i := 0; i < 60; i++ {
 		e.Tick(emptyInput())
-		if e.Enemy.Grounded {
+		if e.Enemies[0].Grounded {
 			return
 		}
 	}
@@ -67,13 +67,13 @@ func TestEngine_FullCombat_PlayerWins(t *testing.T) {
 	// Place player directly left of enemy so the attack hitbox will overlap.
 	// Both on the floor (Y = FloorY - height).
 	floorY := 112.0
-	e.Player.Pos.X = e.Enemy.Pos.X - PlayerWidth - AttackOffsetX + AttackWidth/2
+	e.Player.Pos.X = e.Enemies[0].Pos.X - PlayerWidth - AttackOffsetX + AttackWidth/2
 	e.Player.Pos.Y = floorY - PlayerHeight
 	e.Player.Grounded = true
 	e.Player.Facing = DirRight
 
-	e.Enemy.Pos.Y = floorY - EnemyHeight
-	e.Enemy.Grounded = true
+	e.Enemies[0].Pos.Y = floorY - EnemyHeight
+	e.Enemies[0].Grounded = true
 
 	// Verify hitbox would overlap enemy from this position.
 	hb := AttackHitbox(&Player{
@@ -81,9 +81,9 @@ func TestEngine_FullCombat_PlayerWins(t *testing.T) {
 		Facing:      DirRight,
 		State:       StateAttack,
 		AttackTimer: AttackDuration,
-	})
-	if !hb.Overlaps(e.Enemy.Pos) {
-		t.Fatalf("setup error: hitbox %+v doesn't overlap enemy %+v", hb, e.Enemy.Pos)
+	}, &tuning)
+	if !hb.Overlaps(e.Enemies[0].Pos) {
+		t.Fatalf("setup error: hitbox %+v doesn't overlap enemy %+v", hb, e.Enemies[0].Pos)
 	}
 
 	// Attack EnemyHP times, waiting for invincibility between attacks.
@@ -95,11 +95,11 @@ func TestEngine_FullCombat_PlayerWins(t *testing.T) {
 
 		canAttack := e.Player.State != StateAttack &&
 			e.Player.AttackCooldownTimer <= 0 &&
-			e.Enemy.InvincTimer <= 0
+			e.Enemies[0].InvincTimer <= 0
 
-		if canAttack && e.Enemy.Alive {
+		if canAttack && e.Enemies[0].Alive {
 			// Reposition player next to enemy (knockback moves enemy each hit).
-			e.Player.Pos.X = e.Enemy.Pos.X - PlayerWidth - AttackOffsetX + AttackWidth/2
+			e.Player.Pos.X = e.Enemies[0].Pos.X - PlayerWidth - AttackOffsetX + AttackWidth/2
 			e.Tick(InputState{Attack: true})
 		} else {
 			e.Tick(emptyInput())
@@ -108,7 +108,54 @@ func TestEngine_FullCombat_PlayerWins(t *testing.T) {
 
 	if e.Result != ResultPlayerWin {
 		t.Errorf("Result = %v, want ResultPlayerWin (enemy HP=%d, alive=%v)",
-			e.Result, e.Enemy.HP, e.Enemy.Alive)
+			e.Result, e.Enemies[0].HP, e.Enemies[0].Alive)
+	}
+}
+
+func TestEngine_OneSwingHitsEveryEnemy(t *testing.T) {
+	e := testEngine()
+	e.Player.Pos = Rect{60, 112 - PlayerHeight, PlayerWidth, PlayerHeight}
+	hbX := e.Player.Pos.X + PlayerWidth/2 + AttackOffsetX - AttackWidth/2
+	e.Enemies[0].Pos = Rect{hbX, 112 - EnemyHeight, EnemyWidth, EnemyHeight}
+	e.SpawnEnemy(hbX+4, 112-EnemyHeight)
+	e.SpawnEnemy(140, 112-EnemyHeight) // out of reach
+
+	e.Tick(InputState{Attack: true})
+
+	for i, want := range []int{EnemyHP - AttackDamage, EnemyHP - AttackDamage, EnemyHP} {
+		if got := e.Enemies[i].HP; got != want {
+			t.Errorf("enemy %d HP = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestEngine_WinsWhenAllEnemiesDead(t *testing.T) {
+	e := testEngine()
+	e.Player.Pos.X = 20 // out of the enemies' sight on Platform 1
+	second := e.SpawnEnemy(70, 0)
+
+	e.Enemies[0].Alive = false
+	e.Tick(emptyInput())
+	if e.Result != ResultNone || e.EnemiesLeft() != 1 {
+		t.Fatalf("Result = %v with %d left, want ResultNone with 1", e.Result, e.EnemiesLeft())
+	}
+	second.Alive = false
+	e.Tick(emptyInput())
+	if e.Result != ResultPlayerWin {
+		t.Errorf("Result = %v, want ResultPlayerWin", e.Result)
+	}
+}
+
+func TestEngine_ContactFromTwoEnemiesHurtsOnce(t *testing.T) {
+	e := testEngine()
+	e.Player.Pos = Rect{60, 112 - PlayerHeight, PlayerWidth, PlayerHeight}
+	e.Enemies[0].Pos = Rect{56, 112 - EnemyHeight, EnemyWidth, EnemyHeight}
+	e.SpawnEnemy(62, 112-EnemyHeight)
+
+	e.Tick(emptyInput())
+
+	if e.Player.HP != PlayerHP-EnemyDamage {
+		t.Errorf("Player HP = %d, want %d", e.Player.HP, PlayerHP-EnemyDamage)
 	}
 }
 
@@ -117,6 +164,7 @@ func TestEngine_Reset(t *testing.T) {
 	// Modify state.
 	e.Tick(InputState{Right: true})
 	e.Tick(InputState{JumpPress: true, JumpHeld: true})
+	e.SpawnEnemy(70, 0)
 	e.TickCount = 100
 
 	e.Reset()
@@ -127,17 +175,32 @@ func TestEngine_Reset(t *testing.T) {
 	if e.Player.HP != PlayerHP {
 		t.Errorf("Player HP = %d, want %d", e.Player.HP, PlayerHP)
 	}
-	if e.Enemy.HP != EnemyHP {
-		t.Errorf("Enemy HP = %d, want %d", e.Enemy.HP, EnemyHP)
+	if e.Enemies[0].HP != EnemyHP {
+		t.Errorf("Enemy HP = %d, want %d", e.Enemies[0].HP, EnemyHP)
 	}
-	if !e.Enemy.Alive {
+	if !e.Enemies[0].Alive {
 		t.Error("Enemy should be alive after reset")
 	}
+	if len(e.Enemies) != 1 {
+		t.Errorf("%d enemies after reset, want 1", len(e.Enemies))
+	}
 	if e.Result != ResultNone {
 		t.Errorf("Result = %v, want ResultNone", e.Result)
 	}
 	assertNear(t, "Player.Pos.X", e.Player.Pos.X, 20, 0.1)
-	assertNear(t, "Enemy.Pos.X", e.Enemy.Pos.X, 120, 0.1)
+	assertNear(t, "Enemy.Pos.X", e.Enemies[0].Pos.X, 120, 0.1)
+}
+
+func TestEngine_CloneLeavesOriginal(t *testing.T) {
+	e := testEngine()
+	before := *e.Enemies[0]
+	c := e.Clone()
+	for range 30 {
+		c.Tick(InputState{Right: true, Attack: true})
+	}
+	if *e.Enemies[0] != before || e.TickCount == c.TickCount || e.Player == c.Player {
+		t.Errorf("ticking the clone changed the original: %+v", e.Enemies[0])
+	}
 }
 
 func TestEngine_NoUpdateAfterResult(t *testing.T) {
@@ -2,7 +2,10 @@ package engine
 
 // Rect is an axis-aligned bounding box.
 type Rect struct {
-	X, Y, W, H float64
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	W float64 `json:"w"`
+	H float64 `json:"h"`
 }
 
 // Overlaps reports whether a and b have a non-zero area intersection.
@@ -39,6 +42,7 @@ type Player struct {
 	State  PlayerState
 	HP     int
 	MaxHP  int
+	Damage int // damage per hit
 
 	Grounded bool
 
@@ -52,8 +56,9 @@ type Player struct {
 	AttackHit           bool // prevents multi-hit per swing
 }
 
-// Enemy is a minimal Phase 2 enemy: stands there, takes hits, dies.
-// Has physics for gravity (initial fall) and knockback.
+// Enemy is a walking melee enemy driven by the state machine in enemy.go:
+// it patrols around HomeX, lunges at the player when they come close, and
+// hurts them on contact.
 type Enemy struct {
 	Pos         Rect
 	VelX        float64
@@ -61,10 +66,15 @@ type Enemy struct {
 	Grounded    bool
 	HP          int
 	MaxHP       int
+	Damage      int // contact damage
 	Facing      Dir
 	HurtTimer   float64
 	InvincTimer float64
 	Alive       bool
+
+	State      EnemyState
+	StateTimer float64 // counts down; time left in Detect, Windup, Lunge or Recover
+	HomeX      float64 // centre of the patrol
 }
 
 // Platform is a solid collidable surface.
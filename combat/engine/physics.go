@@ -1,10 +1,10 @@
 package engine
 
 // applyGravity accelerates velY downward and clamps to MaxFallSpeed.
-func applyGravity(velY *float64) {
-	*velY += Gravity * DT
-	if *velY > MaxFallSpeed {
-		*velY = MaxFallSpeed
+func applyGravity(velY *float64, t *Tuning) {
+	*velY += t.Gravity * DT
+	if *velY > t.MaxFallSpeed {
+		*velY = t.MaxFallSpeed
 	}
 }
 
@@ -4,14 +4,14 @@ import "testing"
 
 func TestApplyGravity_IncreasesVelocity(t *testing.T) {
 	vel := 0.0
-	applyGravity(&vel)
+	applyGravity(&vel, &tuning)
 	want := Gravity * DT
 	assertNear(t, "velY after one tick", vel, want, 0.01)
 }
 
 func TestApplyGravity_ClampsToMaxFallSpeed(t *testing.T) {
 	vel := MaxFallSpeed - 1.0
-	applyGravity(&vel)
+	applyGravity(&vel, &tuning)
 	if vel > MaxFallSpeed {
 		t.Errorf("velY = %v, should be clamped to MaxFallSpeed %v", vel, MaxFallSpeed)
 	}
@@ -19,7 +19,7 @@ func TestApplyGravity_ClampsToMaxFallSpeed(t *testing.T) {
 
 func TestApplyGravity_AlreadyAtMax(t *testing.T) {
 	vel := MaxFallSpeed
-	applyGravity(&vel)
+	applyGravity(&vel, &tuning)
 	assertNear(t, "velY at max", vel, MaxFallSpeed, 0.01)
 }
 
@@ -3,10 +3,12 @@ package engine
 // updatePlayer processes input and updates the player state machine.
 // Called at step 1 of Tick — uses coyote/jump-buffer timers from the
 // PREVIOUS frame (one-frame lag is intentional and standard).
-func updatePlayer(p *Player, input InputState) {
-	// Hurt blocks all input.
+func updatePlayer(p *Player, input InputState, t *Tuning) {
+	// Hurt blocks all input. The knockback slides for the first part of it.
 	if p.State == StateHurt {
-		p.VelX = 0
+		if p.HurtTimer < t.HurtDuration-t.KnockbackTime {
+			p.VelX = 0
+		}
 		return
 	}
 
@@ -20,7 +22,7 @@ func updatePlayer(p *Player, input InputState) {
 			} else {
 				p.State = StateFall
 			}
-			p.AttackCooldownTimer = AttackCooldown
+			p.AttackCooldownTimer = t.AttackCooldown
 			p.AttackHit = false
 		}
 		return
@@ -36,36 +38,36 @@ func updatePlayer(p *Player, input InputState) {
 	// --- Movement ---
 	p.VelX = 0
 	if input.Left {
-		p.VelX = -RunSpeed
+		p.VelX = -t.RunSpeed
 		p.Facing = DirLeft
 	}
 	if input.Right {
-		p.VelX = RunSpeed
+		p.VelX = t.RunSpeed
 		p.Facing = DirRight
 	}
 
 	// --- Jump ---
-	canJump := p.Grounded || p.CoyoteTimer < CoyoteTime
-	wantsJump := p.JumpBufferTimer < JumpBufferTime
+	canJump := p.Grounded || p.CoyoteTimer < t.CoyoteTime
+	wantsJump := p.JumpBufferTimer < t.JumpBufferTime
 	if canJump && wantsJump {
-		p.VelY = -JumpForce
+		p.VelY = -t.JumpForce
 		p.State = StateJump
 		p.JumpCut = false
-		p.JumpBufferTimer = JumpBufferTime // consume the buffer
-		p.CoyoteTimer = CoyoteTime         // consume coyote time
+		p.JumpBufferTimer = t.JumpBufferTime // consume the buffer
+		p.CoyoteTimer = t.CoyoteTime         // consume coyote time
 		return
 	}
 
 	// --- Variable-height jump ---
 	if !input.JumpHeld && p.VelY < 0 && !p.JumpCut {
-		p.VelY *= JumpCutMultiplier
+		p.VelY *= t.JumpCutMultiplier
 		p.JumpCut = true
 	}
 
 	// --- Attack ---
 	if input.Attack && p.AttackCooldownTimer <= 0 {
 		p.State = StateAttack
-		p.AttackTimer = AttackDuration
+		p.AttackTimer = t.AttackDuration
 		p.AttackHit = false
 		p.VelX = 0
 		return
@@ -9,6 +9,7 @@ func groundedPlayer() Player {
 		State:           StateIdle,
 		HP:              PlayerHP,
 		MaxHP:           PlayerHP,
+		Damage:          AttackDamage,
 		Grounded:        true,
 		JumpBufferTimer: JumpBufferTime, // prevent false trigger
 	}
@@ -16,7 +17,7 @@ func groundedPlayer() Player {
 
 func TestPlayer_IdleNoInput(t *testing.T) {
 	p := groundedPlayer()
-	updatePlayer(&p, InputState{})
+	updatePlayer(&p, InputState{}, &tuning)
 	if p.State != StateIdle {
 		t.Errorf("State = %v, want StateIdle", p.State)
 	}
@@ -25,7 +26,7 @@ func TestPlayer_IdleNoInput(t *testing.T) {
 
 func TestPlayer_RunRight(t *testing.T) {
 	p := groundedPlayer()
-	updatePlayer(&p, InputState{Right: true})
+	updatePlayer(&p, InputState{Right: true}, &tuning)
 	if p.State != StateRun {
 		t.Errorf("State = %v, want StateRun", p.State)
 	}
@@ -37,7 +38,7 @@ func TestPlayer_RunRight(t *testing.T) {
 
 func TestPlayer_RunLeft(t *testing.T) {
 	p := groundedPlayer()
-	updatePlayer(&p, InputState{Left: true})
+	updatePlayer(&p, InputState{Left: true}, &tuning)
 	if p.State != StateRun {
 		t.Errorf("State = %v, want StateRun", p.State)
 	}
@@ -49,7 +50,7 @@ func TestPlayer_RunLeft(t *testing.T) {
 
 func TestPlayer_JumpFromGround(t *testing.T) {
 	p := groundedPlayer()
-	updatePlayer(&p, InputState{JumpPress: true, JumpHeld: true})
+	updatePlayer(&p, InputState{JumpPress: true, JumpHeld: true}, &tuning)
 	if p.State != StateJump {
 		t.Errorf("State = %v, want StateJump", p.State)
 	}
@@ -60,7 +61,7 @@ func TestPlayer_CoyoteTime_JumpSucceeds(t *testing.T) {
 	p := groundedPlayer()
 	p.Grounded = false
 	p.CoyoteTimer = CoyoteTime * 0.5 // within coyote window
-	updatePlayer(&p, InputState{JumpPress: true, JumpHeld: true})
+	updatePlayer(&p, InputState{JumpPress: true, JumpHeld: true}, &tuning)
 	if p.State != StateJump {
 		t.Errorf("expected jump during coyote time, State = %v", p.State)
 	}
@@ -71,7 +72,7 @@ func TestPlayer_CoyoteTime_JumpFails(t *testing.T) {
 	p := groundedPlayer()
 	p.Grounded = false
 	p.CoyoteTimer = CoyoteTime + 0.01 // past coyote window
-	updatePlayer(&p, InputState{JumpPress: true, JumpHeld: true})
+	updatePlayer(&p, InputState{JumpPress: true, JumpHeld: true}, &tuning)
 	if p.State == StateJump {
 		t.Error("should not jump after coyote time expired")
 	}
@@ -83,7 +84,7 @@ func TestPlayer_JumpBuffer(t *testing.T) {
 	p.CoyoteTimer = CoyoteTime + 1 // well past coyote
 
 	// Press jump while airborne.
-	updatePlayer(&p, InputState{JumpPress: true, JumpHeld: true})
+	updatePlayer(&p, InputState{JumpPress: true, JumpHeld: true}, &tuning)
 	if p.State == StateJump {
 		t.Fatal("should not jump while airborne past coyote")
 	}
@@ -91,7 +92,7 @@ func TestPlayer_JumpBuffer(t *testing.T) {
 	// Now land (set grounded). JumpBufferTimer was reset to 0 by the press above.
 	// It then incremented by DT, so it should still be < JumpBufferTime.
 	p.Grounded = true
-	updatePlayer(&p, InputState{JumpHeld: true}) // no new press, but buffer active
+	updatePlayer(&p, InputState{JumpHeld: true}, &tuning) // no new press, but buffer active
 	if p.State != StateJump {
 		t.Errorf("jump buffer should have triggered, State = %v, JumpBufferTimer = %v", p.State, p.JumpBufferTimer)
 	}
@@ -100,7 +101,7 @@ func TestPlayer_JumpBuffer(t *testing.T) {
 func TestPlayer_VariableHeightJump_CutsVelocity(t *testing.T) {
 	p := groundedPlayer()
 	// Initiate jump.
-	updatePlayer(&p, InputState{JumpPress: true, JumpHeld: true})
+	updatePlayer(&p, InputState{JumpPress: true, JumpHeld: true}, &tuning)
 	if p.VelY >= 0 {
 		t.Fatal("expected negative VelY after jump")
 	}
@@ -108,7 +109,7 @@ func TestPlayer_VariableHeightJump_CutsVelocity(t *testing.T) {
 	// Release jump while ascending.
 	origVel := p.VelY
 	p.Grounded = false
-	updatePlayer(&p, InputState{JumpHeld: false})
+	updatePlayer(&p, InputState{JumpHeld: false}, &tuning)
 	assertNear(t, "VelY after cut", p.VelY, origVel*JumpCutMultiplier, 0.01)
 	if !p.JumpCut {
 		t.Error("JumpCut should be true")
@@ -117,21 +118,21 @@ func TestPlayer_VariableHeightJump_CutsVelocity(t *testing.T) {
 
 func TestPlayer_VariableHeightJump_CutsOnlyOnce(t *testing.T) {
 	p := groundedPlayer()
-	updatePlayer(&p, InputState{JumpPress: true, JumpHeld: true})
+	updatePlayer(&p, InputState{JumpPress: true, JumpHeld: true}, &tuning)
 
 	// Release to cut.
 	p.Grounded = false
-	updatePlayer(&p, InputState{JumpHeld: false})
+	updatePlayer(&p, InputState{JumpHeld: false}, &tuning)
 	velAfterCut := p.VelY
 
 	// Release again — should not cut further.
-	updatePlayer(&p, InputState{JumpHeld: false})
+	updatePlayer(&p, InputState{JumpHeld: false}, &tuning)
 	assertNear(t, "VelY should not change", p.VelY, velAfterCut, 0.01)
 }
 
 func TestPlayer_AttackInitiation(t *testing.T) {
 	p := groundedPlayer()
-	updatePlayer(&p, InputState{Attack: true})
+	updatePlayer(&p, InputState{Attack: true}, &tuning)
 	if p.State != StateAttack {
 		t.Errorf("State = %v, want StateAttack", p.State)
 	}
@@ -142,7 +143,7 @@ func TestPlayer_AttackInitiation(t *testing.T) {
 func TestPlayer_AttackCooldown(t *testing.T) {
 	p := groundedPlayer()
 	p.AttackCooldownTimer = 0.1 // cooldown active
-	updatePlayer(&p, InputState{Attack: true})
+	updatePlayer(&p, InputState{Attack: true}, &tuning)
 	if p.State == StateAttack {
 		t.Error("should not attack while cooldown is active")
 	}
@@ -152,7 +153,7 @@ func TestPlayer_AttackEnds_TransitionsToIdle(t *testing.T) {
 	p := groundedPlayer()
 	p.State = StateAttack
 	p.AttackTimer = 0 // attack just finished
-	updatePlayer(&p, InputState{})
+	updatePlayer(&p, InputState{}, &tuning)
 	if p.State != StateIdle {
 		t.Errorf("State = %v, want StateIdle after attack ends on ground", p.State)
 	}
@@ -164,7 +165,7 @@ func TestPlayer_AttackEnds_TransitionsToFall(t *testing.T) {
 	p.State = StateAttack
 	p.AttackTimer = 0
 	p.Grounded = false
-	updatePlayer(&p, InputState{})
+	updatePlayer(&p, InputState{}, &tuning)
 	if p.State != StateFall {
 		t.Errorf("State = %v, want StateFall after attack ends in air", p.State)
 	}
@@ -174,7 +175,7 @@ func TestPlayer_HurtBlocksInput(t *testing.T) {
 	p := groundedPlayer()
 	p.State = StateHurt
 	p.HurtTimer = 0.3
-	updatePlayer(&p, InputState{Right: true, JumpPress: true, Attack: true})
+	updatePlayer(&p, InputState{Right: true, JumpPress: true, Attack: true}, &tuning)
 	assertNear(t, "VelX", p.VelX, 0, 0.01)
 	if p.State != StateHurt {
 		t.Errorf("State = %v, want StateHurt", p.State)
@@ -0,0 +1,251 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// replayVersion is bumped whenever the replay format changes.
+const replayVersion = 1
+
+// Replay is a recorded fight: the arena, the number of enemies, the tuning
+// and every tick's input. The engine is deterministic, so feeding the inputs
+// to a fresh engine built the same way reproduces the fight exactly. The
+// whole arena is kept rather than its name, so editing an arena file doesn't
+// change old replays.
+//
+// Outcome, if set, is how the recorded fight ended. Tests play the replays
+// in testdata/replays back and compare against it, so a change to the feel
+// of a fight shows up as a failing replay.
+type Replay struct {
+	Arena   Arena
+	Enemies int
+	Tuning  Tuning
+	Inputs  []InputState
+	Outcome *Outcome
+}
+
+// Outcome summarizes the state a fight ended in.
+type Outcome struct {
+	Result   Result    `json:"result"`
+	Ticks    int       `json:"ticks"`
+	PlayerHP int       `json:"player_hp"`
+	PlayerX  float64   `json:"player_x"`
+	PlayerY  float64   `json:"player_y"`
+	EnemyHP  []int     `json:"enemy_hp"`
+	EnemyX   []float64 `json:"enemy_x"`
+}
+
+// Outcome returns the engine's current state as an Outcome.
+func (e *Engine) Outcome() Outcome {
+	o := Outcome{
+		Result:   e.Result,
+		Ticks:    e.TickCount,
+		PlayerHP: e.Player.HP,
+		PlayerX:  e.Player.Pos.X,
+		PlayerY:  e.Player.Pos.Y,
+	}
+	for _, enemy := range e.Enemies {
+		o.EnemyHP = append(o.EnemyHP, enemy.HP)
+		o.EnemyX = append(o.EnemyX, enemy.Pos.X)
+	}
+	return o
+}
+
+// NewReplay starts a recording of a fight in e, which must not have ticked
+// yet. Record each tick's input with Record.
+func NewReplay(e *Engine) *Replay {
+	return &Replay{Arena: e.Arena, Enemies: e.numEnemies, Tuning: e.Tuning}
+}
+
+// Record appends one tick's input.
+func (r *Replay) Record(input InputState) {
+	r.Inputs = append(r.Inputs, input)
+}
+
+// Engine returns a fresh engine set up as the recorded one was.
+func (r *Replay) Engine() *Engine {
+	return NewEngineIn(r.Arena, r.Enemies, r.Tuning)
+}
+
+// Play runs the whole replay in a fresh engine and returns it.
+func (r *Replay) Play() *Engine {
+	e := r.Engine()
+	for _, input := range r.Inputs {
+		e.Tick(input)
+	}
+	return e
+}
+
+// LoadReplay reads a replay file.
+func LoadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := ParseReplay(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// replayFile is a replay as stored on disk. The inputs are run-length
+// encoded; see encodeInputs.
+type replayFile struct {
+	Version int             `json:"version"`
+	Arena   json.RawMessage `json:"arena"`
+	Enemies int             `json:"enemies"`
+	Tuning  json.RawMessage `json:"tuning"`
+	Inputs  string          `json:"inputs"`
+	Outcome *Outcome        `json:"outcome,omitempty"`
+}
+
+// ParseReplay decodes a replay from JSON.
+func ParseReplay(data []byte) (*Replay, error) {
+	var f replayFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("replay is not valid JSON: %w", err)
+	}
+	if f.Version != replayVersion {
+		return nil, fmt.Errorf("replay version %d is not supported (want %d)", f.Version, replayVersion)
+	}
+	if f.Enemies < 1 {
+		return nil, errors.New("replay needs at least one enemy")
+	}
+	r := &Replay{Enemies: f.Enemies, Tuning: DefaultTuning(), Outcome: f.Outcome}
+	var err error
+	if r.Arena, err = ParseArena(f.Arena); err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	if len(f.Tuning) > 0 {
+		if r.Tuning, err = ParseTuning(f.Tuning); err != nil {
+			return nil, fmt.Errorf("replay: %w", err)
+		}
+	}
+	if r.Inputs, err = decodeInputs(f.Inputs); err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	return r, nil
+}
+
+// Save writes the replay to a file, ready for LoadReplay. Each field gets a
+// line of its own, so replays diff well.
+func (r *Replay) Save(path string) error {
+	type field struct {
+		name  string
+		value any
+	}
+	fields := []field{
+		{"version", replayVersion},
+		{"arena", r.Arena},
+		{"enemies", r.Enemies},
+		{"tuning", r.Tuning},
+		{"inputs", encodeInputs(r.Inputs)},
+	}
+	if r.Outcome != nil {
+		fields = append(fields, field{"outcome", r.Outcome})
+	}
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i, f := range fields {
+		data, err := json.Marshal(f.value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&buf, "  %q: %s", f.name, data)
+		if i < len(fields)-1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("}\n")
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Input bits, as stored in a replay file.
+const (
+	inputLeft = 1 << iota
+	inputRight
+	inputJumpPress
+	inputJumpHeld
+	inputAttack
+	inputAll = inputLeft | inputRight | inputJumpPress | inputJumpHeld | inputAttack
+)
+
+func (in InputState) bits() int {
+	b := 0
+	if in.Left {
+		b |= inputLeft
+	}
+	if in.Right {
+		b |= inputRight
+	}
+	if in.JumpPress {
+		b |= inputJumpPress
+	}
+	if in.JumpHeld {
+		b |= inputJumpHeld
+	}
+	if in.Attack {
+		b |= inputAttack
+	}
+	return b
+}
+
+func inputFromBits(b int) InputState {
+	return InputState{
+		Left:      b&inputLeft != 0,
+		Right:     b&inputRight != 0,
+		JumpPress: b&inputJumpPress != 0,
+		JumpHeld:  b&inputJumpHeld != 0,
+		Attack:    b&inputAttack != 0,
+	}
+}
+
+// encodeInputs writes inputs as space-separated runs of "count*bits", or
+// just "bits" for a single tick. Input changes a few times a second at most,
+// so a fight of several minutes fits in a few kilobytes.
+func encodeInputs(inputs []InputState) string {
+	var runs []string
+	for i := 0; i < len(inputs); {
+		n := 1
+		for i+n < len(inputs) && inputs[i+n] == inputs[i] {
+			n++
+		}
+		run := strconv.Itoa(inputs[i].bits())
+		if n > 1 {
+			run = strconv.Itoa(n) + "*" + run
+		}
+		runs = append(runs, run)
+		i += n
+	}
+	return strings.Join(runs, " ")
+}
+
+func decodeInputs(s string) ([]InputState, error) {
+	var inputs []InputState
+	for _, run := range strings.Fields(s) {
+		count, bits := "1", run
+		if c, b, ok := strings.Cut(run, "*"); ok {
+			count, bits = c, b
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("bad input run %q", run)
+		}
+		b, err := strconv.Atoi(bits)
+		if err != nil || b < 0 || b&^inputAll != 0 {
+			return nil, fmt.Errorf("bad input run %q", run)
+		}
+		for range n {
+			inputs = append(inputs, inputFromBits(b))
+		}
+	}
+	return inputs, nil
+}
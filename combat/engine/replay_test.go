@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"flag"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the outcomes of the replays in testdata/replays")
+
+// replayEpsilon allows for floating-point differences between platforms,
+// such as fused multiply-adds, when comparing positions.
+const replayEpsilon = 0.5
+
+// TestReplays plays every recorded fight in testdata/replays and checks it
+// ends as it did when it was recorded. After a deliberate change to the feel
+// of a fight, run with -update to record the new outcomes.
+func TestReplays(t *testing.T) {
+	paths, err := filepath.Glob("testdata/replays/*.json")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no replays found: %v", err)
+	}
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".json"), func(t *testing.T) {
+			r, err := LoadReplay(path)
+			if err != nil {
+				t.Fatalf("LoadReplay failed: %v", err)
+			}
+			got := r.Play().Outcome()
+			if *update {
+				r.Outcome = &got
+				if err := r.Save(path); err != nil {
+					t.Fatalf("Save failed: %v", err)
+				}
+				return
+			}
+			if r.Outcome == nil {
+				t.Fatal("replay has no outcome; run with -update to record one")
+			}
+			want := *r.Outcome
+			if got.Result != want.Result || got.Ticks != want.Ticks || got.PlayerHP != want.PlayerHP ||
+				!slices.Equal(got.EnemyHP, want.EnemyHP) {
+				t.Errorf("outcome = %+v, want %+v", got, want)
+			}
+			assertNear(t, "PlayerX", got.PlayerX, want.PlayerX, replayEpsilon)
+			assertNear(t, "PlayerY", got.PlayerY, want.PlayerY, replayEpsilon)
+			for i := range min(len(got.EnemyX), len(want.EnemyX)) {
+				assertNear(t, "EnemyX", got.EnemyX[i], want.EnemyX[i], replayEpsilon)
+			}
+		})
+	}
+}
+
+func TestReplay_RecordSaveAndPlay(t *testing.T) {
+	tuned := DefaultTuning()
+	tuned.RunSpeed = 150
+	e := NewEngineIn(MustLoadArena("pit"), 2, tuned)
+	r := NewReplay(e)
+	script := []step{{idle, 20}, {right, 12}, {InputState{Right: true, JumpPress: true, JumpHeld: true}, 1},
+		{InputState{Right: true, JumpHeld: true}, 10}, {attack, 1}, {idle, 30}, {left, 8}, {attack, 1}, {idle, 40}}
+	for _, s := range script {
+		for range s.ticks {
+			r.Record(s.in)
+			e.Tick(s.in)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "fight.json")
+	if err := r.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := LoadReplay(path)
+	if err != nil {
+		t.Fatalf("LoadReplay failed: %v", err)
+	}
+	if loaded.Tuning != tuned || loaded.Enemies != 2 || loaded.Arena.Name != "Spike Pit" {
+		t.Errorf("loaded replay is set up differently: %+v", loaded)
+	}
+	if !slices.Equal(loaded.Inputs, r.Inputs) {
+		t.Errorf("inputs changed on the way through a file")
+	}
+	replayed := loaded.Play()
+	if !slices.EqualFunc(replayed.Enemies, e.Enemies, func(a, b *Enemy) bool { return *a == *b }) ||
+		replayed.Player != e.Player || replayed.TickCount != e.TickCount {
+		t.Errorf("replay ended in %+v, want %+v", replayed.Outcome(), e.Outcome())
+	}
+}
+
+func TestEncodeInputs(t *testing.T) {
+	jump := InputState{JumpPress: true, JumpHeld: true}
+	held := InputState{JumpHeld: true}
+	var inputs []InputState
+	for _, s := range []step{{idle, 30}, {jump, 1}, {held, 5}, {left, 2}, {InputState{Right: true, Attack: true}, 1}} {
+		for range s.ticks {
+			inputs = append(inputs, s.in)
+		}
+	}
+
+	got := encodeInputs(inputs)
+	if want := "30*0 12 5*8 2*1 18"; got != want {
+		t.Errorf("encodeInputs = %q, want %q", got, want)
+	}
+	decoded, err := decodeInputs(got)
+	if err != nil || !slices.Equal(decoded, inputs) {
+		t.Errorf("decodeInputs did not round-trip: %v", err)
+	}
+}
+
+func TestParseReplay_Rejects(t *testing.T) {
+	valid := `"arena": {"name": "Flat", "width": 160, "height": 120, "platforms": [{"x": 0, "y": 112, "w": 160, "h": 8}],
+		"player": {"x": 20, "y": 112}, "enemies": [{"x": 120, "y": 112}]}, "enemies": 1`
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"not json", `{`, "JSON"},
+		{"old version", `{"version": 0, ` + valid + `}`, "version 0"},
+		{"no enemies", `{"version": 1, ` + strings.Replace(valid, `"enemies": 1`, `"enemies": 0`, 1) + `}`, "at least one enemy"},
+		{"bad arena", `{"version": 1, "arena": {"width": 160}, "enemies": 1}`, "arena"},
+		{"bad tuning", `{"version": 1, ` + valid + `, "tuning": {"gravity": -1}}`, "gravity"},
+		{"bad run", `{"version": 1, ` + valid + `, "inputs": "3*x"}`, `"3*x"`},
+		{"unknown input bit", `{"version": 1, ` + valid + `, "inputs": "64"}`, `"64"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseReplay([]byte(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error about %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -8,10 +8,10 @@ import (
 // testEngine creates an engine and ticks until both player and enemy
 // are grounded. Panics if it takes more than 60 ticks.
 func testEngine() *Engine {
-	e := NewEngine()
+	e := NewEngine(DefaultTuning())
 	for i := 0; i < 60; i++ {
 		e.Tick(InputState{})
-		if e.Player.Grounded && e.Enemy.Grounded {
+		if e.Player.Grounded && e.Enemies[0].Grounded {
 			return e
 		}
 	}
@@ -30,3 +30,6 @@ func assertNear(t *testing.T, name string, got, want, epsilon float64) {
 		t.Errorf("%s = %v, want ~%v (epsilon %v)", name, got, want, epsilon)
 	}
 }
+
+// tuning is the default tuning, for calling the engine's internals directly.
+var tuning = DefaultTuning()
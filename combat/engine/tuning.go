@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// Tuning holds the feel of a fight: physics, timing windows, the attack, the
+// hurt reaction and the enemy AI. DefaultTuning fills it from the constants
+// in consts.go; a tuning file can override any of it without a rebuild.
+// Units are pixels, seconds and pixels per second (squared for Gravity).
+type Tuning struct {
+	Gravity      float64 `json:"gravity"`
+	MaxFallSpeed float64 `json:"max_fall_speed"`
+	JumpForce    float64 `json:"jump_force"`
+	RunSpeed     float64 `json:"run_speed"`
+
+	CoyoteTime        float64 `json:"coyote_time"`
+	JumpBufferTime    float64 `json:"jump_buffer_time"`
+	JumpCutMultiplier float64 `json:"jump_cut_multiplier"`
+
+	AttackDuration float64 `json:"attack_duration"`
+	AttackCooldown float64 `json:"attack_cooldown"`
+	AttackWidth    float64 `json:"attack_width"`
+	AttackHeight   float64 `json:"attack_height"`
+	AttackOffsetX  float64 `json:"attack_offset_x"`
+
+	HurtDuration  float64 `json:"hurt_duration"`
+	InvincTime    float64 `json:"invinc_time"`
+	KnockbackVel  float64 `json:"knockback_vel"`
+	KnockbackTime float64 `json:"knockback_time"`
+
+	EnemyPatrolSpeed  float64 `json:"enemy_patrol_speed"`
+	EnemyPatrolRange  float64 `json:"enemy_patrol_range"`
+	EnemyChaseSpeed   float64 `json:"enemy_chase_speed"`
+	EnemyDetectRange  float64 `json:"enemy_detect_range"`
+	EnemyLoseRange    float64 `json:"enemy_lose_range"`
+	EnemyDetectHeight float64 `json:"enemy_detect_height"`
+	EnemyDetectTime   float64 `json:"enemy_detect_time"`
+	EnemyLungeRange   float64 `json:"enemy_lunge_range"`
+	EnemyWindupTime   float64 `json:"enemy_windup_time"`
+	EnemyLungeSpeed   float64 `json:"enemy_lunge_speed"`
+	EnemyLungeTime    float64 `json:"enemy_lunge_time"`
+	EnemyRecoverTime  float64 `json:"enemy_recover_time"`
+}
+
+// DefaultTuning returns the tuning the game ships with.
+func DefaultTuning() Tuning {
+	return Tuning{
+		Gravity:      Gravity,
+		MaxFallSpeed: MaxFallSpeed,
+		JumpForce:    JumpForce,
+		RunSpeed:     RunSpeed,
+
+		CoyoteTime:        CoyoteTime,
+		JumpBufferTime:    JumpBufferTime,
+		JumpCutMultiplier: JumpCutMultiplier,
+
+		AttackDuration: AttackDuration,
+		AttackCooldown: AttackCooldown,
+		AttackWidth:    AttackWidth,
+		AttackHeight:   AttackHeight,
+		AttackOffsetX:  AttackOffsetX,
+
+		HurtDuration:  HurtDuration,
+		InvincTime:    InvincTime,
+		KnockbackVel:  KnockbackVel,
+		KnockbackTime: KnockbackTime,
+
+		EnemyPatrolSpeed:  EnemyPatrolSpeed,
+		EnemyPatrolRange:  EnemyPatrolRange,
+		EnemyChaseSpeed:   EnemyChaseSpeed,
+		EnemyDetectRange:  EnemyDetectRange,
+		EnemyLoseRange:    EnemyLoseRange,
+		EnemyDetectHeight: EnemyDetectHeight,
+		EnemyDetectTime:   EnemyDetectTime,
+		EnemyLungeRange:   EnemyLungeRange,
+		EnemyWindupTime:   EnemyWindupTime,
+		EnemyLungeSpeed:   EnemyLungeSpeed,
+		EnemyLungeTime:    EnemyLungeTime,
+		EnemyRecoverTime:  EnemyRecoverTime,
+	}
+}
+
+// LoadTuning reads a tuning file. Values it leaves out keep their defaults.
+func LoadTuning(path string) (Tuning, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Tuning{}, err
+	}
+	t, err := ParseTuning(data)
+	if err != nil {
+		return Tuning{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+// ParseTuning decodes a tuning from JSON on top of the defaults and checks it.
+// Unknown names are rejected, so a typo doesn't silently keep a default.
+func ParseTuning(data []byte) (Tuning, error) {
+	t := DefaultTuning()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&t); err != nil {
+		return Tuning{}, fmt.Errorf("tuning is not valid JSON: %w", err)
+	}
+	if err := t.Validate(); err != nil {
+		return Tuning{}, err
+	}
+	return t, nil
+}
+
+// Validate checks that every value is positive, the jump cut is a fraction,
+// and the knockback fits in the hurt reaction.
+func (t Tuning) Validate() error {
+	var errs []error
+	for _, p := range t.Params() {
+		if *p.Value <= 0 {
+			errs = append(errs, fmt.Errorf("tuning: %s must be positive, got %v", p.Name, *p.Value))
+		}
+	}
+	if t.JumpCutMultiplier > 1 {
+		errs = append(errs, fmt.Errorf("tuning: jump_cut_multiplier must be at most 1, got %v", t.JumpCutMultiplier))
+	}
+	if t.KnockbackTime > t.HurtDuration {
+		errs = append(errs, fmt.Errorf("tuning: knockback_time %v is longer than hurt_duration %v", t.KnockbackTime, t.HurtDuration))
+	}
+	return errors.Join(errs...)
+}
+
+// Save writes the tuning to a file as indented JSON, ready for LoadTuning.
+func (t Tuning) Save(path string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Param is one tunable value, named as in a tuning file.
+type Param struct {
+	Name  string
+	Value *float64
+	Step  float64 // a sensible nudge: a twentieth of the default
+}
+
+// Params lists every value in t, in file order, for editing in place.
+func (t *Tuning) Params() []Param {
+	defaults := reflect.ValueOf(DefaultTuning())
+	v := reflect.ValueOf(t).Elem()
+	params := make([]Param, v.NumField())
+	for i := range params {
+		params[i] = Param{
+			Name:  v.Type().Field(i).Tag.Get("json"),
+			Value: v.Field(i).Addr().Interface().(*float64),
+			Step:  defaults.Field(i).Float() / 20,
+		}
+	}
+	return params
+}
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaultTuning_Valid(t *testing.T) {
+	if err := DefaultTuning().Validate(); err != nil {
+		t.Errorf("default tuning is invalid: %v", err)
+	}
+}
+
+func TestParseTuning_KeepsDefaults(t *testing.T) {
+	got, err := ParseTuning([]byte(`{"jump_force": 350, "enemy_windup_time": 0.25}`))
+	if err != nil {
+		t.Fatalf("ParseTuning failed: %v", err)
+	}
+	want := DefaultTuning()
+	want.JumpForce, want.EnemyWindupTime = 350, 0.25
+	if got != want {
+		t.Errorf("ParseTuning = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseTuning_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"not json", `{`, "JSON"},
+		{"typo", `{"jump_forse": 350}`, "jump_forse"},
+		{"zero gravity", `{"gravity": 0}`, "gravity must be positive"},
+		{"negative speed", `{"run_speed": -1}`, "run_speed must be positive"},
+		{"jump cut above 1", `{"jump_cut_multiplier": 1.5}`, "at most 1"},
+		{"knockback outlasts hurt", `{"knockback_time": 0.6}`, "longer than hurt_duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTuning([]byte(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error about %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestTuning_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tuning.json")
+	tuned := DefaultTuning()
+	tuned.Gravity, tuned.AttackCooldown = 900, 0.3
+
+	if err := tuned.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := LoadTuning(path)
+	if err != nil {
+		t.Fatalf("LoadTuning failed: %v", err)
+	}
+	if loaded != tuned {
+		t.Errorf("LoadTuning = %+v, want %+v", loaded, tuned)
+	}
+	if _, err := LoadTuning(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestTuning_Params(t *testing.T) {
+	tuned := DefaultTuning()
+	params := tuned.Params()
+	if n := reflect.TypeOf(tuned).NumField(); len(params) != n {
+		t.Fatalf("%d params, want one per field (%d)", len(params), n)
+	}
+	for _, p := range params {
+		if p.Name == "" || p.Step <= 0 {
+			t.Errorf("param %+v needs a name and a step", p)
+		}
+	}
+	if params[2].Name != "jump_force" {
+		t.Fatalf("params[2] = %q, want jump_force", params[2].Name)
+	}
+	*params[2].Value = 123
+	if tuned.JumpForce != 123 {
+		t.Errorf("JumpForce = %v, want the param to edit it in place", tuned.JumpForce)
+	}
+}
+
+// jumpApex returns how high the player rises on a held jump.
+func jumpApex(e *Engine) float64 {
+	groundY := e.Player.Pos.Y
+	e.Tick(InputState{JumpPress: true, JumpHeld: true})
+	top := groundY
+	for range 60 {
+		e.Tick(InputState{JumpHeld: true})
+		top = min(top, e.Player.Pos.Y)
+	}
+	return groundY - top
+}
+
+func TestEngine_TuningChangesFeel(t *testing.T) {
+	base := jumpApex(testEngine())
+
+	floaty := testEngine()
+	floaty.Tuning.Gravity /= 2 // live change between ticks
+	if got := jumpApex(floaty); got <= base {
+		t.Errorf("apex with half gravity = %v, want above %v", got, base)
+	}
+
+	tuned := DefaultTuning()
+	tuned.JumpForce *= 0.8
+	low := NewEngine(tuned)
+	for range 60 {
+		low.Tick(emptyInput())
+	}
+	if got := jumpApex(low); got >= base {
+		t.Errorf("apex with weaker jump = %v, want below %v", got, base)
+	}
+}
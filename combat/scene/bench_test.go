@@ -0,0 +1,70 @@
+package scene
+
+import (
+	"testing"
+
+	"text-adventure-v2/combat/engine"
+	"text-adventure-v2/pixelbuf"
+)
+
+// benchScale draws the hall at 320x240 pixels, 320x120 cells: about a
+// full-screen terminal.
+const benchScale = 2
+
+// combatFrames draws two seconds of a fight in the hall: the player runs at
+// two enemies, jumping and swinging, while they patrol and lunge.
+func combatFrames(b *testing.B) []*pixelbuf.Buffer {
+	a, err := engine.LoadArena("hall")
+	if err != nil {
+		b.Fatal(err)
+	}
+	e := engine.NewEngineIn(a, 2, engine.DefaultTuning())
+	var frames []*pixelbuf.Buffer
+	for i := range 2 * engine.TickRate {
+		e.Tick(engine.InputState{Right: true, JumpPress: i%20 == 0, JumpHeld: i%20 < 8, Attack: i%10 == 5})
+		buf := pixelbuf.NewBuffer(int(a.Width*benchScale), int(a.Height*benchScale))
+		Draw(buf, e, benchScale)
+		frames = append(frames, buf)
+	}
+	return frames
+}
+
+// BenchmarkRender is the baseline: every frame written in full.
+func BenchmarkRender(b *testing.B) {
+	frames := combatFrames(b)
+	var bytes int
+	b.ResetTimer()
+	for i := range b.N {
+		bytes += len(pixelbuf.Render(frames[i%len(frames)]))
+	}
+	b.ReportMetric(float64(bytes)/float64(b.N), "bytes/frame")
+}
+
+// BenchmarkRenderer writes only what changed since the frame before.
+func BenchmarkRenderer(b *testing.B) {
+	frames := combatFrames(b)
+	r := pixelbuf.NewRenderer()
+	r.Render(frames[len(frames)-1])
+	var bytes int
+	b.ResetTimer()
+	for i := range b.N {
+		bytes += len(r.Render(frames[i%len(frames)]))
+	}
+	b.ReportMetric(float64(bytes)/float64(b.N), "bytes/frame")
+}
+
+// BenchmarkRenderWith writes every frame in full in each encoding, fitting
+// two colors to each cell for quadrants and braille.
+func BenchmarkRenderWith(b *testing.B) {
+	frames := combatFrames(b)
+	for _, name := range pixelbuf.EncodingNames() {
+		enc, _ := pixelbuf.ParseEncoding(name)
+		b.Run(name, func(b *testing.B) {
+			var bytes int
+			for i := range b.N {
+				bytes += len(pixelbuf.RenderWith(frames[i%len(frames)], pixelbuf.Options{Encoding: enc}))
+			}
+			b.ReportMetric(float64(bytes)/float64(b.N), "bytes/frame")
+		})
+	}
+}
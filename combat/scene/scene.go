@@ -0,0 +1,92 @@
+// Package scene draws a combat/engine fight into a pixelbuf.Buffer: the
+// arena, the knight and Husk Guard sprites, the attack hitbox and HP pips.
+// It only composes the frame; showing it is up to the caller.
+package scene
+
+import (
+	"text-adventure-v2/combat/engine"
+	"text-adventure-v2/pixelbuf"
+)
+
+// Colors.
+var (
+	bgColor    = pixelbuf.Color{R: 20, G: 20, B: 30, A: 255}
+	platColor  = pixelbuf.Color{R: 80, G: 80, B: 100, A: 255}
+	playerCol  = pixelbuf.Color{R: 100, G: 200, B: 255, A: 255}
+	enemyCol   = pixelbuf.Color{R: 255, G: 80, B: 80, A: 255}
+	windupCol  = pixelbuf.Color{R: 255, G: 160, B: 60, A: 255}
+	attackCol  = pixelbuf.Color{R: 255, G: 255, B: 100, A: 255}
+	hazardCol  = pixelbuf.Color{R: 200, G: 60, B: 200, A: 255}
+	hpFullCol  = pixelbuf.Color{R: 80, G: 220, B: 80, A: 255}
+	hpEmptyCol = pixelbuf.Color{R: 80, G: 20, B: 20, A: 255}
+	whiteCol   = pixelbuf.Color{R: 255, G: 255, B: 255, A: 255}
+)
+
+// Draw paints the state of e over all of buf, with scale buffer pixels to
+// each engine unit.
+func Draw(buf *pixelbuf.Buffer, e *engine.Engine, scale float64) {
+	s := func(v float64) int { return int(v * scale) }
+
+	buf.Clear(bgColor)
+
+	// Platforms.
+	for _, p := range e.Platforms {
+		r := p.Rect
+		pixelbuf.FillRect(buf, s(r.X), s(r.Y), s(r.W), s(r.H), platColor)
+	}
+
+	// Hazards.
+	for _, h := range e.Arena.Hazards {
+		pixelbuf.FillRect(buf, s(h.X), s(h.Y), s(h.W), s(h.H), hazardCol)
+	}
+
+	// Enemies, flashing white while hurt.
+	for _, enemy := range e.Enemies {
+		if !enemy.Alive {
+			continue
+		}
+		img := huskFrame(e, enemy)
+		if enemy.HurtTimer > 0 && int(enemy.HurtTimer*20)%2 == 0 {
+			img = silhouette(img, whiteCol)
+		}
+		pixelbuf.BlitScaled(buf, img, s(enemy.Pos.X), s(enemy.Pos.Y), s(enemy.Pos.W), s(enemy.Pos.H))
+	}
+
+	// Player, blinking while invincible.
+	p := &e.Player
+	if p.InvincTimer <= 0 || int(p.InvincTimer*10)%2 != 0 {
+		pixelbuf.BlitScaled(buf, knightFrame(e), s(p.Pos.X), s(p.Pos.Y), s(p.Pos.W), s(p.Pos.H))
+	}
+
+	// Attack hitbox.
+	hb := engine.AttackHitbox(p, &e.Tuning)
+	if hb.W > 0 {
+		pixelbuf.FillRect(buf, s(hb.X), s(hb.Y), s(hb.W), s(hb.H), attackCol)
+	}
+
+	// HP pips at top of screen.
+	pipW := max(2, s(3))
+	pipH := max(2, s(3))
+	pipGap := max(1, s(1))
+	drawHP(buf, s(6), s(2), pipW, pipH, pipGap, p.HP, p.MaxHP)
+	// Enemy HP sits above each enemy's head, in smaller pips.
+	for _, enemy := range e.Enemies {
+		if !enemy.Alive {
+			continue
+		}
+		size, gap := max(1, s(2)), max(1, s(1)/2)
+		cx, _ := enemy.Pos.Center()
+		x := s(cx) - (enemy.MaxHP*(size+gap)-gap)/2
+		drawHP(buf, x, s(enemy.Pos.Y)-size-gap, size, size, gap, enemy.HP, enemy.MaxHP)
+	}
+}
+
+func drawHP(buf *pixelbuf.Buffer, x, y, pipW, pipH, pipGap, hp, maxHP int) {
+	for i := 0; i < maxHP; i++ {
+		col := hpEmptyCol
+		if i < hp {
+			col = hpFullCol
+		}
+		pixelbuf.FillRect(buf, x+i*(pipW+pipGap), y, pipW, pipH, col)
+	}
+}
@@ -0,0 +1,163 @@
+package scene
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"text-adventure-v2/combat/engine"
+	"text-adventure-v2/pixelbuf"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden frames in testdata/golden")
+
+// fight starts a fight in the named arena for a golden frame.
+func fight(t *testing.T, arena string, enemies int) *engine.Engine {
+	t.Helper()
+	a, err := engine.LoadArena(arena)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return engine.NewEngineIn(a, enemies, engine.DefaultTuning())
+}
+
+// goldens are the states whose frames are checked against
+// testdata/golden/<name>.png.
+var goldens = []struct {
+	name  string
+	scale float64
+	setup func(t *testing.T) *engine.Engine
+}{
+	{"hall_start", 1, func(t *testing.T) *engine.Engine {
+		return fight(t, "hall", 1)
+	}},
+	{"running_left", 1, func(t *testing.T) *engine.Engine {
+		e := fight(t, "hall", 1)
+		for range 5 {
+			e.Tick(engine.InputState{Left: true})
+		}
+		return e
+	}},
+	{"attack", 1, func(t *testing.T) *engine.Engine {
+		e := fight(t, "hall", 1)
+		e.Tick(engine.InputState{Attack: true})
+		e.Tick(engine.InputState{})
+		return e
+	}},
+	{"enemy_windup_and_hurt", 1, func(t *testing.T) *engine.Engine {
+		e := fight(t, "hall", 2)
+		e.Enemies[0].State, e.Enemies[0].StateTimer = engine.EnemyWindup, 0.1
+		e.Enemies[1].HurtTimer, e.Enemies[1].HP = 0.1, 1
+		return e
+	}},
+	{"player_hurt", 1, func(t *testing.T) *engine.Engine {
+		e := fight(t, "hall", 1)
+		p := &e.Player
+		p.State, p.HurtTimer, p.InvincTimer, p.HP = engine.StateHurt, 0.1, 0.15, 3
+		return e
+	}},
+	{"pit_scaled", 2, func(t *testing.T) *engine.Engine {
+		e := fight(t, "pit", 3)
+		e.Enemies[2].Alive = false
+		return e
+	}},
+}
+
+// TestGoldenFrames draws each golden state and compares it pixel for pixel
+// with its committed frame. After a deliberate change to how fights look,
+// run with -update to redraw the goldens, and review the new images.
+func TestGoldenFrames(t *testing.T) {
+	for _, tt := range goldens {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.setup(t)
+			got := pixelbuf.NewBuffer(int(e.Arena.Width*tt.scale), int(e.Arena.Height*tt.scale))
+			Draw(got, e, tt.scale)
+
+			path := filepath.Join("testdata", "golden", tt.name+".png")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := pixelbuf.SavePNG(path, got); err != nil {
+					t.Fatalf("SavePNG failed: %v", err)
+				}
+				return
+			}
+			want, err := pixelbuf.LoadPNG(path)
+			if err != nil {
+				t.Fatalf("no golden frame (run with -update to draw one): %v", err)
+			}
+			if d := diff(got, want); d != "" {
+				t.Errorf("frame differs from %s (run with -update if the change is deliberate):\n%s", path, d)
+			}
+		})
+	}
+}
+
+// maxDiffMap bounds the size of the map diff draws, in pixels each way.
+const maxDiffMap = 80
+
+// diff describes how got differs from want, or returns "" if they match:
+// the number of changed pixels, the first few of them, and a map of the
+// region they fall in with '#' for a changed pixel.
+func diff(got, want *pixelbuf.Buffer) string {
+	if got.Width != want.Width || got.Height != want.Height {
+		return fmt.Sprintf("size = %dx%d, want %dx%d", got.Width, got.Height, want.Width, want.Height)
+	}
+	var sb strings.Builder
+	n, x0, y0, x1, y1 := 0, got.Width, got.Height, -1, -1
+	for y := 0; y < got.Height; y++ {
+		for x := 0; x < got.Width; x++ {
+			g, w := got.At(x, y), want.At(x, y)
+			if g == w {
+				continue
+			}
+			if n < 5 {
+				fmt.Fprintf(&sb, "  (%d,%d) = %v, want %v\n", x, y, g, w)
+			}
+			n++
+			x0, y0, x1, y1 = min(x0, x), min(y0, y), max(x1, x), max(y1, y)
+		}
+	}
+	if n == 0 {
+		return ""
+	}
+	fmt.Fprintf(&sb, "%d pixels differ, within (%d,%d)-(%d,%d):\n", n, x0, y0, x1, y1)
+	for y := y0; y <= min(y1, y0+maxDiffMap-1); y++ {
+		sb.WriteString("  ")
+		for x := x0; x <= min(x1, x0+maxDiffMap-1); x++ {
+			if got.At(x, y) == want.At(x, y) {
+				sb.WriteByte('.')
+			} else {
+				sb.WriteByte('#')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func TestDiff(t *testing.T) {
+	red := pixelbuf.Color{R: 255, A: 255}
+	a, b := pixelbuf.NewBuffer(4, 3), pixelbuf.NewBuffer(4, 3)
+	if d := diff(a, b); d != "" {
+		t.Errorf("diff of equal buffers = %q, want \"\"", d)
+	}
+	b.Set(1, 0, red)
+	b.Set(2, 1, red)
+	want := "" +
+		"  (1,0) = {0 0 0 0}, want {255 0 0 255}\n" +
+		"  (2,1) = {0 0 0 0}, want {255 0 0 255}\n" +
+		"2 pixels differ, within (1,0)-(2,1):\n" +
+		"  #.\n" +
+		"  .#\n"
+	if d := diff(a, b); d != want {
+		t.Errorf("diff =\n%s\nwant\n%s", d, want)
+	}
+	if d := diff(a, pixelbuf.NewBuffer(2, 2)); d != "size = 4x3, want 2x2" {
+		t.Errorf("diff of different sizes = %q", d)
+	}
+}
@@ -0,0 +1,364 @@
+package scene
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"text-adventure-v2/combat/engine"
+	"text-adventure-v2/pixelbuf"
+)
+
+// The knight and the Husk Guard are drawn as string art, facing right, one
+// art pixel to one engine unit: the knight is the player's 12x20 body and
+// the Husk Guard an enemy's 14x20. pixelbuf mirrors them to face left.
+
+var knightPalette = pixelbuf.Palette{
+	'o': {R: 30, G: 40, B: 60, A: 255},    // outline
+	'a': playerCol,                        // armour
+	'l': {R: 200, G: 235, B: 255, A: 255}, // highlight
+	'v': {R: 15, G: 15, B: 25, A: 255},    // visor slit
+	'c': {R: 80, G: 60, B: 160, A: 255},   // cape
+	's': {R: 230, G: 230, B: 240, A: 255}, // blade
+	'g': {R: 220, G: 180, B: 60, A: 255},  // hilt and buckle
+}
+
+// The knight's frames are an upper body over a pair of legs.
+var (
+	knightBody = []string{
+		"....oooo....",
+		"...oaaaao...",
+		"...oalaao..s",
+		"...oaavvo..s",
+		"...oaaaao..s",
+		"....oaao...s",
+		"..cooaaoo..s",
+		".ccoallaao.s",
+		".ccoallaaoog",
+		".ccoallaao.o",
+		".cc.oooo....",
+		".c..oggo....",
+	}
+	knightSwing = []string{
+		"....oooo....",
+		"...oaaaao...",
+		"...oalaao...",
+		"...oaavvo...",
+		"...oaaaao...",
+		"....oaao....",
+		"..cooaaoo...",
+		".ccoallaaooo",
+		".ccoallaaagg",
+		".ccoallaaooo",
+		".cc.oooo....",
+		".c..oggo....",
+	}
+	knightRecoil = []string{
+		"...oooo.....",
+		"..oaaaao....",
+		"..oalaao....",
+		"..oaavvo....",
+		"..oaaaao....",
+		"...oaao..o..",
+		".cooaaoooo..",
+		"ccoallaao...",
+		"ccoallaao...",
+		"ccoallaao...",
+		"cc.oooo.....",
+		"c..oggo.....",
+	}
+
+	knightStand = []string{
+		"....oaao....",
+		"...oa..ao...",
+		"...oa..ao...",
+		"...oa..ao...",
+		"...oa..ao...",
+		"...oa..ao...",
+		"..ooa..aoo..",
+		"..ooo..ooo..",
+	}
+	knightStride = []string{
+		"....oaao....",
+		"...oa..ao...",
+		"...oa..ao...",
+		"..oa....ao..",
+		"..oa....ao..",
+		".oa......ao.",
+		".oa......ao.",
+		"oo........oo",
+	}
+	knightStep = []string{
+		"....oaao....",
+		"....oaao....",
+		"....oaaao...",
+		"....oa.ao...",
+		"....oa.oao..",
+		"....oa..o...",
+		"....oa......",
+		"...ooo......",
+	}
+	knightTuck = []string{
+		"....oaao....",
+		"...oaaaao...",
+		"..oao..oao..",
+		"..oao..oao..",
+		"..oo....oo..",
+		"............",
+		"............",
+		"............",
+	}
+	knightDangle = []string{
+		"....oaao....",
+		"...oa..ao...",
+		"...oa..ao...",
+		"..oa....ao..",
+		"..oa....ao..",
+		"..oo....oo..",
+		"............",
+		"............",
+	}
+)
+
+var huskPalette = pixelbuf.Palette{
+	'o': {R: 50, G: 15, B: 20, A: 255},    // outline
+	'm': {R: 230, G: 220, B: 200, A: 255}, // bone mask
+	'e': {R: 255, G: 230, B: 120, A: 255}, // eyes
+	'h': enemyCol,                         // shell
+	'd': {R: 150, G: 40, B: 45, A: 255},   // shell plates
+	's': {R: 190, G: 190, B: 200, A: 255}, // nail
+}
+
+// huskWindupPalette turns the shell orange to telegraph the lunge.
+var huskWindupPalette = func() pixelbuf.Palette {
+	p := maps.Clone(huskPalette)
+	p['h'] = windupCol
+	return p
+}()
+
+var (
+	huskWalk = []string{
+		".....oooo.....",
+		"....ommmmo....",
+		"...ommmmmmo...",
+		"...ommmoeoe...",
+		"...ommmmmmo...",
+		"....ommmmo....",
+		"...oohhhhoo...",
+		"..ohhhhhhhho.s",
+		".ohhdhhhhdhhos",
+		".ohhdhhhhdhhos",
+		".ohhdhhhhdhhos",
+		"..ohhhhhhhhoo.",
+		"..ohddddddho..",
+		"...ohhhhhho...",
+		"...oho..oho...",
+		"...oho..oho...",
+		"..oho....oho..",
+		"..oho....oho..",
+		".oho......oho.",
+		".ooo......ooo.",
+	}
+	huskStep = []string{
+		".....oooo.....",
+		"....ommmmo....",
+		"...ommmmmmo...",
+		"...ommmoeoe...",
+		"...ommmmmmo...",
+		"....ommmmo....",
+		"...oohhhhoo...",
+		"..ohhhhhhhho.s",
+		".ohhdhhhhdhhos",
+		".ohhdhhhhdhhos",
+		".ohhdhhhhdhhos",
+		"..ohhhhhhhhoo.",
+		"..ohddddddho..",
+		"...ohhhhhho...",
+		"....oho.oho...",
+		"....oho.oho...",
+		"....oho.oho...",
+		"....oho.oho...",
+		"....oho.oho...",
+		"...oooo.ooo...",
+	}
+	huskWindup = []string{
+		"s.............",
+		"so...oooo.....",
+		"sho.ommmmo....",
+		".sooommmmmo...",
+		"..oommmoeoe...",
+		"...ommmmmmo...",
+		"...oommmmo....",
+		"..ohhhhhhhoo..",
+		".ohhhhhhhhhho.",
+		".ohhdhhhhdhho.",
+		".ohhdhhhhdhho.",
+		".ohhdhhhhdhho.",
+		"..ohddddddho..",
+		"...ohhhhhho...",
+		"...oho..oho...",
+		"..oho....oho..",
+		"..oho....oho..",
+		".oho......oho.",
+		".oho......oho.",
+		"ooo........ooo",
+	}
+	huskLunge = []string{
+		"..............",
+		"..............",
+		"..............",
+		".......oooo...",
+		"......ommmmo..",
+		".....ommmmmmo.",
+		".....ommmoeoe.",
+		"...oohommmmmo.",
+		"..ohhhhommmo..",
+		".ohhdhhhhhhooo",
+		".ohhdhhhhhhsss",
+		".ohhdhhhhhhooo",
+		"..ohddddddho..",
+		"...ohhhhhho...",
+		"..oho...oho...",
+		".oho.....oho..",
+		"oho.......oho.",
+		"oho........oho",
+		"oo.........ooo",
+		"..............",
+	}
+	huskSlump = []string{
+		"..............",
+		"..............",
+		".....oooo.....",
+		"....ommmmo....",
+		"...ommmmmmo...",
+		"...ommmoeoe...",
+		"...ommmmmmo...",
+		"...oommmmoo...",
+		"..ohhhhhhhho..",
+		".ohhdhhhhdhho.",
+		".ohhdhhhhdhho.",
+		".ohhdhhhhdhho.",
+		"..ohhhhhhhhoo.",
+		"..ohddddddhos.",
+		"...ohhhhhhoss.",
+		"...oho..ohos..",
+		"..oho....oho..",
+		"..oho....oho..",
+		".oho......oho.",
+		".ooo......ooo.",
+	}
+)
+
+var knight, husk = newKnight(), newHusk()
+
+func newKnight() *pixelbuf.Sprite {
+	s := pixelbuf.NewSprite()
+	frame := func(name string, body, legs []string) {
+		s.AddFrame(name, pixelbuf.MustParseArt(knightPalette, append(slices.Clone(body), legs...)...))
+	}
+	frame("stand", knightBody, knightStand)
+	frame("stride", knightBody, knightStride)
+	frame("step", knightBody, knightStep)
+	frame("tuck", knightBody, knightTuck)
+	frame("dangle", knightBody, knightDangle)
+	frame("swing", knightSwing, knightStride)
+	frame("recoil", knightRecoil, knightStand)
+
+	hold := func(frame string) pixelbuf.Animation {
+		return pixelbuf.Animation{Steps: []pixelbuf.Step{{Frame: frame, Duration: time.Second}}}
+	}
+	s.AddAnimation("idle", hold("stand"))
+	s.AddAnimation("run", pixelbuf.Animation{Loop: true, Steps: []pixelbuf.Step{
+		{Frame: "stride", Duration: 120 * time.Millisecond},
+		{Frame: "step", Duration: 120 * time.Millisecond},
+	}})
+	s.AddAnimation("jump", hold("tuck"))
+	s.AddAnimation("fall", hold("dangle"))
+	s.AddAnimation("attack", pixelbuf.Animation{Steps: []pixelbuf.Step{
+		{Frame: "stand", Duration: 40 * time.Millisecond},
+		{Frame: "swing", Duration: time.Second},
+	}})
+	s.AddAnimation("hurt", hold("recoil"))
+	return s
+}
+
+func newHusk() *pixelbuf.Sprite {
+	s := pixelbuf.NewSprite()
+	s.AddFrame("walk", pixelbuf.MustParseArt(huskPalette, huskWalk...))
+	s.AddFrame("step", pixelbuf.MustParseArt(huskPalette, huskStep...))
+	s.AddFrame("windup", pixelbuf.MustParseArt(huskWindupPalette, huskWindup...))
+	s.AddFrame("windup-step", pixelbuf.MustParseArt(huskWindupPalette, huskWalk...))
+	s.AddFrame("lunge", pixelbuf.MustParseArt(huskPalette, huskLunge...))
+	s.AddFrame("slump", pixelbuf.MustParseArt(huskPalette, huskSlump...))
+
+	walk := pixelbuf.Animation{Loop: true, Steps: []pixelbuf.Step{
+		{Frame: "walk", Duration: 200 * time.Millisecond},
+		{Frame: "step", Duration: 200 * time.Millisecond},
+	}}
+	s.AddAnimation("patrol", walk)
+	s.AddAnimation("detect", pixelbuf.Animation{Steps: []pixelbuf.Step{{Frame: "walk", Duration: time.Second}}})
+	// The windup shivers before the raise, then holds it.
+	s.AddAnimation("windup", pixelbuf.Animation{Steps: []pixelbuf.Step{
+		{Frame: "windup-step", Duration: 60 * time.Millisecond},
+		{Frame: "walk", Duration: 60 * time.Millisecond},
+		{Frame: "windup", Duration: time.Second},
+	}})
+	s.AddAnimation("lunge", pixelbuf.Animation{Steps: []pixelbuf.Step{{Frame: "lunge", Duration: time.Second}}})
+	s.AddAnimation("recover", pixelbuf.Animation{Steps: []pixelbuf.Step{{Frame: "slump", Duration: time.Second}}})
+	return s
+}
+
+// seconds converts an engine time to a duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// knightFrame picks the knight's picture for the player's state.
+func knightFrame(e *engine.Engine) *pixelbuf.Buffer {
+	p := &e.Player
+	anim, t := "idle", seconds(float64(e.TickCount)*engine.DT)
+	switch p.State {
+	case engine.StateRun:
+		anim = "run"
+	case engine.StateJump:
+		anim = "jump"
+	case engine.StateFall:
+		anim = "fall"
+	case engine.StateAttack:
+		anim, t = "attack", seconds(e.Tuning.AttackDuration-p.AttackTimer)
+	case engine.StateHurt:
+		anim = "hurt"
+	}
+	return knight.At(anim, t, p.Facing == engine.DirLeft)
+}
+
+// huskFrame picks an enemy's picture for its state. Timed states are
+// animated from their start.
+func huskFrame(e *engine.Engine, enemy *engine.Enemy) *pixelbuf.Buffer {
+	t := seconds(float64(e.TickCount) * engine.DT)
+	anim := "patrol"
+	switch enemy.State {
+	case engine.EnemyDetect:
+		anim = "detect"
+	case engine.EnemyWindup:
+		anim, t = "windup", seconds(e.Tuning.EnemyWindupTime-enemy.StateTimer)
+	case engine.EnemyLunge:
+		anim = "lunge"
+	case engine.EnemyRecover:
+		anim = "recover"
+	}
+	return husk.At(anim, t, enemy.Facing == engine.DirLeft)
+}
+
+// silhouette returns img with every visible pixel set to c, for hit flashes.
+func silhouette(img *pixelbuf.Buffer, c pixelbuf.Color) *pixelbuf.Buffer {
+	out := pixelbuf.NewBuffer(img.Width, img.Height)
+	for y := 0; y < img.Height; y++ {
+		for x := 0; x < img.Width; x++ {
+			if img.At(x, y).A > 0 {
+				out.Set(x, y, c)
+			}
+		}
+	}
+	return out
+}
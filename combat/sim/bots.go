@@ -0,0 +1,433 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+
+	"text-adventure-v2/combat/engine"
+)
+
+// A Bot plays the player's side of a fight, choosing each tick's input from
+// the engine's state. Bots read the state directly, as a player reads the
+// screen.
+type Bot interface {
+	Input(e *engine.Engine) engine.InputState
+}
+
+// Bots are the built-in policies by name. Each fight gets a fresh bot with
+// its own random source, so a seed always plays the same fights.
+var Bots = map[string]func(rng *rand.Rand) Bot{
+	// rusher walks straight at the nearest enemy and swings as soon as it
+	// is in reach, jumping up to it when it stands higher.
+	"rusher": func(rng *rand.Rand) Bot { return &rusher{botBase: newBotBase(rng)} },
+	// spacer waits at the edge of the enemy's lunge range, and strikes
+	// when the enemy winds up to lunge or recovers from one.
+	"spacer": func(rng *rand.Rand) Bot { return &spacer{botBase: newBotBase(rng)} },
+	// random holds random directions for random stretches, and jumps and
+	// swings at random.
+	"random": func(rng *rand.Rand) Bot { return &random{rng: rng} },
+}
+
+// BotNames returns the names of the built-in bots in alphabetical order.
+func BotNames() []string {
+	var names []string
+	for name := range Bots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// reach is how far from the player's center an enemy's center may be and
+// still be hit: the far edge of the attack hitbox plus half an enemy.
+func reach(t *engine.Tuning) float64 {
+	return t.AttackOffsetX + t.AttackWidth/2 + engine.EnemyWidth/2 - 1
+}
+
+// botBase holds what the scripted bots share: a reaction time, so they
+// don't play frame-perfectly, the jump button's state, where they meant
+// to go when they last left the ground, and how long they have been stuck.
+type botBase struct {
+	rng      *rand.Rand
+	delay    int // ticks left before the bot starts to play
+	reaction int // ticks between seeing something and acting on it
+	waited   int
+	swingAt  float64 // how far off an enemy it swings at
+	jumping  bool
+	aimX     float64 // where to steer in the air
+	climbTo  float64 // if set, rise straight up until the feet are above this
+
+	lastX     float64
+	stuck     int     // ticks travelling without moving
+	wander    int     // ticks left wandering to get unstuck
+	wanderDir float64 // which way to wander
+}
+
+func newBotBase(rng *rand.Rand) botBase {
+	b := botBase{rng: rng, delay: rng.Intn(engine.TickRate), reaction: 2 + rng.Intn(5)} // about 70 to 200 ms
+	b.swingAt = b.rng.Float64()
+	return b
+}
+
+// ready counts down the delay before the bot starts, so that fights don't
+// all open the same way, and reports whether it is over.
+func (b *botBase) ready() bool {
+	if b.delay > 0 {
+		b.delay--
+		return false
+	}
+	return true
+}
+
+// react reports whether the bot has waited out its reaction time for
+// something it wants to do, and starts the wait again if so. The wait
+// varies a little each time.
+func (b *botBase) react() bool {
+	if b.waited < b.reaction {
+		b.waited++
+		return false
+	}
+	b.waited = b.rng.Intn(3) - 1
+	return true
+}
+
+// jump holds the jump button while want is set, pressing it only on the
+// first tick.
+func (b *botBase) jump(in *engine.InputState, want bool) {
+	in.JumpHeld = want
+	in.JumpPress = want && !b.jumping
+	b.jumping = want
+}
+
+// target returns the living enemy nearest the player, and the distance
+// from the player's center to its center.
+func target(e *engine.Engine) (enemy *engine.Enemy, dx float64) {
+	px, py := e.Player.Pos.Center()
+	best := math.Inf(1)
+	for _, en := range e.Enemies {
+		if !en.Alive {
+			continue
+		}
+		ex, ey := en.Pos.Center()
+		if d := math.Hypot(ex-px, ey-py); d < best {
+			best, enemy, dx = d, en, ex-px
+		}
+	}
+	return enemy, dx
+}
+
+// toward sets the input to walk in the direction of dx.
+func toward(in *engine.InputState, dx float64) {
+	in.Left, in.Right = dx < 0, dx > 0
+}
+
+// level reports whether the player can fight enemy where they stand: on the
+// same platform, or at about the same height while the enemy is knocked
+// into the air or stands in a hazard. A player in the air sees their jump
+// through first.
+func level(e *engine.Engine, enemy *engine.Enemy) bool {
+	pi, ei := e.Arena.PlatformUnder(e.Player.Pos), e.Arena.PlatformUnder(enemy.Pos)
+	switch {
+	case pi < 0:
+		return false
+	case ei >= 0 && !hazardous(e.Arena, ei):
+		return pi == ei
+	}
+	return math.Abs(e.Player.Pos.Y+e.Player.Pos.H-enemy.Pos.Y-enemy.Pos.H) < engine.EnemyHeight/2
+}
+
+// strike walks toward an enemy dx away on the player's level, and swings
+// once it is in reach and faced, a different distance in each time. It stops at the edge of the platform rather
+// than follow the enemy off it.
+func (b *botBase) strike(e *engine.Engine, in *engine.InputState, dx float64) {
+	facing := (dx >= 0) == (e.Player.Facing == engine.DirRight)
+	px, _ := e.Player.Pos.Center()
+	cur := e.Arena.Platforms[e.Arena.PlatformUnder(e.Player.Pos)]
+	edge := dx < 0 && px-1 < cur.X || dx > 0 && px+1 > cur.X+cur.W
+	switch {
+	case math.Abs(dx) <= reach(&e.Tuning)*(0.6+0.4*b.swingAt) && facing:
+		if in.Attack = b.react(); in.Attack {
+			b.swingAt = b.rng.Float64()
+		}
+	case !edge:
+		toward(in, dx)
+	}
+	b.jump(in, false)
+}
+
+// travel heads for the platform enemy stands on. If the enemy is in the air,
+// or there is no way up to it, it waits below.
+func (b *botBase) travel(e *engine.Engine, in *engine.InputState, enemy *engine.Enemy) {
+	a := e.Arena
+	from := a.PlatformUnder(e.Player.Pos)
+	if from < 0 {
+		b.fly(e, in)
+		return
+	}
+	var route []int
+	if to := a.PlatformUnder(enemy.Pos); to >= 0 {
+		route = a.Route(from, to, &e.Tuning)
+	}
+	if len(route) == 0 {
+		_, dx := target(e)
+		toward(in, dx)
+		b.jump(in, false)
+		return
+	}
+	b.follow(e, in, route)
+}
+
+// escape gets the player off a platform covered by a hazard, such as the
+// floor of a spike pit, heading for the nearest platform that isn't. It
+// reports whether the player needed to.
+func (b *botBase) escape(e *engine.Engine, in *engine.InputState) bool {
+	a := e.Arena
+	from := a.PlatformUnder(e.Player.Pos)
+	if from < 0 || !hazardous(a, from) {
+		return false
+	}
+	var best []int
+	for i := range a.Platforms {
+		if hazardous(a, i) {
+			continue
+		}
+		if route := a.Route(from, i, &e.Tuning); len(route) > 0 && (best == nil || len(route) < len(best)) {
+			best = route
+		}
+	}
+	if best == nil {
+		return false // nowhere to go; fight on
+	}
+	b.follow(e, in, best)
+	return true
+}
+
+// hazardous reports whether a hazard lies on top of platform i.
+func hazardous(a engine.Arena, i int) bool {
+	p := a.Platforms[i]
+	top := engine.Rect{X: p.X, Y: p.Y - 1, W: p.W, H: 1}
+	for _, h := range a.Hazards {
+		if top.Overlaps(h.Rect) {
+			return true
+		}
+	}
+	return false
+}
+
+// follow takes the player along route, a list of platforms to jump between.
+// Each tick on the ground it tries jumps in a copy of the fight, toward the
+// next platform and any other the route goes on from as quickly, and takes
+// one that lands somewhere on the way; otherwise it walks toward the next
+// platform, or off the edge above it.
+func (b *botBase) follow(e *engine.Engine, in *engine.InputState, route []int) {
+	a := e.Arena
+	from := a.PlatformUnder(e.Player.Pos)
+	goal := route[len(route)-1]
+	px, _ := e.Player.Pos.Center()
+	steps := []int{route[0]}
+	for i := range a.Platforms {
+		if i != from && i != route[0] && i != goal {
+			if on := a.Route(i, goal, &e.Tuning); on != nil && len(on) <= len(route) {
+				steps = append(steps, i)
+			}
+		}
+	}
+	for _, i := range steps {
+		b.aimX = aim(a.Platforms[i], px, e.Player.Pos.W)
+		for _, climbTo := range []float64{0, a.Platforms[i].Y} {
+			b.climbTo = climbTo
+			if b.tryJump(e, route) {
+				b.fly(e, in)
+				b.jump(in, true)
+				return
+			}
+		}
+	}
+
+	cur, next := a.Platforms[from], a.Platforms[route[0]]
+	nx := next.X + next.W/2
+	b.aimX = aim(next, px, e.Player.Pos.W)
+	b.jump(in, false)
+	if b.wander > 0 {
+		b.wander--
+		b.aimX = b.wanderDir * math.Inf(1)
+		toward(in, b.wanderDir)
+		return
+	}
+	under := e.Player.Pos.X < next.X+next.W && e.Player.Pos.X+e.Player.Pos.W > next.X
+	switch {
+	case next.Y >= cur.Y && b.aimX >= cur.X && b.aimX <= cur.X+cur.W:
+		// Below this platform: walk off its nearer edge.
+		if px-cur.X < cur.X+cur.W-px {
+			b.aimX = math.Inf(-1)
+		} else {
+			b.aimX = math.Inf(1)
+		}
+	case next.Y < cur.Y && under:
+		b.aimX = math.Copysign(math.Inf(1), px-nx) // out from under it, to jump up
+	}
+	toward(in, b.aimX-px)
+	b.unstick(e)
+}
+
+// unstick notices when the player has stopped getting anywhere, such as
+// when walled in under a platform or when an enemy guards the only way up,
+// and then has follow wander the other way for a while, still looking for
+// a jump to take.
+func (b *botBase) unstick(e *engine.Engine) {
+	if math.Abs(e.Player.Pos.X-b.lastX) < 0.5 {
+		b.stuck++
+	} else {
+		b.stuck = 0
+	}
+	b.lastX = e.Player.Pos.X
+	if b.stuck < engine.TickRate/2 {
+		return
+	}
+	b.stuck = 0
+	b.wander = engine.TickRate/2 + b.rng.Intn(engine.TickRate)
+	px, _ := e.Player.Pos.Center()
+	b.wanderDir = -math.Copysign(1, b.aimX-px)
+	if b.aimX == px {
+		b.wanderDir = float64(2*b.rng.Intn(2) - 1)
+	}
+}
+
+// aim returns where on platform p a player w wide, now at x, should land:
+// the near end, with room to stand.
+func aim(p engine.Rect, x, w float64) float64 {
+	lo, hi := p.X+w, p.X+p.W-w
+	if lo > hi {
+		return p.X + p.W/2
+	}
+	return min(max(x, lo), hi)
+}
+
+// closer reports whether the player in c, having landed, is closer along
+// route than in e.
+func (b *botBase) closer(e, c *engine.Engine, route []int) bool {
+	a := e.Arena
+	landed := a.PlatformUnder(c.Player.Pos)
+	if landed < 0 || landed == a.PlatformUnder(e.Player.Pos) {
+		return false
+	}
+	if slices.Contains(route, landed) {
+		return true
+	}
+	goal := route[len(route)-1]
+	on := a.Route(landed, goal, &e.Tuning)
+	if on == nil || len(on) > len(route) {
+		return false
+	}
+	gx := a.Platforms[goal].X + a.Platforms[goal].W/2
+	cx, _ := c.Player.Pos.Center()
+	px, _ := e.Player.Pos.Center()
+	return math.Abs(gx-cx) < math.Abs(gx-px)
+}
+
+// fly steers in the air as tryJump did: toward aimX, once above climbTo if
+// it is set, holding jump while rising.
+func (b *botBase) fly(e *engine.Engine, in *engine.InputState) {
+	p := e.Player.Pos
+	if b.climbTo == 0 || p.Y+p.H <= b.climbTo || e.Player.VelY > 0 {
+		px, _ := p.Center()
+		if dx := b.aimX - px; math.Abs(dx) > e.Tuning.RunSpeed*engine.DT {
+			toward(in, dx)
+		}
+	}
+	b.jump(in, b.jumping && e.Player.VelY < 0)
+}
+
+// tryJump reports whether jumping now toward b.aimX gets the player
+// closer to the end of route, trying it out in a copy of the fight. A
+// landing off the route counts if the way on from there is no longer, and
+// it is nearer the goal.
+func (b *botBase) tryJump(e *engine.Engine, route []int) bool {
+	c := e.Clone()
+	ghost := *b
+	var in engine.InputState
+	ghost.fly(c, &in)
+	ghost.jump(&in, true)
+	c.Tick(in)
+	for range 2 * engine.TickRate {
+		if c.Result != engine.ResultNone || c.Player.State == engine.StateHurt {
+			return false
+		}
+		if c.Player.Grounded {
+			return b.closer(e, c, route)
+		}
+		in = engine.InputState{}
+		ghost.fly(c, &in)
+		c.Tick(in)
+	}
+	return false
+}
+
+type rusher struct{ botBase }
+
+func (b *rusher) Input(e *engine.Engine) engine.InputState {
+	var in engine.InputState
+	enemy, dx := target(e)
+	switch {
+	case enemy == nil, !b.ready(), b.escape(e, &in):
+	case level(e, enemy):
+		b.strike(e, &in, dx)
+	default:
+		b.travel(e, &in, enemy)
+	}
+	return in
+}
+
+type spacer struct{ botBase }
+
+func (b *spacer) Input(e *engine.Engine) engine.InputState {
+	var in engine.InputState
+	enemy, dx := target(e)
+	if enemy == nil || !b.ready() || b.escape(e, &in) {
+		return in
+	}
+	if !level(e, enemy) {
+		b.travel(e, &in, enemy)
+		return in
+	}
+	t := &e.Tuning
+	switch enemy.State {
+	case engine.EnemyWindup, engine.EnemyRecover:
+		b.strike(e, &in, dx) // a hit cuts the windup short
+		return in
+	case engine.EnemyLunge:
+		toward(&in, -dx)
+	default:
+		// Hang about at the edge of the enemy's lunge range to draw it
+		// out, but don't back into a corner.
+		gap := math.Abs(dx) - (t.EnemyLungeRange + engine.EnemyWidth)
+		p := e.Player.Pos
+		cornered := p.X < 2*p.W && dx > 0 || p.X+p.W > e.Arena.Width-2*p.W && dx < 0
+		if gap > 4 || gap < -4 && !cornered {
+			toward(&in, dx*gap)
+		}
+	}
+	b.jump(&in, false)
+	return in
+}
+
+type random struct {
+	rng  *rand.Rand
+	dir  int // -1, 0 or 1
+	hold int // ticks left holding dir
+	jump bool
+}
+
+func (b *random) Input(e *engine.Engine) engine.InputState {
+	if b.hold <= 0 {
+		b.dir, b.hold = b.rng.Intn(3)-1, 5+b.rng.Intn(25)
+	}
+	b.hold--
+	in := engine.InputState{Left: b.dir < 0, Right: b.dir > 0, Attack: b.rng.Intn(8) == 0}
+	wasJumping := b.jump
+	b.jump = b.jump && b.rng.Intn(10) != 0 || b.rng.Intn(40) == 0
+	in.JumpHeld, in.JumpPress = b.jump, b.jump && !wasJumping
+	return in
+}
@@ -0,0 +1,141 @@
+// Package sim runs combat/engine fights without a terminal, with a bot in
+// the player's place, and sums up how they went. It is used by
+// cmd/combat-sim to balance fights from data.
+package sim
+
+import (
+	"math/rand"
+	"runtime"
+	"slices"
+	"sync"
+
+	"text-adventure-v2/combat/engine"
+)
+
+// Config sets up every fight in a batch.
+type Config struct {
+	Arena    engine.Arena
+	Enemies  int
+	Tuning   engine.Tuning
+	EnemyHP  int // 0 keeps engine.EnemyHP
+	Damage   int // the player's damage per hit; 0 keeps engine.AttackDamage
+	MaxTicks int // a fight still going after this many ticks is a timeout
+}
+
+// Fight is how one fight went.
+type Fight struct {
+	Result      engine.Result // ResultNone if the fight timed out
+	Ticks       int
+	DamageTaken int
+	Swings      int // attacks started
+	Hits        int // attacks that landed on at least one enemy
+}
+
+// Run plays one fight with bot as the player.
+func Run(c Config, bot Bot) Fight {
+	e := engine.NewEngineIn(c.Arena, c.Enemies, c.Tuning)
+	if c.Damage > 0 {
+		e.Player.Damage = c.Damage
+	}
+	if c.EnemyHP > 0 {
+		for _, enemy := range e.Enemies {
+			enemy.HP, enemy.MaxHP = c.EnemyHP, c.EnemyHP
+		}
+	}
+
+	var f Fight
+	for e.Result == engine.ResultNone && e.TickCount < c.MaxTicks {
+		prev := e.Player
+		e.Tick(bot.Input(e))
+		p := &e.Player
+		if p.State == engine.StateAttack && prev.State != engine.StateAttack {
+			f.Swings++
+		}
+		if p.AttackHit && !prev.AttackHit {
+			f.Hits++
+		}
+	}
+	f.Result = e.Result
+	f.Ticks = e.TickCount
+	f.DamageTaken = e.Player.MaxHP - e.Player.HP
+	return f
+}
+
+// Batch plays n fights, each with a fresh bot from newBot, spread over the
+// CPUs. Fight i's bot gets a random source seeded with seed+i, so a batch
+// can be repeated.
+func Batch(c Config, newBot func(*rand.Rand) Bot, n int, seed int64) []Fight {
+	fights := make([]Fight, n)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range runtime.GOMAXPROCS(0) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fights[i] = Run(c, newBot(rand.New(rand.NewSource(seed+int64(i)))))
+			}
+		}()
+	}
+	for i := range fights {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return fights
+}
+
+// Summary sums up a batch of fights. Times are in seconds.
+type Summary struct {
+	Fights, Wins, Losses, Timeouts int
+
+	TTK       float64 // mean time to kill every enemy, over the fights won
+	TTKMedian float64
+	Damage    float64 // mean damage taken per fight
+	Accuracy  float64 // the fraction of swings that landed
+}
+
+// WinRate returns the fraction of fights won.
+func (s Summary) WinRate() float64 {
+	return ratio(s.Wins, s.Fights)
+}
+
+// Summarize sums up fights.
+func Summarize(fights []Fight) Summary {
+	s := Summary{Fights: len(fights)}
+	var ttks []float64
+	damage, swings, hits := 0, 0, 0
+	for _, f := range fights {
+		switch f.Result {
+		case engine.ResultPlayerWin:
+			s.Wins++
+			ttks = append(ttks, float64(f.Ticks)*engine.DT)
+		case engine.ResultPlayerDead:
+			s.Losses++
+		default:
+			s.Timeouts++
+		}
+		damage += f.DamageTaken
+		swings += f.Swings
+		hits += f.Hits
+	}
+	if len(ttks) > 0 {
+		total := 0.0
+		for _, t := range ttks {
+			total += t
+		}
+		s.TTK = total / float64(len(ttks))
+		slices.Sort(ttks)
+		s.TTKMedian = ttks[len(ttks)/2]
+	}
+	s.Damage = ratio(damage, s.Fights)
+	s.Accuracy = ratio(hits, swings)
+	return s
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
@@ -0,0 +1,110 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"text-adventure-v2/combat/engine"
+)
+
+func hallConfig(t *testing.T, enemies int) Config {
+	t.Helper()
+	arena, err := engine.LoadArena("hall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Config{Arena: arena, Enemies: enemies, Tuning: engine.DefaultTuning(), MaxTicks: 60 * engine.TickRate}
+}
+
+// script plays a fixed list of inputs, then stands still.
+type script []engine.InputState
+
+func (s *script) Input(*engine.Engine) engine.InputState {
+	if len(*s) == 0 {
+		return engine.InputState{}
+	}
+	in := (*s)[0]
+	*s = (*s)[1:]
+	return in
+}
+
+func TestRun_CountsSwingsAndHits(t *testing.T) {
+	c := hallConfig(t, 1)
+	c.MaxTicks = 60
+	var s script
+	for range 30 {
+		s = append(s, engine.InputState{Attack: true}) // the enemy is far off
+	}
+	f := Run(c, &s)
+	if f.Result != engine.ResultNone || f.Ticks != 60 {
+		t.Errorf("Result, Ticks = %v, %d, want a timeout after 60", f.Result, f.Ticks)
+	}
+	if f.Swings < 2 || f.Hits != 0 {
+		t.Errorf("Swings, Hits = %d, %d, want several misses", f.Swings, f.Hits)
+	}
+}
+
+func TestRun_AppliesEnemyHPAndDamage(t *testing.T) {
+	c := hallConfig(t, 1)
+	c.EnemyHP, c.Damage = 4, 4
+	f := Run(c, Bots["rusher"](rand.New(rand.NewSource(1))))
+	if f.Result != engine.ResultPlayerWin || f.Hits != 1 {
+		t.Errorf("Result, Hits = %v, %d, want a win in one hit", f.Result, f.Hits)
+	}
+}
+
+func TestBatch_Repeatable(t *testing.T) {
+	c := hallConfig(t, 2)
+	for _, name := range BotNames() {
+		a := Batch(c, Bots[name], 20, 7)
+		b := Batch(c, Bots[name], 20, 7)
+		if !slices.Equal(a, b) {
+			t.Errorf("%s: two batches from the same seed differ:\n%v\n%v", name, a, b)
+		}
+	}
+}
+
+func TestBots_BeatTheHall(t *testing.T) {
+	c := hallConfig(t, 1)
+	for _, name := range []string{"rusher", "spacer"} {
+		s := Summarize(Batch(c, Bots[name], 50, 1))
+		if s.WinRate() < 0.9 {
+			t.Errorf("%s: WinRate() = %v, want at least 0.9 (%+v)", name, s.WinRate(), s)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	win := func(ticks int) Fight {
+		return Fight{Result: engine.ResultPlayerWin, Ticks: ticks, DamageTaken: 1, Swings: 4, Hits: 3}
+	}
+	fights := []Fight{
+		win(30), win(90), win(60),
+		{Result: engine.ResultPlayerDead, Ticks: 45, DamageTaken: 5, Swings: 4},
+		{Ticks: 1800, DamageTaken: 2},
+	}
+	s := Summarize(fights)
+	if s.Fights != 5 || s.Wins != 3 || s.Losses != 1 || s.Timeouts != 1 {
+		t.Errorf("Fights, Wins, Losses, Timeouts = %d, %d, %d, %d, want 5, 3, 1, 1", s.Fights, s.Wins, s.Losses, s.Timeouts)
+	}
+	for _, tt := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"WinRate()", s.WinRate(), 0.6},
+		{"TTK", s.TTK, 2},
+		{"TTKMedian", s.TTKMedian, 2},
+		{"Damage", s.Damage, 2},
+		{"Accuracy", s.Accuracy, 0.5625},
+	} {
+		if math.Abs(tt.got-tt.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if s := Summarize(nil); s != (Summary{}) {
+		t.Errorf("Summarize(nil) = %+v, want zeros", s)
+	}
+}
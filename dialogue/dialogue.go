@@ -0,0 +1,255 @@
+// Package dialogue models branching conversations: trees of nodes where the
+// player picks from numbered choices. Choices can be hidden behind conditions
+// on the game state and can change it through effects. The package only
+// describes conversations; the game evaluates conditions and applies effects.
+package dialogue
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text-adventure-v2/world"
+)
+
+// LinePlaceholder in a node's text is replaced by the speaker's next plain
+// line, so a tree can pass on the hints the generator wrote for a character.
+const LinePlaceholder = "{line}"
+
+// Tree is one conversation.
+type Tree struct {
+	ID    string           `json:"id"`
+	Start string           `json:"start"` // ID of the first node
+	Nodes map[string]*Node `json:"nodes"`
+}
+
+// Node is something the character says, followed by the player's choices. A
+// node with no choices, or none whose conditions hold, ends the conversation.
+type Node struct {
+	Text    string   `json:"text"`
+	Choices []Choice `json:"choices"`
+}
+
+// Choice is a reply the player can pick.
+type Choice struct {
+	Text    string      `json:"text"`
+	Next    string      `json:"next,omitempty"` // node to go to; empty ends the conversation
+	If      []Condition `json:"if,omitempty"`   // all must hold for the choice to be offered
+	Effects []Effect    `json:"effects,omitempty"`
+}
+
+// Condition is a test on the game state. Exactly one field is set.
+type Condition struct {
+	HasItem  string `json:"has_item,omitempty"`  // the player carries an item with this name
+	Visited  string `json:"visited,omitempty"`   // the player has been to this room
+	MinTurns int    `json:"min_turns,omitempty"` // at least this many turns have passed
+	Flag     string `json:"flag,omitempty"`      // this flag is set
+	NotFlag  string `json:"not_flag,omitempty"`  // this flag is not set
+}
+
+// Effect changes the game state when a choice is picked. Exactly one field is set.
+type Effect struct {
+	GiveItem *Item    `json:"give_item,omitempty"`
+	Unlock   *ExitRef `json:"unlock,omitempty"`
+	SetFlag  string   `json:"set_flag,omitempty"`
+}
+
+// Item is an item handed to the player.
+type Item struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Behaviors   []Behavior `json:"behaviors,omitempty"`
+}
+
+// Behavior mirrors world.Behavior for items given in dialogue.
+type Behavior struct {
+	Kind  string `json:"kind"`
+	KeyID string `json:"key_id,omitempty"`
+	Text  string `json:"text,omitempty"`
+	HP    int    `json:"hp,omitempty"`
+}
+
+// ExitRef names a locked exit. An empty Room means the room the conversation
+// happens in; an empty Direction means every locked exit in that room.
+type ExitRef struct {
+	Room      string `json:"room,omitempty"`
+	Direction string `json:"direction,omitempty"`
+}
+
+// behaviorKinds are the item behaviors a given item may carry.
+var behaviorKinds = map[string]bool{
+	string(world.BehaviorKey): true, string(world.BehaviorLight): true, string(world.BehaviorConsumable): true,
+	string(world.BehaviorReadable): true, string(world.BehaviorTool): true,
+}
+
+var conditionKinds = []string{"has_item", "visited", "min_turns", "flag", "not_flag"}
+
+// set lists which fields of the condition are filled in.
+func (c Condition) set() []string {
+	var kinds []string
+	for i, ok := range []bool{c.HasItem != "", c.Visited != "", c.MinTurns > 0, c.Flag != "", c.NotFlag != ""} {
+		if ok {
+			kinds = append(kinds, conditionKinds[i])
+		}
+	}
+	return kinds
+}
+
+func (e Effect) set() []string {
+	var kinds []string
+	if e.GiveItem != nil {
+		kinds = append(kinds, "give_item")
+	}
+	if e.Unlock != nil {
+		kinds = append(kinds, "unlock")
+	}
+	if e.SetFlag != "" {
+		kinds = append(kinds, "set_flag")
+	}
+	return kinds
+}
+
+// Parse decodes a dialogue tree from JSON and validates it.
+func Parse(data []byte) (*Tree, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var t Tree
+	if err := dec.Decode(&t); err != nil {
+		return nil, fmt.Errorf("dialogue is not valid: %w", err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Validate reports every problem with the tree: a missing or unknown start
+// node, choices leading to nodes that don't exist, nodes no path reaches, and
+// conditions or effects that are empty or set more than one thing.
+func (t *Tree) Validate() error {
+	name := fmt.Sprintf("dialogue %q", t.ID)
+	var problems []error
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(name+": "+format, args...))
+	}
+
+	if t.ID == "" {
+		add("has no id")
+	}
+	if len(t.Nodes) == 0 {
+		add("has no nodes")
+	}
+	if _, ok := t.Nodes[t.Start]; !ok {
+		add("start node %q does not exist", t.Start)
+	}
+
+	for _, id := range t.nodeIDs() {
+		node := t.Nodes[id]
+		if node == nil || node.Text == "" {
+			add("node %q has no text", id)
+			continue
+		}
+		for i, c := range node.Choices {
+			where := fmt.Sprintf("node %q choice %d", id, i+1)
+			if c.Text == "" {
+				add("%s has no text", where)
+			}
+			if _, ok := t.Nodes[c.Next]; c.Next != "" && !ok {
+				add("%s leads to unknown node %q", where, c.Next)
+			}
+			for _, cond := range c.If {
+				if n := len(cond.set()); n != 1 {
+					add("%s has a condition with %d tests; use exactly one of %s", where, n, strings.Join(conditionKinds, ", "))
+				}
+			}
+			for _, e := range c.Effects {
+				if n := len(e.set()); n != 1 {
+					add("%s has an effect with %d actions; use exactly one of give_item, unlock, set_flag", where, n)
+				}
+				if e.GiveItem != nil && e.GiveItem.Name == "" {
+					add("%s gives an item with no name", where)
+				}
+				if e.GiveItem != nil {
+					for _, b := range e.GiveItem.Behaviors {
+						if !behaviorKinds[b.Kind] {
+							add("%s gives an item with unknown behavior %q", where, b.Kind)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	reached := t.reachable()
+	for _, id := range t.nodeIDs() {
+		if !reached[id] {
+			add("node %q can never be reached", id)
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// reachable returns the nodes some sequence of choices leads to from the
+// start, ignoring conditions.
+func (t *Tree) reachable() map[string]bool {
+	reached := make(map[string]bool)
+	if _, ok := t.Nodes[t.Start]; !ok {
+		return reached
+	}
+	reached[t.Start] = true
+	queue := []string{t.Start}
+	for len(queue) > 0 {
+		node := t.Nodes[queue[0]]
+		queue = queue[1:]
+		if node == nil {
+			continue
+		}
+		for _, c := range node.Choices {
+			if _, ok := t.Nodes[c.Next]; ok && !reached[c.Next] {
+				reached[c.Next] = true
+				queue = append(queue, c.Next)
+			}
+		}
+	}
+	return reached
+}
+
+func (t *Tree) nodeIDs() []string {
+	ids := make([]string, 0, len(t.Nodes))
+	for id := range t.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+//go:embed data/*.json
+var dataFiles embed.FS
+
+// Builtin loads the dialogue trees embedded in the binary, keyed by ID.
+func Builtin() (map[string]*Tree, error) {
+	entries, err := dataFiles.ReadDir("data")
+	if err != nil {
+		return nil, err
+	}
+	trees := make(map[string]*Tree)
+	for _, e := range entries {
+		data, err := dataFiles.ReadFile(path.Join("data", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		t, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		if _, dup := trees[t.ID]; dup {
+			return nil, fmt.Errorf("%s: dialogue id %q is used twice", e.Name(), t.ID)
+		}
+		trees[t.ID] = t
+	}
+	return trees, nil
+}
@@ -0,0 +1,106 @@
+package dialogue
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuiltin_Loads(t *testing.T) {
+	trees, err := Builtin()
+	if err != nil {
+		t.Fatalf("Builtin failed: %v", err)
+	}
+	if len(trees) == 0 {
+		t.Error("Expected at least one built-in dialogue")
+	}
+	for id, tree := range trees {
+		if tree.ID != id {
+			t.Errorf("Expected tree keyed %q to have that ID, got %q", id, tree.ID)
+		}
+	}
+}
+
+// TestBuiltin_NeverUnlocks ensures no built-in tree opens doors. The generator
+// proves a world solvable without talking to anyone, so a character who
+// unlocks a door would let the player skip the puzzle it guards.
+func TestBuiltin_NeverUnlocks(t *testing.T) {
+	trees, err := Builtin()
+	if err != nil {
+		t.Fatalf("Builtin failed: %v", err)
+	}
+	for id, tree := range trees {
+		for nodeID, node := range tree.Nodes {
+			for _, choice := range node.Choices {
+				for _, effect := range choice.Effects {
+					if effect.Unlock != nil {
+						t.Errorf("%s: node %q has a choice that unlocks a door", id, nodeID)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestParse_Accepts(t *testing.T) {
+	data := `{"id":"t","start":"a","nodes":{
+		"a":{"text":"Hi.","choices":[{"text":"More?","next":"b","if":[{"min_turns":3}]},{"text":"Bye."}]},
+		"b":{"text":"That's all.","choices":[{"text":"Thanks.","effects":[{"set_flag":"asked"}]}]}}}`
+	tree, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Expected a valid tree, got %v", err)
+	}
+	if len(tree.Nodes["a"].Choices) != 2 || tree.Nodes["a"].Choices[0].If[0].MinTurns != 3 {
+		t.Errorf("Expected choices and conditions to decode, got %+v", tree.Nodes["a"])
+	}
+}
+
+func TestParse_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"not json", `{`, "not valid"},
+		{"unknown field", `{"id":"t","start":"a","nodes":{"a":{"text":"Hi.","colour":"red"}}}`, "not valid"},
+		{"no id", `{"start":"a","nodes":{"a":{"text":"Hi."}}}`, "has no id"},
+		{"missing start", `{"id":"t","start":"x","nodes":{"a":{"text":"Hi."}}}`, `start node "x" does not exist`},
+		{"empty text", `{"id":"t","start":"a","nodes":{"a":{"text":""}}}`, `node "a" has no text`},
+		{"dangling next", `{"id":"t","start":"a","nodes":{"a":{"text":"Hi.","choices":[{"text":"Go.","next":"b"}]}}}`,
+			`leads to unknown node "b"`},
+		{"unreachable node", `{"id":"t","start":"a","nodes":{"a":{"text":"Hi."},"b":{"text":"Lost."}}}`,
+			`node "b" can never be reached`},
+		{"two tests in one condition", `{"id":"t","start":"a","nodes":{"a":{"text":"Hi.",
+			"choices":[{"text":"Go.","if":[{"flag":"x","visited":"Hall"}]}]}}}`, "condition with 2 tests"},
+		{"empty effect", `{"id":"t","start":"a","nodes":{"a":{"text":"Hi.","choices":[{"text":"Go.","effects":[{}]}]}}}`,
+			"effect with 0 actions"},
+		{"nameless gift", `{"id":"t","start":"a","nodes":{"a":{"text":"Hi.",
+			"choices":[{"text":"Go.","effects":[{"give_item":{"description":"?"}}]}]}}}`, "item with no name"},
+		{"unknown behavior", `{"id":"t","start":"a","nodes":{"a":{"text":"Hi.",
+			"choices":[{"text":"Go.","effects":[{"give_item":{"name":"rock","behaviors":[{"kind":"explode"}]}}]}]}}}`,
+			`unknown behavior "explode"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	tree := &Tree{ID: "t", Start: "a", Nodes: map[string]*Node{
+		"a": {Text: "Hi.", Choices: []Choice{{Text: "Go.", Next: "nowhere"}}},
+		"b": {Text: "Lost."},
+	}}
+	err := tree.Validate()
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	for _, want := range []string{`unknown node "nowhere"`, `node "b" can never be reached`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Handler runs a command. s.Verb is the word the player typed (so aliases and
+// keys can behave differently). It returns the message to show, whether the
+// command succeeded, and whether the game should exit.
+type Handler func(g *Game, s Sentence) (msg string, success, shouldExit bool)
+
+// Command describes a verb the player can issue.
+type Command struct {
+	Name     string   // canonical verb, e.g. "take"
+	Aliases  []string // other verbs that dispatch here
+	Keys     []string // instant keys the TUI runs without Enter; each also works as a typed alias
+	Usage    string   // typed form shown in help, e.g. "take [item]"; defaults to Name
+	Summary  string   // short label for the instant-key legend, e.g. "move"
+	UsesTurn bool     // whether a successful run consumes a turn
+	Handler  Handler
+}
+
+// Registry maps verbs and aliases to commands, preserving registration order
+// so that help output is stable.
+type Registry struct {
+	commands []*Command
+	byVerb   map[string]*Command
+}
+
+// NewRegistry creates an empty command registry.
+func NewRegistry() *Registry {
+	return &Registry{byVerb: make(map[string]*Command)}
+}
+
+// Commands is the registry HandleCommand dispatches through. New verbs are
+// added by registering them here.
+var Commands = NewRegistry()
+
+func init() {
+	registerBuiltins(Commands)
+}
+
+// Register adds a command. It panics if the name or an alias is already
+// taken, since that is a programming error caught at startup.
+func (r *Registry) Register(c *Command) {
+	verbs := append([]string{c.Name}, c.Aliases...)
+	for _, verb := range append(verbs, c.Keys...) {
+		if _, taken := r.byVerb[verb]; taken {
+			panic(fmt.Sprintf("command registry: verb %q registered twice", verb))
+		}
+		r.byVerb[verb] = c
+	}
+	r.commands = append(r.commands, c)
+}
+
+// Lookup finds the command for a verb or alias.
+func (r *Registry) Lookup(verb string) (*Command, bool) {
+	c, ok := r.byVerb[verb]
+	return c, ok
+}
+
+// All returns the registered commands in registration order.
+func (r *Registry) All() []*Command {
+	return r.commands
+}
+
+// IsInstantKey reports whether key is bound to a command as an instant key.
+// The TUI runs these on a keypress when the input line is empty.
+func (r *Registry) IsInstantKey(key string) bool {
+	c, ok := r.byVerb[key]
+	if !ok {
+		return false
+	}
+	for _, k := range c.Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyLegend returns a one-line summary of the instant keys, e.g.
+// "w,a,s,d: move | e: take | q: quit".
+func (r *Registry) KeyLegend() string {
+	var parts []string
+	for _, c := range r.commands {
+		if len(c.Keys) > 0 {
+			parts = append(parts, strings.Join(c.Keys, ",")+": "+c.label())
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// HelpText returns the full help message listing instant and typed commands.
+func (r *Registry) HelpText() string {
+	var instant, typed []string
+	for _, c := range r.commands {
+		if len(c.Keys) > 0 {
+			instant = append(instant, fmt.Sprintf("%s (%s)", strings.Join(c.Keys, ","), c.label()))
+		}
+		usage := c.Usage
+		if usage == "" {
+			usage = c.Name
+		}
+		typed = append(typed, usage)
+	}
+	return "Instant Commands: " + strings.Join(instant, ", ") +
+		"\nTyped Commands: " + strings.Join(typed, ", ")
+}
+
+func (c *Command) label() string {
+	if c.Summary != "" {
+		return c.Summary
+	}
+	return c.Name
+}
+
+// wasdDirections maps the movement keys to compass directions.
+var wasdDirections = map[string]string{"w": "north", "a": "west", "s": "south", "d": "east"}
+
+// registerBuiltins registers the core verbs. Order here is the order shown in help.
+func registerBuiltins(r *Registry) {
+	r.Register(&Command{
+		Name:     "go",
+		Aliases:  []string{"walk", "move"},
+		Keys:     []string{"w", "a", "s", "d"},
+		Usage:    "go [dir]",
+		Summary:  "move",
+		UsesTurn: true,
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			dir, ok := wasdDirections[s.Verb]
+			if !ok {
+				dir = normalizeDirection(s.Target())
+			}
+			msg, success := g.Move(dir)
+			return msg, success, false
+		},
+	})
+	r.Register(&Command{
+		Name:     "take",
+		Aliases:  []string{"get", "grab"},
+		Keys:     []string{"e"},
+		Usage:    "take [item]",
+		UsesTurn: true,
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			noun := s.Object
+			if s.Verb == "e" && g.canSee() {
+				if len(g.Player.Location.Items) == 0 {
+					return "There is nothing to take.", false, false
+				}
+				noun = g.Player.Location.Items[0].Name
+			}
+			msg, success := g.Take(noun)
+			return msg, success, false
+		},
+	})
+	r.Register(&Command{
+		Name:     "drop",
+		Aliases:  []string{"discard"},
+		Usage:    "drop [item]",
+		UsesTurn: true,
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			msg, success := g.Drop(s.Object)
+			return msg, success, false
+		},
+	})
+	r.Register(&Command{
+		Name:     "unlock",
+		Keys:     []string{"u"},
+		Usage:    "unlock [dir] (with [key])",
+		UsesTurn: true,
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			if s.Preposition == "with" || s.Preposition == "using" {
+				i, msg := resolveItem(s.Indirect, g.Player.Inventory, "You don't have that.")
+				if i < 0 {
+					return msg, false, false
+				}
+				return g.UnlockWith(g.Player.Inventory[i], directionIn(s.Object))
+			}
+			return g.UnlockWith(nil, directionIn(s.Object))
+		},
+	})
+	r.Register(&Command{
+		Name:     "use",
+		Usage:    "use [item] (on [target])",
+		UsesTurn: true,
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			return g.Use(s.Object, s.Indirect)
+		},
+	})
+	r.Register(&Command{
+		Name:  "read",
+		Usage: "read [item]",
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			msg, success := g.Read(s.Object)
+			return msg, success, false
+		},
+	})
+	r.Register(&Command{
+		Name:     "talk",
+		Aliases:  []string{"speak"},
+		Usage:    "talk (to) [someone]",
+		UsesTurn: true,
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			msg, success := g.Talk(s.Target())
+			return msg, success, false
+		},
+	})
+	r.Register(&Command{
+		Name:    "inventory",
+		Aliases: []string{"inv"},
+		Keys:    []string{"i"},
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			return g.Inventory(), true, false
+		},
+	})
+	r.Register(&Command{
+		Name:    "look",
+		Aliases: []string{"l"},
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			return g.Look(), true, false
+		},
+	})
+	r.Register(&Command{
+		Name: "score",
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			return fmt.Sprintf("Score: %d", g.Score()), true, false
+		},
+	})
+	r.Register(&Command{
+		Name:  "save",
+		Usage: "save [name]",
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			msg, success := g.Save(s.Object)
+			return msg, success, false
+		},
+	})
+	r.Register(&Command{
+		Name:  "load",
+		Usage: "load [name]",
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			msg, success := g.Load(s.Object)
+			return msg, success, false
+		},
+	})
+	r.Register(&Command{
+		Name: "help",
+		Keys: []string{"h"},
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			return r.HelpText(), true, false
+		},
+	})
+	r.Register(&Command{
+		Name: "quit",
+		Keys: []string{"q"},
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			return "Goodbye!", true, true
+		},
+	})
+}
@@ -0,0 +1,77 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry_KeyLegend(t *testing.T) {
+	want := "w,a,s,d: move | e: take | u: unlock | i: inventory | h: help | q: quit"
+	if got := Commands.KeyLegend(); got != want {
+		t.Errorf("KeyLegend() = %q, want %q", got, want)
+	}
+}
+
+func TestRegistry_IsInstantKey(t *testing.T) {
+	for _, key := range []string{"w", "a", "s", "d", "e", "i", "u", "h", "q"} {
+		if !Commands.IsInstantKey(key) {
+			t.Errorf("Expected %q to be an instant key", key)
+		}
+	}
+	// Typed-only verbs and aliases must not fire on a single keypress.
+	for _, key := range []string{"l", "x", "go", "look"} {
+		if Commands.IsInstantKey(key) {
+			t.Errorf("Expected %q not to be an instant key", key)
+		}
+	}
+}
+
+func TestRegistry_HelpListsEveryCommand(t *testing.T) {
+	help := Commands.HelpText()
+	for _, c := range Commands.All() {
+		usage := c.Usage
+		if usage == "" {
+			usage = c.Name
+		}
+		if !strings.Contains(help, usage) {
+			t.Errorf("Help should mention %q, got: %s", usage, help)
+		}
+	}
+}
+
+func TestRegistry_DuplicateVerbPanics(t *testing.T) {
+	r := NewRegistry()
+	r.Register(&Command{Name: "look", Aliases: []string{"l"}})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Registering a taken alias should panic")
+		}
+	}()
+	r.Register(&Command{Name: "listen", Aliases: []string{"l"}})
+}
+
+func TestRegistry_NewVerbDispatches(t *testing.T) {
+	r := NewRegistry()
+	r.Register(&Command{
+		Name:     "wait",
+		Aliases:  []string{"z"},
+		UsesTurn: true,
+		Handler: func(g *Game, s Sentence) (string, bool, bool) {
+			return "Time passes.", true, false
+		},
+	})
+
+	saved := Commands
+	Commands = r
+	defer func() { Commands = saved }()
+
+	game := createSimpleLayout()
+	msg, _ := game.HandleCommand("z")
+	if msg != "Time passes." {
+		t.Errorf("Expected registered handler to run, got: %s", msg)
+	}
+	if game.Turns != 1 {
+		t.Errorf("Command with UsesTurn should increment turns, got %d", game.Turns)
+	}
+}
@@ -0,0 +1,182 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"text-adventure-v2/dialogue"
+	"text-adventure-v2/world"
+)
+
+// Conversation is a dialogue in progress. While one is running, typing a
+// choice's number picks it; any other command ends the conversation.
+type Conversation struct {
+	NPC  *world.NPC
+	Room *world.Room // where the conversation takes place
+	Tree *dialogue.Tree
+	Node string // ID of the current node
+}
+
+// Choices returns the text of the choices on offer, numbered from 1 in order.
+// It is empty when no conversation is running.
+func (g *Game) Choices() []string {
+	var texts []string
+	for _, c := range g.availableChoices() {
+		texts = append(texts, c.Text)
+	}
+	return texts
+}
+
+// Choose picks the nth choice (counting from 1), applies its effects and moves
+// the conversation on.
+func (g *Game) Choose(n int) (string, bool, bool) {
+	conv := g.Conversation
+	if conv == nil {
+		return "You aren't talking to anyone.", false, false
+	}
+	choices := g.availableChoices()
+	if n < 1 || n > len(choices) {
+		return fmt.Sprintf("Choose a number from 1 to %d.", len(choices)), false, false
+	}
+	choice := choices[n-1]
+
+	lines := []string{fmt.Sprintf("You say, %q", choice.Text)}
+	for _, e := range choice.Effects {
+		msg, won := g.applyEffect(e)
+		if msg != "" {
+			lines = append(lines, msg)
+		}
+		if won {
+			g.Conversation = nil
+			return strings.Join(lines, "\n"), false, true
+		}
+	}
+
+	if choice.Next == "" {
+		g.Conversation = nil
+	} else {
+		lines = append(lines, g.enterNode(choice.Next))
+	}
+	return strings.Join(lines, "\n"), true, false
+}
+
+// startConversation begins a dialogue tree with a character.
+func (g *Game) startConversation(npc *world.NPC, tree *dialogue.Tree) string {
+	g.Conversation = &Conversation{NPC: npc, Room: g.Player.Location, Tree: tree}
+	return g.enterNode(tree.Start)
+}
+
+// enterNode moves the conversation to a node and returns what the character
+// says. The conversation ends if no choices are on offer there.
+func (g *Game) enterNode(id string) string {
+	conv := g.Conversation
+	conv.Node = id
+	text := conv.Tree.Nodes[id].Text
+	if strings.Contains(text, dialogue.LinePlaceholder) {
+		text = strings.ReplaceAll(text, dialogue.LinePlaceholder, nextLine(conv.NPC))
+	}
+	if len(g.availableChoices()) == 0 {
+		g.Conversation = nil
+	}
+	return fmt.Sprintf("The %s says, \"%s\"", conv.NPC.Name, text)
+}
+
+// availableChoices returns the current node's choices whose conditions hold.
+func (g *Game) availableChoices() []dialogue.Choice {
+	if g.Conversation == nil {
+		return nil
+	}
+	var out []dialogue.Choice
+	for _, c := range g.Conversation.Tree.Nodes[g.Conversation.Node].Choices {
+		if g.allHold(c.If) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (g *Game) allHold(conditions []dialogue.Condition) bool {
+	for _, c := range conditions {
+		if !g.holds(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// holds evaluates one condition against the game state.
+func (g *Game) holds(c dialogue.Condition) bool {
+	switch {
+	case c.HasItem != "":
+		for _, item := range g.Player.Inventory {
+			if item.Name == c.HasItem {
+				return true
+			}
+		}
+		return false
+	case c.Visited != "":
+		return g.VisitedRooms[c.Visited]
+	case c.MinTurns > 0:
+		return g.Turns >= c.MinTurns
+	case c.Flag != "":
+		return g.Flags[c.Flag]
+	case c.NotFlag != "":
+		return !g.Flags[c.NotFlag]
+	}
+	return true
+}
+
+// applyEffect carries out one effect, returning a message for the player and
+// whether it won the game.
+func (g *Game) applyEffect(e dialogue.Effect) (string, bool) {
+	npc := g.Conversation.NPC
+	switch {
+	case e.GiveItem != nil:
+		item := &world.Item{Name: e.GiveItem.Name, Description: e.GiveItem.Description}
+		for _, b := range e.GiveItem.Behaviors {
+			item.Behaviors = append(item.Behaviors, world.Behavior{Kind: world.BehaviorKind(b.Kind), KeyID: b.KeyID, Text: b.Text, HP: b.HP})
+		}
+		g.Player.Inventory = append(g.Player.Inventory, item)
+		return "The " + npc.Name + " gives you the " + item.Name + ".", false
+
+	case e.Unlock != nil:
+		room := g.Conversation.Room
+		if e.Unlock.Room != "" {
+			room = g.AllRooms[e.Unlock.Room]
+		}
+		if room == nil {
+			return "", false
+		}
+		var opened []string
+		for _, dir := range sortedExitDirs(room) {
+			exit := room.Exits[dir]
+			if !exit.Locked || (e.Unlock.Direction != "" && e.Unlock.Direction != dir) {
+				continue
+			}
+			opened = append(opened, dir)
+			if _, _, won := g.openExit(exit); won {
+				return "The " + npc.Name + " unlocks the door to the " + dir + ". You win!", true
+			}
+		}
+		if len(opened) == 0 {
+			return "The " + npc.Name + " looks around, but there is no locked door here.", false
+		}
+		return "The " + npc.Name + " unlocks the door to the " + strings.Join(opened, " and ") + ".", false
+
+	case e.SetFlag != "":
+		if g.Flags == nil {
+			g.Flags = make(map[string]bool)
+		}
+		g.Flags[e.SetFlag] = true
+	}
+	return "", false
+}
+
+// nextLine returns a character's next plain line; the last one repeats.
+func nextLine(npc *world.NPC) string {
+	if len(npc.Lines) == 0 {
+		return "..."
+	}
+	line := npc.Lines[min(npc.Said, len(npc.Lines)-1)]
+	npc.Said++
+	return line
+}
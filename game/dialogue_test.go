@@ -0,0 +1,126 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"text-adventure-v2/dialogue"
+	"text-adventure-v2/generator"
+	"text-adventure-v2/world"
+)
+
+// guardTree takes a bribe when the player carries gold, and once bribed opens
+// the east door.
+const guardTree = `{
+  "id": "guard",
+  "start": "hello",
+  "nodes": {
+    "hello": {
+      "text": "{line}",
+      "choices": [
+        {"text": "Take this gold.", "next": "bribed", "if": [{"has_item": "gold"}],
+          "effects": [{"set_flag": "guard_bribed"}, {"give_item": {"name": "pass", "description": "A scrap of paper.",
+            "behaviors": [{"kind": "readable", "text": "Let the bearer through."}]}}]},
+        {"text": "Open the door.", "if": [{"flag": "guard_bribed"}], "effects": [{"unlock": {"direction": "east"}}]},
+        {"text": "Seen anything in Room C?", "next": "hello", "if": [{"visited": "Room C"}]},
+        {"text": "Long day?", "next": "hello", "if": [{"min_turns": 5}]},
+        {"text": "Goodbye."}
+      ]
+    },
+    "bribed": {"text": "Much obliged."}
+  }
+}`
+
+func guardGame(t *testing.T) *Game {
+	t.Helper()
+	tree, err := dialogue.Parse([]byte(guardTree))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	game := createSimpleLayout()
+	game.Dialogues = map[string]*dialogue.Tree{"guard": tree}
+	game.Player.Location.NPCs = []*world.NPC{{Name: "guard", Lines: []string{"Halt."}, Dialogue: "guard"}}
+	game.Player.Location.Exits["east"].Locked = true
+	return game
+}
+
+func TestDialogue_ConditionsFilterChoices(t *testing.T) {
+	game := guardGame(t)
+	if msg, _ := game.HandleCommand("talk to guard"); msg != `The guard says, "Halt."` {
+		t.Errorf("Expected the guard's line, got %q", msg)
+	}
+	if got := game.Choices(); len(got) != 1 || got[0] != "Goodbye." {
+		t.Errorf("Expected only Goodbye with nothing done, got %v", got)
+	}
+
+	game.Player.Inventory = []*world.Item{{Name: "gold"}}
+	game.VisitedRooms["Room C"] = true
+	game.Turns = 5
+	want := []string{"Take this gold.", "Seen anything in Room C?", "Long day?", "Goodbye."}
+	if got := game.Choices(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected choices %v, got %v", want, got)
+	}
+}
+
+func TestDialogue_EffectsChangeTheWorld(t *testing.T) {
+	game := guardGame(t)
+	game.Player.Inventory = []*world.Item{{Name: "gold"}}
+	game.HandleCommand("talk to guard")
+
+	msg, _ := game.HandleCommand("1")
+	if !strings.Contains(msg, `You say, "Take this gold."`) || !strings.Contains(msg, "gives you the pass") ||
+		!strings.Contains(msg, "Much obliged.") {
+		t.Errorf("Expected the reply, the gift and the answer, got %q", msg)
+	}
+	if !game.Flags["guard_bribed"] {
+		t.Error("Expected the choice to set its flag")
+	}
+	pass := game.Player.Inventory[len(game.Player.Inventory)-1]
+	if _, ok := pass.Behavior(world.BehaviorReadable); pass.Name != "pass" || !ok {
+		t.Errorf("Expected a readable pass, got %+v", pass)
+	}
+	if game.Conversation != nil {
+		t.Error("A node with no choices should end the conversation")
+	}
+
+	game.HandleCommand("talk to guard")
+	msg, _ = game.HandleCommand("2") // "Open the door." follows the bribe
+	if game.Player.Location.Exits["east"].Locked {
+		t.Errorf("Expected the guard to unlock the door, got %q", msg)
+	}
+}
+
+func TestDialogue_NumbersAndEnding(t *testing.T) {
+	game := guardGame(t)
+	game.HandleCommand("talk to guard")
+	turns := game.Turns
+
+	if msg, _ := game.HandleCommand("7"); msg != "Choose a number from 1 to 1." {
+		t.Errorf("Expected a range hint, got %q", msg)
+	}
+	if game.Conversation == nil || game.Turns != turns {
+		t.Error("A bad number should keep the conversation going without using a turn")
+	}
+
+	game.HandleCommand("look")
+	if game.Conversation != nil {
+		t.Error("Any other command should end the conversation")
+	}
+	if msg, _ := game.HandleCommand("1"); msg != "I don't understand that command." {
+		t.Errorf("Numbers outside a conversation should not be commands, got %q", msg)
+	}
+
+	game.HandleCommand("talk to guard")
+	game.HandleCommand("1")
+	if game.Conversation != nil {
+		t.Error("A choice with no next node should end the conversation")
+	}
+}
+
+func TestDialogue_BuiltinTreesLoad(t *testing.T) {
+	game := NewGame(generator.DefaultConfig())
+	for _, id := range []string{"gaoler", "weeping_ghost", "old_miner", "talking_fox"} {
+		if _, ok := game.Dialogues[id]; !ok {
+			t.Errorf("Expected built-in dialogue %q", id)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package game
+
+import (
+	"strings"
+	"text-adventure-v2/generator"
+	"text-adventure-v2/world"
+)
+
+// PlayerMaxHP is the player's health at the start of a game. The generator
+// checks its fights against the same figure.
+const PlayerMaxHP = generator.PlayerHP
+
+// FightResult is how a fight ended, as reported by whatever ran it.
+type FightResult struct {
+	Won      bool
+	PlayerHP int // the player's health when the fight ended
+}
+
+// Encounter returns the enemy waiting in the player's room, or nil if there is
+// none. The TUI starts a fight when a move lands the player next to one.
+func (g *Game) Encounter() *world.Enemy {
+	return g.Player.Location.Enemy
+}
+
+// EndFight applies the outcome of a fight with the enemy in the player's room.
+// A won fight removes the enemy, which drops its loot. A lost one is the end
+// of the game.
+func (g *Game) EndFight(result FightResult) string {
+	enemy := g.Encounter()
+	if enemy == nil {
+		return ""
+	}
+	g.Player.HP = min(max(result.PlayerHP, 0), g.Player.MaxHP)
+
+	if result.Won {
+		room := g.Player.Location
+		room.Enemy = nil
+		room.Items = append(room.Items, enemy.Loot...)
+		msg := "You defeated the " + enemy.Name + "."
+		if len(enemy.Loot) > 0 {
+			names := make([]string, len(enemy.Loot))
+			for i, item := range enemy.Loot {
+				names[i] = withArticle(item.Name)
+			}
+			msg += " It drops " + joinAnd(names) + "."
+		}
+		return msg
+	}
+
+	g.Player.HP = 0
+	g.IsLost = true
+	return "The " + enemy.Name + " strikes you down."
+}
+
+// hurt takes health from the player; at zero the game is lost.
+func (g *Game) hurt(damage int) {
+	g.Player.HP = max(g.Player.HP-damage, 0)
+	if g.Player.HP == 0 {
+		g.IsLost = true
+	}
+}
+
+// heal restores up to amount health, never past the player's maximum, and
+// returns how much was restored.
+func (g *Game) heal(amount int) int {
+	healed := min(amount, g.Player.MaxHP-g.Player.HP)
+	g.Player.HP += max(healed, 0)
+	return max(healed, 0)
+}
+
+// joinAnd lists words as "a, b and c".
+func joinAnd(words []string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
@@ -0,0 +1,168 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"text-adventure-v2/world"
+)
+
+// createGuardedLayout puts a rat in Room C of the simple layout.
+func createGuardedLayout() *Game {
+	game := createSimpleLayout()
+	game.Player.HP, game.Player.MaxHP = PlayerMaxHP, PlayerMaxHP
+	game.AllRooms["Room C"].Enemy = &world.Enemy{Name: "rat", HP: 2, Damage: 1, Profile: world.ProfileCautious,
+		Loot: []*world.Item{{Name: "rat tail"}, {Name: "old coin"}}}
+	return game
+}
+
+func TestEncounter_OnEnteringTheRoom(t *testing.T) {
+	game := createGuardedLayout()
+	if game.Encounter() != nil {
+		t.Error("Expected no enemy in Room B")
+	}
+	game.HandleCommand("go east")
+	if enemy := game.Encounter(); enemy == nil || enemy.Name != "rat" {
+		t.Errorf("Expected the rat in Room C, got %+v", enemy)
+	}
+}
+
+func TestEndFight_WinRemovesEnemy(t *testing.T) {
+	game := createGuardedLayout()
+	game.HandleCommand("go east")
+
+	msg := game.EndFight(FightResult{Won: true, PlayerHP: 3})
+	if want := "You defeated the rat. It drops a rat tail and an old coin."; msg != want {
+		t.Errorf("Expected %q, got %q", want, msg)
+	}
+	if game.Player.Location.Enemy != nil {
+		t.Error("The defeated enemy should be gone")
+	}
+	if items := game.Player.Location.Items; len(items) != 2 || items[0].Name != "rat tail" {
+		t.Errorf("Expected the loot on the floor, got %v", items)
+	}
+	if game.Player.HP != 3 {
+		t.Errorf("Expected the fight's damage to carry over, got %d HP", game.Player.HP)
+	}
+}
+
+func TestEndFight_LossEndsTheGame(t *testing.T) {
+	game := createGuardedLayout()
+	game.HandleCommand("go east")
+
+	if msg := game.EndFight(FightResult{PlayerHP: 0}); msg != "The rat strikes you down." {
+		t.Errorf("Expected a death message, got %q", msg)
+	}
+	if !game.IsLost || game.Player.HP != 0 {
+		t.Errorf("Expected the game to be lost on 0 HP, got lost=%t on %d HP", game.IsLost, game.Player.HP)
+	}
+	if msg, shouldExit := game.HandleCommand("look"); msg != "You are dead." || !shouldExit {
+		t.Errorf("Expected commands to be refused after death, got %q", msg)
+	}
+}
+
+func TestMove_TrapSpringsOnce(t *testing.T) {
+	game := createGuardedLayout()
+	game.AllRooms["Room A"].Trap = &world.Trap{Text: "A dart flies out of the wall.", Damage: 2}
+
+	msg, _ := game.HandleCommand("go west")
+	if want := "A dart flies out of the wall. You lose 2 health."; msg != want {
+		t.Errorf("Expected %q, got %q", want, msg)
+	}
+	if game.Player.HP != PlayerMaxHP-2 {
+		t.Errorf("Expected %d HP, got %d", PlayerMaxHP-2, game.Player.HP)
+	}
+	game.HandleCommand("go east")
+	if msg, _ := game.HandleCommand("go west"); msg != "" || game.Player.HP != PlayerMaxHP-2 {
+		t.Errorf("A sprung trap should not fire again, got %q on %d HP", msg, game.Player.HP)
+	}
+}
+
+func TestMove_TrapCanKill(t *testing.T) {
+	game := createGuardedLayout()
+	game.Player.HP = 1
+	game.AllRooms["Room A"].Trap = &world.Trap{Text: "The floor gives way.", Damage: 2}
+
+	msg, shouldExit := game.HandleCommand("go west")
+	if !strings.HasSuffix(msg, "You die.") || !shouldExit || !game.IsLost {
+		t.Errorf("Expected the trap to end the game, got %q", msg)
+	}
+	if game.Player.HP != 0 {
+		t.Errorf("Health should not go below zero, got %d", game.Player.HP)
+	}
+}
+
+func TestUse_ConsumableHeals(t *testing.T) {
+	game := createGuardedLayout()
+	game.Player.HP = 2
+	potion := func() *world.Item {
+		return &world.Item{Name: "potion", Behaviors: []world.Behavior{{Kind: world.BehaviorConsumable, Text: "You drink the potion.", HP: 2}}}
+	}
+	game.Player.Inventory = []*world.Item{potion(), potion(), potion()}
+
+	tests := []struct {
+		wantMsg string
+		wantHP  int
+	}{
+		{"You drink the potion. You regain 2 health.", 4},
+		{"You drink the potion. You regain 1 health.", PlayerMaxHP},
+		{"You drink the potion. You were already at full health.", PlayerMaxHP},
+	}
+	for i, tt := range tests {
+		msg, _ := game.HandleCommand("use potion")
+		if msg != tt.wantMsg || game.Player.HP != tt.wantHP {
+			t.Errorf("use #%d: expected %q on %d HP, got %q on %d HP", i+1, tt.wantMsg, tt.wantHP, msg, game.Player.HP)
+		}
+	}
+}
+
+func TestScore_CountsHealth(t *testing.T) {
+	game := createGuardedLayout()
+	// 1 room visited = 5, plus 5 per point of health.
+	if want := 5 + PlayerMaxHP*5; game.Score() != want {
+		t.Errorf("Expected score %d at full health, got %d", want, game.Score())
+	}
+	game.Player.HP = 1
+	if game.Score() != 10 {
+		t.Errorf("Expected score 10 on 1 HP, got %d", game.Score())
+	}
+}
+
+func TestSaveAndLoad_KeepsHealthEnemiesAndTraps(t *testing.T) {
+	game := createGuardedLayout()
+	game.Player.HP = 2
+	game.AllRooms["Room A"].Trap = &world.Trap{Text: "Spikes!", Damage: 1}
+	game.Player.Inventory = []*world.Item{{Name: "potion", Behaviors: []world.Behavior{{Kind: world.BehaviorConsumable, HP: 2}}}}
+
+	data, err := EncodeSave(game)
+	if err != nil {
+		t.Fatalf("EncodeSave failed: %v", err)
+	}
+	loaded, err := DecodeSave(data)
+	if err != nil {
+		t.Fatalf("DecodeSave failed: %v", err)
+	}
+	if loaded.Player.HP != 2 || loaded.Player.MaxHP != PlayerMaxHP {
+		t.Errorf("Expected 2/%d HP, got %d/%d", PlayerMaxHP, loaded.Player.HP, loaded.Player.MaxHP)
+	}
+	if enemy := loaded.AllRooms["Room C"].Enemy; enemy == nil || enemy.Name != "rat" || enemy.HP != 2 ||
+		enemy.Damage != 1 || enemy.Profile != world.ProfileCautious || len(enemy.Loot) != 2 {
+		t.Errorf("Expected the rat to survive a save, got %+v", enemy)
+	}
+	if trap := loaded.AllRooms["Room A"].Trap; trap == nil || trap.Damage != 1 {
+		t.Errorf("Expected the trap to survive a save, got %+v", trap)
+	}
+	if b, _ := loaded.Player.Inventory[0].Behavior(world.BehaviorConsumable); b.HP != 2 {
+		t.Errorf("Expected healing to survive a save, got %+v", b)
+	}
+}
+
+func TestDecodeSave_MigratesV3Health(t *testing.T) {
+	data := `{"version":3,"player":{"location":"A"},"rooms":[{"name":"A"}]}`
+	game, err := DecodeSave([]byte(data))
+	if err != nil {
+		t.Fatalf("DecodeSave failed: %v", err)
+	}
+	if game.Player.HP != PlayerMaxHP || game.Player.MaxHP != PlayerMaxHP {
+		t.Errorf("Expected full health after migrating, got %d/%d", game.Player.HP, game.Player.MaxHP)
+	}
+}
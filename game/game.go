@@ -3,20 +3,27 @@ package game
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
+	"text-adventure-v2/dialogue"
 	"text-adventure-v2/generator"
 	"text-adventure-v2/world"
 )
 
-// NewGame creates a new game instance.
-func NewGame() *Game {
+// NewGame creates a new game instance from the given generator config.
+func NewGame(config generator.Config) *Game {
 	// Generate a new world
-	startRoom, err := generator.Generate(generator.DefaultConfig())
+	startRoom, err := generator.Generate(config)
 	if err != nil {
 		// For now, we'll panic. In a real application, you might want to handle this more gracefully.
 		panic(fmt.Sprintf("failed to generate world: %v", err))
 	}
 
+	dialogues, err := dialogue.Builtin()
+	if err != nil {
+		panic(fmt.Sprintf("failed to load dialogue: %v", err))
+	}
+
 	allRooms := make(map[string]*world.Room)
 	GetAllRooms(startRoom, allRooms)
 
@@ -24,6 +31,8 @@ func NewGame() *Game {
 		Name:      "Player",
 		Location:  startRoom,
 		Inventory: make([]*world.Item, 0),
+		HP:        PlayerMaxHP,
+		MaxHP:     PlayerMaxHP,
 	}
 
 	return &Game{
@@ -32,6 +41,9 @@ func NewGame() *Game {
 		IsWon:        false,
 		Turns:        0,
 		VisitedRooms: map[string]bool{startRoom.Name: true},
+		Seed:         config.Seed,
+		Flags:        make(map[string]bool),
+		Dialogues:    dialogues,
 	}
 }
 
@@ -47,75 +59,67 @@ func GetAllRooms(room *world.Room, rooms map[string]*world.Room) {
 }
 
 // HandleCommand processes a player command and updates the game state.
+// The input is parsed into a Sentence and dispatched through the Commands registry.
 func (g *Game) HandleCommand(command string) (string, bool) {
-	verb, noun := ParseInput(strings.ToLower(command))
-
-	var msg string
-	var success, shouldExit bool
-
-	switch verb {
-	case "quit", "q":
-		return "Goodbye!", true
-	case "help", "h":
-		return "Instant Commands: w,a,s,d (move), e (take), i (inventory), u (unlock), l (look), q (quit)\nTyped Commands: go [dir], take [item], drop [item], unlock, score, help, quit", false
-	case "look", "l":
-		return g.Look(), false
-	case "inventory", "i":
-		return g.Inventory(), false
-	case "score":
-		return fmt.Sprintf("Score: %d", g.Score()), false
-	case "go":
-		msg, success = g.Move(noun)
-	case "w", "a", "s", "d":
-		var dir string
-		dir = map[string]string{"w": "north", "a": "west", "s": "south", "d": "east"}[verb]
-		msg, success = g.Move(dir)
-	case "take":
-		msg, success = g.Take(noun)
-	case "e":
-		if len(g.Player.Location.Items) > 0 {
-			msg, success = g.Take(g.Player.Location.Items[0].Name)
-		} else {
-			msg, success = "There is nothing to take.", false
+	if g.IsLost {
+		return "You are dead.", true
+	}
+	sentence := Parse(command)
+
+	// During a conversation a number picks a choice; anything else ends it.
+	if g.Conversation != nil {
+		if n, err := strconv.Atoi(strings.TrimSpace(command)); err == nil {
+			msg, _, shouldExit := g.Choose(n)
+			return msg, shouldExit
 		}
-	case "drop":
-		msg, success = g.Drop(noun)
-	case "unlock", "u":
-		msg, success, shouldExit = g.Unlock()
-	default:
-		msg, success = "I don't understand that command.", false
+		g.Conversation = nil
+	}
+
+	cmd, ok := Commands.Lookup(sentence.Verb)
+	if !ok {
+		return "I don't understand that command.", false
 	}
 
-	if success {
+	msg, success, shouldExit := cmd.Handler(g, sentence)
+	if success && cmd.UsesTurn {
 		g.Turns++
 	}
 
-	return msg, shouldExit
+	return msg, shouldExit || g.IsLost
 }
 
 // Score returns the player's current score.
-// 10 points per inventory item, 5 points per room visited.
+// 10 points per inventory item, 5 points per room visited, 5 points per point
+// of health left.
 func (g *Game) Score() int {
-	return len(g.Player.Inventory)*10 + len(g.VisitedRooms)*5
+	return len(g.Player.Inventory)*10 + len(g.VisitedRooms)*5 + g.Player.HP*5
 }
 
 // Look returns the description of the player's current location.
 func (g *Game) Look() string {
 	var b strings.Builder
-	b.WriteString(g.Player.Location.Description + "\n")
-	if len(g.Player.Location.Items) > 0 {
+	if g.canSee() {
+		b.WriteString(g.Player.Location.Description + "\n")
+	} else {
+		b.WriteString("It is pitch dark. You can't see a thing.\n")
+	}
+	if g.canSee() {
+		for _, npc := range g.Player.Location.NPCs {
+			fmt.Fprintf(&b, "%s is here. %s\n", capitalize(withArticle(npc.Name)), npc.Description)
+		}
+	}
+	if g.canSee() && len(g.Player.Location.Items) > 0 {
 		b.WriteString("You see the following items:\n")
 		for _, item := range g.Player.Location.Items {
 			fmt.Fprintf(&b, "- %s\n", item.Name)
 		}
 	}
 	b.WriteString("Exits:\n")
-	dirs := make([]string, 0, len(g.Player.Location.Exits))
-	for dir := range g.Player.Location.Exits {
-		dirs = append(dirs, dir)
-	}
-	sort.Strings(dirs)
-	for _, dir := range dirs {
+	for _, dir := range sortedExitDirs(g.Player.Location) {
+		if obstacle := g.Player.Location.Exits[dir].Obstacle; obstacle != "" {
+			fmt.Fprintf(&b, "- %s (blocked by %s)\n", dir, obstacle)
+			continue
+		}
 		fmt.Fprintf(&b, "- %s\n", dir)
 	}
 	return b.String()
@@ -137,11 +141,30 @@ func (g *Game) Inventory() string {
 // Move moves the player in the given direction.
 func (g *Game) Move(direction string) (string, bool) {
 	if exit, ok := g.Player.Location.Exits[direction]; ok {
+		if exit.Obstacle != "" {
+			return "The way " + direction + " is blocked by " + exit.Obstacle + ".", false
+		}
 		if exit.Locked {
-			return "The door is locked.", false
+			msg := "The door is locked."
+			if exit.LockText != "" {
+				msg = exit.LockText
+			}
+			if exit.KeyID != "" {
+				msg += " It takes " + withArticle(exit.KeyID) + " key."
+			}
+			return msg, false
 		}
 		g.Player.Location = exit.Room
 		g.VisitedRooms[exit.Room.Name] = true
+		if trap := exit.Room.Trap; trap != nil {
+			exit.Room.Trap = nil
+			g.hurt(trap.Damage)
+			msg := fmt.Sprintf("%s You lose %d health.", trap.Text, trap.Damage)
+			if g.IsLost {
+				msg += " You die."
+			}
+			return msg, true
+		}
 		return "", true
 	}
 	return "You can't go that way.", false
@@ -149,6 +172,9 @@ func (g *Game) Move(direction string) (string, bool) {
 
 // Take picks up an item from the current room.
 func (g *Game) Take(itemName string) (string, bool) {
+	if !g.canSee() {
+		return "It is too dark to find anything.", false
+	}
 	if itemName == "" {
 		if len(g.Player.Location.Items) == 1 {
 			itemName = g.Player.Location.Items[0].Name
@@ -157,62 +183,232 @@ func (g *Game) Take(itemName string) (string, bool) {
 		}
 	}
 
-	for i, item := range g.Player.Location.Items {
-		if strings.ToLower(item.Name) == strings.ToLower(itemName) {
-			g.Player.Inventory = append(g.Player.Inventory, item)
-			g.Player.Location.Items = append(g.Player.Location.Items[:i], g.Player.Location.Items[i+1:]...)
-			return "You took the " + item.Name + ".", true
-		}
+	i, msg := resolveItem(itemName, g.Player.Location.Items, "You don't see that here.")
+	if i < 0 {
+		return msg, false
 	}
-	return "You don't see that here.", false
+	item := g.Player.Location.Items[i]
+	g.Player.Inventory = append(g.Player.Inventory, item)
+	g.Player.Location.Items = append(g.Player.Location.Items[:i], g.Player.Location.Items[i+1:]...)
+	return "You took the " + item.Name + ".", true
 }
 
 // Drop drops an item into the current room.
 func (g *Game) Drop(itemName string) (string, bool) {
+	if !g.canSee() {
+		// Take refuses in the dark, so anything dropped here would be lost.
+		return "It is too dark to see where it would fall.", false
+	}
 	if itemName == "" {
 		return "What do you want to drop?", false
 	}
 
-	for i, item := range g.Player.Inventory {
-		if strings.ToLower(item.Name) == strings.ToLower(itemName) {
-			g.Player.Location.Items = append(g.Player.Location.Items, item)
-			g.Player.Inventory = append(g.Player.Inventory[:i], g.Player.Inventory[i+1:]...)
-			return "You dropped the " + item.Name + ".", true
-		}
+	i, msg := resolveItem(itemName, g.Player.Inventory, "You don't have that.")
+	if i < 0 {
+		return msg, false
 	}
-	return "You don't have that.", false
+	item := g.Player.Inventory[i]
+	g.Player.Location.Items = append(g.Player.Location.Items, item)
+	g.Player.Inventory = append(g.Player.Inventory[:i], g.Player.Inventory[i+1:]...)
+	return "You dropped the " + item.Name + ".", true
 }
 
-// Unlock unlocks a door.
+// Unlock unlocks the first locked door in the room that one of the player's keys fits.
 func (g *Game) Unlock() (string, bool, bool) {
-	var lockedExit *world.Exit
-	for _, exit := range g.Player.Location.Exits {
-		if exit.Locked {
-			lockedExit = exit
-			break
+	return g.UnlockWith(nil, "")
+}
+
+// UnlockWith unlocks a door. If key is nil any key in the inventory may be
+// used; if dir is empty every locked door in the room is tried in order.
+func (g *Game) UnlockWith(key *world.Item, dir string) (string, bool, bool) {
+	var locked []*world.Exit
+	for _, d := range sortedExitDirs(g.Player.Location) {
+		exit := g.Player.Location.Exits[d]
+		if exit.Locked && (dir == "" || dir == d) {
+			locked = append(locked, exit)
 		}
 	}
 
-	if lockedExit == nil {
+	if len(locked) == 0 {
+		if dir != "" {
+			return "There is no locked door to the " + dir + ".", false, false
+		}
 		return "There is nothing to unlock here.", false, false
 	}
 
-	hasKey := false
-	for _, item := range g.Player.Inventory {
-		if item.Name == "key" {
-			hasKey = true
-			break
+	keys := g.Player.Inventory
+	if key != nil {
+		keys = []*world.Item{key}
+	}
+	for _, exit := range locked {
+		for _, item := range keys {
+			if keyFits(item, exit) {
+				return g.openExit(exit)
+			}
 		}
 	}
 
-	if !hasKey {
-		return "You don't have the key.", false, false
+	if key != nil {
+		return "The " + key.Name + " doesn't fit the lock.", false, false
 	}
+	return "You don't have the key.", false, false
+}
+
+// keyFits reports whether item is a key for the lock on the given exit.
+func keyFits(item *world.Item, exit *world.Exit) bool {
+	b, ok := item.Behavior(world.BehaviorKey)
+	return ok && (exit.KeyID == "" || b.KeyID == exit.KeyID)
+}
 
-	lockedExit.Locked = false
-	if lockedExit.Room.Name == "Treasure Room" {
+// openExit unlocks an exit, winning the game if it leads to the treasure room.
+func (g *Game) openExit(exit *world.Exit) (string, bool, bool) {
+	exit.Locked = false
+	if exit.Room.Name == "Treasure Room" {
 		g.IsWon = true
 		return "You unlocked the door! You win!", false, true
 	}
 	return "You unlocked the door.", true, false
 }
+
+// Talk has a conversation with a character in the room, who says their next
+// line. Characters with a dialogue tree start a conversation instead. With no
+// name given, the only character present is addressed.
+func (g *Game) Talk(name string) (string, bool) {
+	npcs := g.Player.Location.NPCs
+	if len(npcs) == 0 {
+		return "There is nobody here to talk to.", false
+	}
+	if name == "" {
+		if len(npcs) > 1 {
+			return "Who do you want to talk to?", false
+		}
+		name = npcs[0].Name
+	}
+
+	names := make([]string, len(npcs))
+	for i, npc := range npcs {
+		names[i] = npc.Name
+	}
+	i, msg := resolveName(name, names, "There is nobody here by that name.")
+	if i < 0 {
+		return msg, false
+	}
+	npc := npcs[i]
+	if tree, ok := g.Dialogues[npc.Dialogue]; ok {
+		return g.startConversation(npc, tree), true
+	}
+	if len(npc.Lines) == 0 {
+		return "The " + npc.Name + " has nothing to say.", true
+	}
+	return fmt.Sprintf("The %s says, \"%s\"", npc.Name, nextLine(npc)), true
+}
+
+// capitalize upper-cases the first letter of s.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// withArticle prefixes a word with "a" or "an".
+func withArticle(word string) string {
+	if word != "" && strings.ContainsRune("aeiou", rune(word[0])) {
+		return "an " + word
+	}
+	return "a " + word
+}
+
+func sortedExitDirs(room *world.Room) []string {
+	dirs := make([]string, 0, len(room.Exits))
+	for dir := range room.Exits {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// resolveItem finds the item a phrase refers to and returns its index, or -1
+// and a message for the player. An exact name match wins; otherwise every word
+// of the phrase must appear in the item's name, so "key" matches "rusty key".
+// Several partial matches are ambiguous and the player is asked to choose.
+func resolveItem(phrase string, items []*world.Item, notFound string) (int, string) {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Name
+	}
+	return resolveName(phrase, names, notFound)
+}
+
+// resolveName is resolveItem for a plain list of names.
+func resolveName(phrase string, names []string, notFound string) (int, string) {
+	phrase = strings.ToLower(strings.TrimSpace(phrase))
+	var candidates []int
+	for i, n := range names {
+		name := strings.ToLower(n)
+		if name == phrase {
+			return i, ""
+		}
+		if containsAllWords(name, phrase) {
+			candidates = append(candidates, i)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return -1, notFound
+	case 1:
+		return candidates[0], ""
+	}
+
+	choices := make([]string, len(candidates))
+	for i, c := range candidates {
+		choices[i] = "the " + names[c]
+	}
+	// Two identical items are interchangeable; don't ask which one.
+	if allSame(choices) {
+		return candidates[0], ""
+	}
+	list := strings.Join(choices[:len(choices)-1], ", ") + " or " + choices[len(choices)-1]
+	return -1, fmt.Sprintf("Which %s do you mean, %s?", phrase, list)
+}
+
+// resolveHeldOrNearby resolves a phrase against the inventory first, then the room.
+func (g *Game) resolveHeldOrNearby(phrase string) (*world.Item, string) {
+	items := append(append([]*world.Item{}, g.Player.Inventory...), g.Player.Location.Items...)
+	i, msg := resolveItem(phrase, items, "You don't see that here.")
+	if i < 0 {
+		return nil, msg
+	}
+	return items[i], ""
+}
+
+func containsAllWords(name, phrase string) bool {
+	nameWords := strings.Fields(name)
+	phraseWords := strings.Fields(phrase)
+	if len(phraseWords) == 0 {
+		return false
+	}
+	for _, pw := range phraseWords {
+		found := false
+		for _, nw := range nameWords {
+			if nw == pw {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func allSame(names []string) bool {
+	for _, n := range names[1:] {
+		if n != names[0] {
+			return false
+		}
+	}
+	return true
+}
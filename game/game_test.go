@@ -410,6 +410,70 @@ func TestTake_ItemNotFound(t *testing.T) {
 	}
 }
 
+func TestTake_PartialName(t *testing.T) {
+	game := createSimpleLayout()
+	game.Player.Location.Items = []*world.Item{{Name: "rusty key", Description: "A rusty key."}}
+
+	msg, _ := game.HandleCommand("take the key")
+	if msg != "You took the rusty key." {
+		t.Errorf("Expected partial name to match, got: %s", msg)
+	}
+}
+
+func TestTake_Ambiguous(t *testing.T) {
+	game := createSimpleLayout()
+	game.Player.Location.Items = []*world.Item{
+		{Name: "rusty key", Description: "A rusty key."},
+		{Name: "brass key", Description: "A brass key."},
+	}
+
+	msg, _ := game.HandleCommand("take key")
+	if msg != "Which key do you mean, the rusty key or the brass key?" {
+		t.Errorf("Expected ambiguity prompt, got: %s", msg)
+	}
+	if game.Turns != 0 {
+		t.Error("Ambiguous take should not increment turns")
+	}
+
+	msg, _ = game.HandleCommand("grab the brass key")
+	if msg != "You took the brass key." {
+		t.Errorf("Expected qualified name to resolve, got: %s", msg)
+	}
+}
+
+func TestHandleCommand_Synonyms(t *testing.T) {
+	game := createLayoutWithItems()
+	game.HandleCommand("west")
+	if game.Player.Location.Name != "Room A" {
+		t.Fatalf("Bare direction should move, but in %s", game.Player.Location.Name)
+	}
+	game.HandleCommand("get test_item")
+	if len(game.Player.Inventory) != 1 {
+		t.Error("'get' should take the item")
+	}
+	game.HandleCommand("walk e")
+	if game.Player.Location.Name != "Room B" {
+		t.Errorf("'walk e' should move east, but in %s", game.Player.Location.Name)
+	}
+	if game.Turns != 3 {
+		t.Errorf("Expected 3 turns, got %d", game.Turns)
+	}
+}
+
+func TestUnlock_WithItem(t *testing.T) {
+	game := createLayoutWithLock()
+	game.Player.Inventory = []*world.Item{{Name: "sword"}, testKey()}
+
+	msg, _ := game.HandleCommand("unlock door with sword")
+	if msg != "The sword doesn't fit the lock." {
+		t.Errorf("Expected wrong-item message, got: %s", msg)
+	}
+	msg, _ = game.HandleCommand("unlock the door with the key")
+	if msg != "You unlocked the door." {
+		t.Errorf("Expected unlock message, got: %s", msg)
+	}
+}
+
 // --- HandleCommand quit/help/e tests ---
 
 func TestHandleCommand_Quit(t *testing.T) {
@@ -500,6 +564,18 @@ func TestMove_LockedDoorMessage(t *testing.T) {
 	}
 }
 
+func TestMove_LockFlavorText(t *testing.T) {
+	game := createLayoutWithLock()
+	exit := game.AllRooms["Room B"].Exits["east"]
+	exit.LockText = "A portcullis blocks the passage."
+	exit.KeyID = "iron"
+
+	msg, _ := game.Move("east")
+	if msg != "A portcullis blocks the passage. It takes an iron key." {
+		t.Errorf("Expected flavor text with the key hint, got: %s", msg)
+	}
+}
+
 // --- Drop behavior tests ---
 
 func TestDrop_EmptyName(t *testing.T) {
@@ -2,16 +2,81 @@ package game
 
 import "strings"
 
-// ParseInput splits a string into a verb and a noun.
-func ParseInput(input string) (string, string) {
-	parts := strings.Fields(input)
-	if len(parts) == 0 {
-		return "", ""
+// Sentence is a parsed player command: "unlock the north door with the key"
+// becomes Verb "unlock", Object "north door", Preposition "with", Indirect "key".
+type Sentence struct {
+	Verb        string
+	Object      string // direct object, articles stripped
+	Preposition string
+	Indirect    string // indirect object, articles stripped
+}
+
+// Target returns the direct object, or the indirect object when the command
+// only has a prepositional phrase ("talk to guard", "go to the north").
+func (s Sentence) Target() string {
+	if s.Object != "" {
+		return s.Object
 	}
-	verb := parts[0]
-	noun := ""
-	if len(parts) > 1 {
-		noun = strings.Join(parts[1:], " ")
+	return s.Indirect
+}
+
+var articles = map[string]bool{"a": true, "an": true, "the": true, "some": true}
+
+var prepositions = map[string]bool{
+	"in": true, "into": true, "inside": true, "on": true, "onto": true,
+	"with": true, "using": true, "to": true, "at": true, "from": true, "under": true,
+}
+
+// directions maps every accepted spelling of a compass direction to its full name.
+// Single letters other than "n" are instant keys, so they only count as
+// directions in the object position ("go e").
+var directions = map[string]string{
+	"north": "north", "south": "south", "east": "east", "west": "west",
+	"n": "north", "s": "south", "e": "east", "w": "west",
+}
+
+// Parse turns raw input into a Sentence. The first word is the verb; the first
+// preposition after it splits the direct object from the indirect object.
+// A bare direction ("north", "n") becomes "go <direction>", and "pick up" is
+// read as "take". Verb synonyms are left to the command registry's aliases.
+func Parse(input string) Sentence {
+	words := strings.Fields(strings.ToLower(input))
+	if len(words) == 0 {
+		return Sentence{}
+	}
+
+	verb, rest := words[0], words[1:]
+	if dir, ok := directions[verb]; ok && (len(verb) > 1 || verb == "n") && len(rest) == 0 {
+		return Sentence{Verb: "go", Object: dir}
+	}
+	if verb == "pick" && len(rest) > 0 && rest[0] == "up" {
+		verb, rest = "take", rest[1:]
+	}
+
+	s := Sentence{Verb: verb}
+	var object, indirect []string
+	for _, w := range rest {
+		switch {
+		case articles[w]:
+			continue
+		case s.Preposition == "" && prepositions[w]:
+			s.Preposition = w
+		case s.Preposition == "":
+			object = append(object, w)
+		default:
+			indirect = append(indirect, w)
+		}
+	}
+	s.Object = strings.Join(object, " ")
+	s.Indirect = strings.Join(indirect, " ")
+	return s
+}
+
+// normalizeDirection expands abbreviations like "n" to "north" and leaves
+// anything that is not a direction unchanged.
+func normalizeDirection(word string) string {
+	if dir, ok := directions[word]; ok {
+		return dir
 	}
-	return verb, noun
+	return word
 }
@@ -2,49 +2,39 @@ package game
 
 import "testing"
 
-func TestParseInput_Empty(t *testing.T) {
-	verb, noun := ParseInput("")
-	if verb != "" || noun != "" {
-		t.Errorf("Expected empty verb and noun, got verb=%q noun=%q", verb, noun)
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Sentence
+	}{
+		{"", Sentence{}},
+		{"look", Sentence{Verb: "look"}},
+		{"take the rusty key", Sentence{Verb: "take", Object: "rusty key"}},
+		{"Take A Key", Sentence{Verb: "take", Object: "key"}},
+		{"pick up the key", Sentence{Verb: "take", Object: "key"}},
+		{"unlock north door with key", Sentence{Verb: "unlock", Object: "north door", Preposition: "with", Indirect: "key"}},
+		{"unlock the door with the rusty key", Sentence{Verb: "unlock", Object: "door", Preposition: "with", Indirect: "rusty key"}},
+		{"talk to the guard", Sentence{Verb: "talk", Preposition: "to", Indirect: "guard"}},
+		{"north", Sentence{Verb: "go", Object: "north"}},
+		{"n", Sentence{Verb: "go", Object: "north"}},
+		{"e", Sentence{Verb: "e"}}, // instant key, not a direction
+		{"  go   north  ", Sentence{Verb: "go", Object: "north"}},
 	}
-}
-
-func TestParseInput_SingleWord(t *testing.T) {
-	verb, noun := ParseInput("look")
-	if verb != "look" {
-		t.Errorf("Expected verb 'look', got %q", verb)
-	}
-	if noun != "" {
-		t.Errorf("Expected empty noun, got %q", noun)
-	}
-}
 
-func TestParseInput_TwoWords(t *testing.T) {
-	verb, noun := ParseInput("go north")
-	if verb != "go" {
-		t.Errorf("Expected verb 'go', got %q", verb)
-	}
-	if noun != "north" {
-		t.Errorf("Expected noun 'north', got %q", noun)
-	}
-}
-
-func TestParseInput_MultiWordNoun(t *testing.T) {
-	verb, noun := ParseInput("take rusty old key")
-	if verb != "take" {
-		t.Errorf("Expected verb 'take', got %q", verb)
-	}
-	if noun != "rusty old key" {
-		t.Errorf("Expected noun 'rusty old key', got %q", noun)
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Parse(tt.input); got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
 	}
 }
 
-func TestParseInput_ExtraWhitespace(t *testing.T) {
-	verb, noun := ParseInput("  go   north  ")
-	if verb != "go" {
-		t.Errorf("Expected verb 'go', got %q", verb)
+func TestSentence_Target(t *testing.T) {
+	if got := Parse("talk to guard").Target(); got != "guard" {
+		t.Errorf("Target() = %q, want %q", got, "guard")
 	}
-	if noun != "north" {
-		t.Errorf("Expected noun 'north', got %q", noun)
+	if got := Parse("use the key on the north door").Target(); got != "key" {
+		t.Errorf("Target() = %q, want %q", got, "key")
 	}
 }
@@ -0,0 +1,479 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text-adventure-v2/world"
+)
+
+// SaveVersion is the current save file format version. Bump it when the
+// meaning of data an older save already holds changes, and register a
+// migration from the previous version. New optional fields decode as their
+// zero value from older saves and need no bump.
+const SaveVersion = 4
+
+// DefaultSaveDir is where save files go when Game.SaveDir is empty.
+const DefaultSaveDir = "saves"
+
+// saveFile is the on-disk snapshot of a game. The room graph is flattened:
+// rooms are identified by name and exits refer to their target by that name.
+type saveFile struct {
+	Version      int         `json:"version"`
+	Seed         int64       `json:"seed"`
+	Turns        int         `json:"turns"`
+	IsWon        bool        `json:"is_won"`
+	VisitedRooms []string    `json:"visited_rooms"`
+	Flags        []string    `json:"flags,omitempty"`
+	Player       savedPlayer `json:"player"`
+	Rooms        []savedRoom `json:"rooms"`
+}
+
+type savedPlayer struct {
+	Name      string      `json:"name"`
+	Location  string      `json:"location"`
+	Inventory []savedItem `json:"inventory"`
+	HP        int         `json:"hp"`
+	MaxHP     int         `json:"max_hp"`
+}
+
+type savedRoom struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	X           int         `json:"x"`
+	Y           int         `json:"y"`
+	Exits       []savedExit `json:"exits"`
+	Items       []savedItem `json:"items"`
+	NPCs        []savedNPC  `json:"npcs,omitempty"`
+	Enemy       *savedEnemy `json:"enemy,omitempty"`
+	Trap        *savedTrap  `json:"trap,omitempty"`
+	Dark        bool        `json:"dark,omitempty"`
+}
+
+type savedNPC struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Lines       []string `json:"lines"`
+	Said        int      `json:"said"`
+	Dialogue    string   `json:"dialogue,omitempty"`
+}
+
+type savedEnemy struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	HP          int         `json:"hp"`
+	Damage      int         `json:"damage,omitempty"`
+	Profile     string      `json:"profile,omitempty"`
+	Loot        []savedItem `json:"loot,omitempty"`
+}
+
+type savedTrap struct {
+	Text   string `json:"text"`
+	Damage int    `json:"damage"`
+}
+
+type savedExit struct {
+	Direction string `json:"direction"`
+	Room      string `json:"room"`
+	Locked    bool   `json:"locked"`
+	KeyID     string `json:"key_id,omitempty"`
+	Obstacle  string `json:"obstacle,omitempty"`
+	LockText  string `json:"lock_text,omitempty"`
+}
+
+type savedItem struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Behaviors   []savedBehavior `json:"behaviors,omitempty"`
+	Lit         bool            `json:"lit,omitempty"`
+}
+
+type savedBehavior struct {
+	Kind  string `json:"kind"`
+	KeyID string `json:"key_id,omitempty"`
+	Text  string `json:"text,omitempty"`
+	HP    int    `json:"hp,omitempty"`
+}
+
+// migration upgrades a decoded save from one version to the next, in place.
+type migration func(data map[string]any) error
+
+// migrations maps a save version to the function that upgrades it to version+1.
+// Older saves are walked forward one step at a time until they reach SaveVersion.
+var migrations = map[int]migration{
+	1: migrateV1ToV2,
+	2: migrateV2ToV3,
+	3: migrateV3ToV4,
+}
+
+// migrateV1ToV2 gives items named "key" the key behavior. Version 1 predates
+// item behaviors, when any item called "key" opened any locked door.
+func migrateV1ToV2(data map[string]any) error {
+	for _, item := range saveItemMaps(data) {
+		if item["name"] == "key" {
+			item["behaviors"] = []any{map[string]any{"kind": string(world.BehaviorKey)}}
+		}
+	}
+	return nil
+}
+
+// migrateV2ToV3 moves keys from naming the room they open ("opens") to key
+// IDs. Each such key's ID becomes that room name, and locked exits into the
+// room are given the same ID so the key still fits.
+func migrateV2ToV3(data map[string]any) error {
+	opened := make(map[string]bool)
+	for _, item := range saveItemMaps(data) {
+		behaviors, _ := item["behaviors"].([]any)
+		for _, b := range behaviors {
+			behavior, ok := b.(map[string]any)
+			if !ok {
+				continue
+			}
+			if room, ok := behavior["opens"].(string); ok {
+				behavior["key_id"] = room
+				delete(behavior, "opens")
+				opened[room] = true
+			}
+		}
+	}
+
+	rooms, _ := data["rooms"].([]any)
+	for _, r := range rooms {
+		room, _ := r.(map[string]any)
+		exits, _ := room["exits"].([]any)
+		for _, e := range exits {
+			exit, ok := e.(map[string]any)
+			if !ok {
+				continue
+			}
+			if target, _ := exit["room"].(string); exit["locked"] == true && opened[target] {
+				exit["key_id"] = target
+			}
+		}
+	}
+	return nil
+}
+
+// migrateV3ToV4 gives the player full health. Version 4 adds player health and
+// enemies; version 3 worlds have no enemies, so nobody has been hurt yet.
+func migrateV3ToV4(data map[string]any) error {
+	player, ok := data["player"].(map[string]any)
+	if !ok {
+		return errors.New("save has no player")
+	}
+	player["hp"] = float64(PlayerMaxHP)
+	player["max_hp"] = float64(PlayerMaxHP)
+	return nil
+}
+
+// saveItemMaps returns every item object in a decoded save: the player's
+// inventory followed by each room's items.
+func saveItemMaps(data map[string]any) []map[string]any {
+	var lists []any
+	if player, ok := data["player"].(map[string]any); ok {
+		lists = append(lists, player["inventory"])
+	}
+	if rooms, ok := data["rooms"].([]any); ok {
+		for _, r := range rooms {
+			if room, ok := r.(map[string]any); ok {
+				lists = append(lists, room["items"])
+			}
+		}
+	}
+	var out []map[string]any
+	for _, list := range lists {
+		items, _ := list.([]any)
+		for _, it := range items {
+			if item, ok := it.(map[string]any); ok {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
+// Save writes the current game state to <SaveDir>/<name>.json.
+func (g *Game) Save(name string) (string, bool) {
+	path, problem := g.savePath(name)
+	if problem != "" {
+		return problem, false
+	}
+	data, err := EncodeSave(g)
+	if err != nil {
+		return fmt.Sprintf("Could not save the game: %v", err), false
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Sprintf("Could not save the game: %v", err), false
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Sprintf("Could not save the game: %v", err), false
+	}
+	return fmt.Sprintf("Game saved as %q.", name), true
+}
+
+// Load replaces the current game state with the one stored in <SaveDir>/<name>.json.
+func (g *Game) Load(name string) (string, bool) {
+	path, problem := g.savePath(name)
+	if problem != "" {
+		return problem, false
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Sprintf("There is no save called %q.", name), false
+	}
+	if err != nil {
+		return fmt.Sprintf("Could not load the game: %v", err), false
+	}
+	loaded, err := DecodeSave(data)
+	if err != nil {
+		return fmt.Sprintf("Could not load the game: %v", err), false
+	}
+
+	g.Player = loaded.Player
+	g.AllRooms = loaded.AllRooms
+	g.IsWon = loaded.IsWon
+	g.Turns = loaded.Turns
+	g.VisitedRooms = loaded.VisitedRooms
+	g.Seed = loaded.Seed
+	g.Flags = loaded.Flags
+	g.Conversation = nil
+	return fmt.Sprintf("Game %q loaded.", name), true
+}
+
+// savePath validates a save name and returns the file it maps to, or a
+// message for the player explaining why the name was rejected.
+func (g *Game) savePath(name string) (string, string) {
+	if name == "" {
+		return "", "Please give the save a name."
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return "", "Save names may only contain letters, digits, '-' and '_'."
+		}
+	}
+	dir := g.SaveDir
+	if dir == "" {
+		dir = DefaultSaveDir
+	}
+	return filepath.Join(dir, name+".json"), ""
+}
+
+// EncodeSave serializes a game into the current save format.
+func EncodeSave(g *Game) ([]byte, error) {
+	sf := saveFile{
+		Version: SaveVersion,
+		Seed:    g.Seed,
+		Turns:   g.Turns,
+		IsWon:   g.IsWon,
+		Player: savedPlayer{
+			Name:      g.Player.Name,
+			Location:  g.Player.Location.Name,
+			Inventory: saveItems(g.Player.Inventory),
+			HP:        g.Player.HP,
+			MaxHP:     g.Player.MaxHP,
+		},
+	}
+
+	for name, visited := range g.VisitedRooms {
+		if visited {
+			sf.VisitedRooms = append(sf.VisitedRooms, name)
+		}
+	}
+	sort.Strings(sf.VisitedRooms)
+	for flag, set := range g.Flags {
+		if set {
+			sf.Flags = append(sf.Flags, flag)
+		}
+	}
+	sort.Strings(sf.Flags)
+
+	names := make([]string, 0, len(g.AllRooms))
+	for name := range g.AllRooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		room := g.AllRooms[name]
+		sr := savedRoom{
+			Name:        room.Name,
+			Description: room.Description,
+			X:           room.X,
+			Y:           room.Y,
+			Items:       saveItems(room.Items),
+			Dark:        room.Dark,
+		}
+		if room.Enemy != nil {
+			e := room.Enemy
+			sr.Enemy = &savedEnemy{Name: e.Name, Description: e.Description, HP: e.HP, Damage: e.Damage,
+				Profile: string(e.Profile), Loot: saveItems(e.Loot)}
+		}
+		if room.Trap != nil {
+			sr.Trap = &savedTrap{Text: room.Trap.Text, Damage: room.Trap.Damage}
+		}
+		for _, npc := range room.NPCs {
+			sr.NPCs = append(sr.NPCs, savedNPC{Name: npc.Name, Description: npc.Description, Lines: npc.Lines, Said: npc.Said, Dialogue: npc.Dialogue})
+		}
+		dirs := make([]string, 0, len(room.Exits))
+		for dir := range room.Exits {
+			dirs = append(dirs, dir)
+		}
+		sort.Strings(dirs)
+		for _, dir := range dirs {
+			exit := room.Exits[dir]
+			sr.Exits = append(sr.Exits, savedExit{Direction: dir, Room: exit.Room.Name, Locked: exit.Locked, KeyID: exit.KeyID, Obstacle: exit.Obstacle, LockText: exit.LockText})
+		}
+		sf.Rooms = append(sf.Rooms, sr)
+	}
+
+	return json.MarshalIndent(sf, "", "  ")
+}
+
+// DecodeSave rebuilds a game from save data, migrating older versions first.
+func DecodeSave(data []byte) (*Game, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("save file is not valid JSON: %w", err)
+	}
+	version, ok := raw["version"].(float64)
+	if !ok {
+		return nil, errors.New("save file has no version")
+	}
+	if err := migrateSave(raw, int(version), SaveVersion, migrations); err != nil {
+		return nil, err
+	}
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var sf saveFile
+	if err := json.Unmarshal(migrated, &sf); err != nil {
+		return nil, fmt.Errorf("save file is malformed: %w", err)
+	}
+
+	allRooms := make(map[string]*world.Room, len(sf.Rooms))
+	for _, sr := range sf.Rooms {
+		if _, dup := allRooms[sr.Name]; dup {
+			return nil, fmt.Errorf("save file has duplicate room %q", sr.Name)
+		}
+		allRooms[sr.Name] = &world.Room{
+			Name:        sr.Name,
+			Description: sr.Description,
+			Exits:       make(map[string]*world.Exit),
+			Items:       loadItems(sr.Items),
+			NPCs:        loadNPCs(sr.NPCs),
+			Enemy:       loadEnemy(sr.Enemy),
+			Trap:        loadTrap(sr.Trap),
+			X:           sr.X,
+			Y:           sr.Y,
+			Dark:        sr.Dark,
+		}
+	}
+	for _, sr := range sf.Rooms {
+		for _, se := range sr.Exits {
+			target, ok := allRooms[se.Room]
+			if !ok {
+				return nil, fmt.Errorf("room %q has an exit to unknown room %q", sr.Name, se.Room)
+			}
+			allRooms[sr.Name].Exits[se.Direction] = &world.Exit{Room: target, Locked: se.Locked, KeyID: se.KeyID, Obstacle: se.Obstacle, LockText: se.LockText}
+		}
+	}
+
+	location, ok := allRooms[sf.Player.Location]
+	if !ok {
+		return nil, fmt.Errorf("player is in unknown room %q", sf.Player.Location)
+	}
+
+	visited := make(map[string]bool, len(sf.VisitedRooms))
+	for _, name := range sf.VisitedRooms {
+		visited[name] = true
+	}
+	flags := make(map[string]bool, len(sf.Flags))
+	for _, flag := range sf.Flags {
+		flags[flag] = true
+	}
+
+	return &Game{
+		Player: &world.Player{
+			Name:      sf.Player.Name,
+			Location:  location,
+			Inventory: loadItems(sf.Player.Inventory),
+			HP:        sf.Player.HP,
+			MaxHP:     sf.Player.MaxHP,
+		},
+		AllRooms:     allRooms,
+		IsWon:        sf.IsWon,
+		Turns:        sf.Turns,
+		VisitedRooms: visited,
+		Seed:         sf.Seed,
+		Flags:        flags,
+	}, nil
+}
+
+// migrateSave walks data forward from version from to version to using steps.
+func migrateSave(data map[string]any, from, to int, steps map[int]migration) error {
+	if from > to {
+		return fmt.Errorf("save file version %d is newer than supported version %d", from, to)
+	}
+	for v := from; v < to; v++ {
+		step, ok := steps[v]
+		if !ok {
+			return fmt.Errorf("no migration from save version %d", v)
+		}
+		if err := step(data); err != nil {
+			return fmt.Errorf("migrating save from version %d: %w", v, err)
+		}
+		data["version"] = float64(v + 1)
+	}
+	return nil
+}
+
+func saveItems(items []*world.Item) []savedItem {
+	out := make([]savedItem, 0, len(items))
+	for _, item := range items {
+		si := savedItem{Name: item.Name, Description: item.Description, Lit: item.Lit}
+		for _, b := range item.Behaviors {
+			si.Behaviors = append(si.Behaviors, savedBehavior{Kind: string(b.Kind), KeyID: b.KeyID, Text: b.Text, HP: b.HP})
+		}
+		out = append(out, si)
+	}
+	return out
+}
+
+func loadEnemy(se *savedEnemy) *world.Enemy {
+	if se == nil {
+		return nil
+	}
+	return &world.Enemy{Name: se.Name, Description: se.Description, HP: se.HP, Damage: se.Damage,
+		Profile: world.EnemyProfile(se.Profile), Loot: loadItems(se.Loot)}
+}
+
+func loadTrap(st *savedTrap) *world.Trap {
+	if st == nil {
+		return nil
+	}
+	return &world.Trap{Text: st.Text, Damage: st.Damage}
+}
+
+func loadNPCs(npcs []savedNPC) []*world.NPC {
+	var out []*world.NPC
+	for _, sn := range npcs {
+		out = append(out, &world.NPC{Name: sn.Name, Description: sn.Description, Lines: sn.Lines, Said: sn.Said, Dialogue: sn.Dialogue})
+	}
+	return out
+}
+
+func loadItems(items []savedItem) []*world.Item {
+	out := make([]*world.Item, 0, len(items))
+	for _, si := range items {
+		item := &world.Item{Name: si.Name, Description: si.Description, Lit: si.Lit}
+		for _, b := range si.Behaviors {
+			item.Behaviors = append(item.Behaviors, world.Behavior{Kind: world.BehaviorKind(b.Kind), KeyID: b.KeyID, Text: b.Text, HP: b.HP})
+		}
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,222 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"text-adventure-v2/world"
+)
+
+func TestSaveAndLoad_RoundTrip(t *testing.T) {
+	game := createLayoutWithLock()
+	game.SaveDir = t.TempDir()
+	game.Seed = 1234
+
+	game.HandleCommand("go west")
+	game.HandleCommand("take key")
+	game.HandleCommand("go east")
+	game.HandleCommand("unlock")
+
+	msg, _ := game.HandleCommand("save slot1")
+	if !strings.Contains(msg, "Game saved") {
+		t.Fatalf("Expected save confirmation, got: %s", msg)
+	}
+
+	// Change the state, then load it back.
+	game.HandleCommand("go east")
+	game.HandleCommand("drop key")
+
+	msg, _ = game.HandleCommand("load slot1")
+	if !strings.Contains(msg, "loaded") {
+		t.Fatalf("Expected load confirmation, got: %s", msg)
+	}
+
+	if game.Player.Location.Name != "Room B" {
+		t.Errorf("Expected player in Room B after load, got %s", game.Player.Location.Name)
+	}
+	if game.Turns != 4 {
+		t.Errorf("Expected 4 turns after load, got %d", game.Turns)
+	}
+	if game.Seed != 1234 {
+		t.Errorf("Expected seed 1234 after load, got %d", game.Seed)
+	}
+	if len(game.Player.Inventory) != 1 || game.Player.Inventory[0].Name != "key" {
+		t.Errorf("Expected key in inventory after load, got %v", game.Player.Inventory)
+	}
+	if len(game.AllRooms["Room C"].Items) != 0 {
+		t.Error("Item dropped after saving should not be in Room C after load")
+	}
+	if !game.VisitedRooms["Room A"] || game.VisitedRooms["Room C"] {
+		t.Errorf("Visited rooms not restored, got %v", game.VisitedRooms)
+	}
+	if game.AllRooms["Room B"].Exits["east"].Locked {
+		t.Error("Unlocked door should stay unlocked after load")
+	}
+
+	// Exits must point at the loaded rooms, not the old ones.
+	if game.AllRooms["Room B"].Exits["west"].Room != game.AllRooms["Room A"] {
+		t.Error("Exit should point to the loaded Room A")
+	}
+	if game.Player.Location != game.AllRooms["Room B"] {
+		t.Error("Player location should be the loaded Room B")
+	}
+}
+
+func TestSaveAndLoad_DoNotUseTurns(t *testing.T) {
+	game := createSimpleLayout()
+	game.SaveDir = t.TempDir()
+
+	game.HandleCommand("save slot1")
+	game.HandleCommand("load slot1")
+	if game.Turns != 0 {
+		t.Errorf("Save and load should not increment turns, got %d", game.Turns)
+	}
+}
+
+func TestLoad_MissingSave(t *testing.T) {
+	game := createSimpleLayout()
+	game.SaveDir = t.TempDir()
+
+	msg, success := game.Load("nothing")
+	if success {
+		t.Error("Loading a missing save should fail")
+	}
+	if !strings.Contains(msg, "There is no save") {
+		t.Errorf("Expected missing save message, got: %s", msg)
+	}
+}
+
+func TestSave_RejectsBadNames(t *testing.T) {
+	game := createSimpleLayout()
+	game.SaveDir = t.TempDir()
+
+	for _, name := range []string{"", "../escape", "a b", "slot.json"} {
+		if _, success := game.Save(name); success {
+			t.Errorf("Save(%q) should be rejected", name)
+		}
+	}
+}
+
+func TestDecodeSave_RejectsDanglingExit(t *testing.T) {
+	data := `{"version":4,"player":{"location":"A"},"rooms":[
+		{"name":"A","exits":[{"direction":"east","room":"Nowhere"}]}]}`
+	if _, err := DecodeSave([]byte(data)); err == nil {
+		t.Error("Expected error for exit to unknown room")
+	}
+}
+
+func TestDecodeSave_RejectsNewerVersion(t *testing.T) {
+	data := `{"version":99,"player":{"location":"A"},"rooms":[{"name":"A"}]}`
+	if _, err := DecodeSave([]byte(data)); err == nil {
+		t.Error("Expected error for a save from a newer version")
+	}
+}
+
+func TestMigrateSave_WalksEachStep(t *testing.T) {
+	var applied []int
+	steps := map[int]migration{
+		1: func(data map[string]any) error {
+			applied = append(applied, 1)
+			data["turns"] = float64(7)
+			return nil
+		},
+		2: func(data map[string]any) error {
+			applied = append(applied, 2)
+			return nil
+		},
+	}
+
+	data := map[string]any{"version": float64(1)}
+	if err := migrateSave(data, 1, 3, steps); err != nil {
+		t.Fatalf("migrateSave failed: %v", err)
+	}
+	if len(applied) != 2 || applied[0] != 1 || applied[1] != 2 {
+		t.Errorf("Expected migrations 1 then 2, got %v", applied)
+	}
+	if data["version"] != float64(3) || data["turns"] != float64(7) {
+		t.Errorf("Migrated data not updated, got %v", data)
+	}
+}
+
+func TestMigrateSave_MissingStep(t *testing.T) {
+	data := map[string]any{"version": float64(1)}
+	if err := migrateSave(data, 1, 2, map[int]migration{}); err == nil {
+		t.Error("Expected error when no migration is registered")
+	}
+}
+
+func TestDecodeSave_MigratesV1Keys(t *testing.T) {
+	data := `{"version":1,"player":{"location":"A","inventory":[{"name":"key"}]},
+		"rooms":[{"name":"A","items":[{"name":"sword"}]}]}`
+	game, err := DecodeSave([]byte(data))
+	if err != nil {
+		t.Fatalf("DecodeSave failed: %v", err)
+	}
+	if _, ok := game.Player.Inventory[0].Behavior(world.BehaviorKey); !ok {
+		t.Error("Version 1 key should gain the key behavior")
+	}
+	if len(game.AllRooms["A"].Items[0].Behaviors) != 0 {
+		t.Error("Non-key items should not gain behaviors")
+	}
+}
+
+func TestSaveAndLoad_KeepsWorldDetails(t *testing.T) {
+	game := createSimpleLayout()
+	game.Player.Location.Dark = true
+	game.Player.Location.Exits["east"].Obstacle = "rubble"
+	game.Player.Location.Exits["west"].LockText = "A portcullis blocks the passage."
+	game.Player.Location.NPCs = []*world.NPC{{Name: "hermit", Lines: []string{"Hello.", "Goodbye."}, Said: 1, Dialogue: "hermit"}}
+	game.Flags = map[string]bool{"hermit_friend": true}
+	game.Player.Inventory = []*world.Item{{
+		Name:      "torch",
+		Behaviors: []world.Behavior{{Kind: world.BehaviorLight}},
+		Lit:       true,
+	}}
+
+	data, err := EncodeSave(game)
+	if err != nil {
+		t.Fatalf("EncodeSave failed: %v", err)
+	}
+	loaded, err := DecodeSave(data)
+	if err != nil {
+		t.Fatalf("DecodeSave failed: %v", err)
+	}
+	if !loaded.Player.Location.Dark {
+		t.Error("Room darkness should survive a save")
+	}
+	if loaded.Player.Location.Exits["east"].Obstacle != "rubble" {
+		t.Error("Exit obstacles should survive a save")
+	}
+	if loaded.Player.Location.Exits["west"].LockText != "A portcullis blocks the passage." {
+		t.Error("Lock flavor text should survive a save")
+	}
+	if npcs := loaded.Player.Location.NPCs; len(npcs) != 1 || npcs[0].Said != 1 || len(npcs[0].Lines) != 2 || npcs[0].Dialogue != "hermit" {
+		t.Errorf("Characters and their progress should survive a save, got %+v", npcs)
+	}
+	if !loaded.Flags["hermit_friend"] {
+		t.Error("Dialogue flags should survive a save")
+	}
+	torch := loaded.Player.Inventory[0]
+	if _, ok := torch.Behavior(world.BehaviorLight); !ok || !torch.Lit {
+		t.Errorf("Torch behavior and lit state should survive a save, got %+v", torch)
+	}
+}
+
+func TestDecodeSave_MigratesV2KeysToKeyIDs(t *testing.T) {
+	data := `{"version":2,
+		"player":{"location":"A","inventory":[{"name":"key","behaviors":[{"kind":"key","opens":"Treasure Room"}]}]},
+		"rooms":[
+			{"name":"A","exits":[{"direction":"east","room":"Treasure Room","locked":true}]},
+			{"name":"Treasure Room","exits":[{"direction":"west","room":"A"}]}]}`
+	game, err := DecodeSave([]byte(data))
+	if err != nil {
+		t.Fatalf("DecodeSave failed: %v", err)
+	}
+	b, _ := game.Player.Inventory[0].Behavior(world.BehaviorKey)
+	exit := game.AllRooms["A"].Exits["east"]
+	if b.KeyID != "Treasure Room" || exit.KeyID != "Treasure Room" {
+		t.Errorf("Expected key and lock to share an ID, got key %q lock %q", b.KeyID, exit.KeyID)
+	}
+	if msg, _, _ := game.Unlock(); !strings.Contains(msg, "You win!") {
+		t.Errorf("Migrated key should still open its door, got: %s", msg)
+	}
+}
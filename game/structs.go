@@ -1,12 +1,21 @@
 package game
 
-import "text-adventure-v2/world"
+import (
+	"text-adventure-v2/dialogue"
+	"text-adventure-v2/world"
+)
 
 // Game holds the entire state of the game.
 type Game struct {
 	Player       *world.Player
 	AllRooms     map[string]*world.Room
 	IsWon        bool
+	IsLost       bool // the player has died
 	Turns        int
 	VisitedRooms map[string]bool
+	Seed         int64                     // generator seed; replaying it rebuilds the same world
+	SaveDir      string                    // directory for save files; DefaultSaveDir when empty
+	Flags        map[string]bool           // set by dialogue effects
+	Dialogues    map[string]*dialogue.Tree // conversation trees by ID
+	Conversation *Conversation             // the dialogue in progress, if any
 }
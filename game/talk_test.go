@@ -0,0 +1,75 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"text-adventure-v2/world"
+)
+
+func hermit() *world.NPC {
+	return &world.NPC{
+		Name:        "old hermit",
+		Description: "He mutters to himself.",
+		Lines:       []string{"Welcome, traveller.", "The key lies in Room A."},
+	}
+}
+
+func TestTalk_LinesInOrderThenRepeat(t *testing.T) {
+	game := createSimpleLayout()
+	game.Player.Location.NPCs = []*world.NPC{hermit()}
+
+	want := []string{
+		`The old hermit says, "Welcome, traveller."`,
+		`The old hermit says, "The key lies in Room A."`,
+		`The old hermit says, "The key lies in Room A."`,
+	}
+	for i, w := range want {
+		if msg, _ := game.HandleCommand("talk to the hermit"); msg != w {
+			t.Errorf("talk #%d: expected %q, got %q", i+1, w, msg)
+		}
+	}
+	if game.Turns != 3 {
+		t.Errorf("Talking should use a turn, got %d turns", game.Turns)
+	}
+}
+
+func TestTalk_NoNameWithOneCharacter(t *testing.T) {
+	game := createSimpleLayout()
+	game.Player.Location.NPCs = []*world.NPC{hermit()}
+
+	if msg, _ := game.HandleCommand("talk"); !strings.Contains(msg, "Welcome") {
+		t.Errorf("Expected the only character to answer, got: %s", msg)
+	}
+
+	game.Player.Location.NPCs = append(game.Player.Location.NPCs, &world.NPC{Name: "ghost"})
+	if msg, _ := game.HandleCommand("talk"); msg != "Who do you want to talk to?" {
+		t.Errorf("Expected to be asked who, got: %s", msg)
+	}
+	if msg, _ := game.HandleCommand("speak to ghost"); msg != "The ghost has nothing to say." {
+		t.Errorf("Expected the ghost to stay silent, got: %s", msg)
+	}
+}
+
+func TestTalk_Nobody(t *testing.T) {
+	game := createSimpleLayout()
+	if msg, _ := game.HandleCommand("talk to hermit"); msg != "There is nobody here to talk to." {
+		t.Errorf("Expected nobody message, got: %s", msg)
+	}
+	game.Player.Location.NPCs = []*world.NPC{hermit()}
+	if msg, _ := game.HandleCommand("talk to guard"); msg != "There is nobody here by that name." {
+		t.Errorf("Expected unknown-name message, got: %s", msg)
+	}
+}
+
+func TestLook_ShowsCharacters(t *testing.T) {
+	game := createSimpleLayout()
+	game.Player.Location.NPCs = []*world.NPC{hermit()}
+
+	if msg := game.Look(); !strings.Contains(msg, "An old hermit is here. He mutters to himself.") {
+		t.Errorf("Expected Look to show the hermit, got: %s", msg)
+	}
+	game.Player.Location.Dark = true
+	if msg := game.Look(); strings.Contains(msg, "hermit") {
+		t.Errorf("Characters should be hidden in the dark, got: %s", msg)
+	}
+}
@@ -72,7 +72,7 @@ func createLayoutWithLock() *Game {
 		Name:        "Room A",
 		Description: "This is Room A.",
 		Exits:       make(map[string]*world.Exit),
-		Items:       []*world.Item{{Name: "key", Description: "A test key."}},
+		Items:       []*world.Item{testKey()},
 	}
 	roomB := &world.Room{Name: "Room B", Description: "This is Room B.", Exits: make(map[string]*world.Exit)}
 	roomC := &world.Room{Name: "Room C", Description: "This is Room C.", Exits: make(map[string]*world.Exit)}
@@ -107,7 +107,7 @@ func createLayoutWithWinCondition() *Game {
 		Name:        "Room A",
 		Description: "This is Room A.",
 		Exits:       make(map[string]*world.Exit),
-		Items:       []*world.Item{{Name: "key", Description: "A test key."}},
+		Items:       []*world.Item{testKey()},
 	}
 	roomB := &world.Room{Name: "Room B", Description: "This is Room B.", Exits: make(map[string]*world.Exit)}
 	treasureRoom := &world.Room{Name: "Treasure Room", Description: "The treasure is here!", Exits: make(map[string]*world.Exit)}
@@ -134,3 +134,12 @@ func createLayoutWithWinCondition() *Game {
 		VisitedRooms: map[string]bool{roomB.Name: true},
 	}
 }
+
+// testKey returns a key that fits any locked door.
+func testKey() *world.Item {
+	return &world.Item{
+		Name:        "key",
+		Description: "A test key.",
+		Behaviors:   []world.Behavior{{Kind: world.BehaviorKey}},
+	}
+}
@@ -0,0 +1,150 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"text-adventure-v2/world"
+)
+
+// Use applies an item from the inventory, optionally to a target such as a
+// door ("use key on north door"). The item's behaviors decide what happens;
+// the first match wins in the order key, tool, light, readable, consumable.
+func (g *Game) Use(itemName, target string) (string, bool, bool) {
+	if itemName == "" {
+		return "What do you want to use?", false, false
+	}
+	i, msg := resolveItem(itemName, g.Player.Inventory, "You don't have that.")
+	if i < 0 {
+		return msg, false, false
+	}
+	item := g.Player.Inventory[i]
+
+	if _, ok := item.Behavior(world.BehaviorKey); ok {
+		return g.UnlockWith(item, directionIn(target))
+	}
+	if _, ok := item.Behavior(world.BehaviorTool); ok {
+		msg, success := g.Clear(item, directionIn(target))
+		return msg, success, false
+	}
+	if _, ok := item.Behavior(world.BehaviorLight); ok {
+		item.Lit = !item.Lit
+		if item.Lit {
+			return "You light the " + item.Name + ".", true, false
+		}
+		return "You put out the " + item.Name + ".", true, false
+	}
+	if _, ok := item.Behavior(world.BehaviorReadable); ok {
+		msg, success := g.Read(item.Name)
+		return msg, success, false
+	}
+	if b, ok := item.Behavior(world.BehaviorConsumable); ok {
+		g.removeFromInventory(item)
+		msg := "You use up the " + item.Name + "."
+		if b.Text != "" {
+			msg = b.Text
+		}
+		if b.HP > 0 {
+			if healed := g.heal(b.HP); healed > 0 {
+				msg += fmt.Sprintf(" You regain %d health.", healed)
+			} else {
+				msg += " You were already at full health."
+			}
+		}
+		return msg, true, false
+	}
+	return "You can't think of a way to use the " + item.Name + ".", false, false
+}
+
+// Clear uses a tool on an obstacle blocking an exit. If dir is empty every
+// blocked exit in the room is tried in order. Consumable tools are used up.
+func (g *Game) Clear(tool *world.Item, dir string) (string, bool) {
+	b, _ := tool.Behavior(world.BehaviorTool)
+	var blocked []*world.Exit
+	for _, d := range sortedExitDirs(g.Player.Location) {
+		exit := g.Player.Location.Exits[d]
+		if exit.Obstacle != "" && (dir == "" || dir == d) {
+			blocked = append(blocked, exit)
+		}
+	}
+	if len(blocked) == 0 {
+		if dir != "" {
+			return "Nothing blocks the way " + dir + ".", false
+		}
+		return "There is nothing here to clear.", false
+	}
+
+	for _, exit := range blocked {
+		if exit.Obstacle != b.KeyID {
+			continue
+		}
+		msg := "You clear the " + exit.Obstacle + " with the " + tool.Name + "."
+		exit.Obstacle = ""
+		if _, ok := tool.Behavior(world.BehaviorConsumable); ok && g.removeFromInventory(tool) {
+			msg += "\nThe " + tool.Name + " is used up."
+		}
+		return msg, true
+	}
+	return "The " + tool.Name + " is no use against the " + blocked[0].Obstacle + ".", false
+}
+
+// Read shows the writing on a readable item. Readable items that are also
+// consumable crumble away once read.
+func (g *Game) Read(itemName string) (string, bool) {
+	if itemName == "" {
+		return "What do you want to read?", false
+	}
+	if !g.canSee() {
+		return "It is too dark to read.", false
+	}
+	item, msg := g.resolveHeldOrNearby(itemName)
+	if item == nil {
+		return msg, false
+	}
+	b, ok := item.Behavior(world.BehaviorReadable)
+	if !ok {
+		return "There is nothing written on the " + item.Name + ".", false
+	}
+	text := b.Text
+	if _, ok := item.Behavior(world.BehaviorConsumable); ok && g.removeFromInventory(item) {
+		text += "\nThe " + item.Name + " crumbles to dust."
+	}
+	return text, true
+}
+
+// canSee reports whether the player can see in the current room: either the
+// room is not dark, or a lit light source is carried or lying here.
+func (g *Game) canSee() bool {
+	if !g.Player.Location.Dark {
+		return true
+	}
+	for _, items := range [][]*world.Item{g.Player.Inventory, g.Player.Location.Items} {
+		for _, item := range items {
+			if _, ok := item.Behavior(world.BehaviorLight); ok && item.Lit {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removeFromInventory removes item from the inventory, reporting whether it was held.
+func (g *Game) removeFromInventory(item *world.Item) bool {
+	for i, held := range g.Player.Inventory {
+		if held == item {
+			g.Player.Inventory = append(g.Player.Inventory[:i], g.Player.Inventory[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// directionIn returns the first compass direction named in a phrase such as
+// "north door", or "" if there is none.
+func directionIn(phrase string) string {
+	for _, word := range strings.Fields(phrase) {
+		if dir, ok := directions[word]; ok {
+			return dir
+		}
+	}
+	return ""
+}
@@ -0,0 +1,249 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"text-adventure-v2/generator"
+	"text-adventure-v2/world"
+)
+
+func TestUse_KeyOnDoor(t *testing.T) {
+	game := createLayoutWithLock()
+	game.Player.Inventory = []*world.Item{testKey()}
+
+	msg, _ := game.HandleCommand("use key on west door")
+	if msg != "There is no locked door to the west." {
+		t.Errorf("Expected no-door message, got: %s", msg)
+	}
+
+	msg, _ = game.HandleCommand("use the key on the east door")
+	if msg != "You unlocked the door." {
+		t.Errorf("Expected unlock message, got: %s", msg)
+	}
+	if game.Turns != 1 {
+		t.Errorf("Successful use should increment turns, got %d", game.Turns)
+	}
+}
+
+func TestUse_KeyForAnotherDoor(t *testing.T) {
+	game := createLayoutWithLock()
+	game.AllRooms["Room B"].Exits["east"].KeyID = "iron"
+	game.Player.Inventory = []*world.Item{{
+		Name:      "brass key",
+		Behaviors: []world.Behavior{{Kind: world.BehaviorKey, KeyID: "brass"}},
+	}}
+
+	msg, success, _ := game.Unlock()
+	if success || msg != "You don't have the key." {
+		t.Errorf("Key for another door should not fit, got: %s", msg)
+	}
+	msg, _ = game.HandleCommand("use brass key")
+	if msg != "The brass key doesn't fit the lock." {
+		t.Errorf("Expected doesn't-fit message, got: %s", msg)
+	}
+}
+
+func TestUse_LightInDarkRoom(t *testing.T) {
+	game := createLayoutWithItems()
+	roomA := game.AllRooms["Room A"]
+	roomA.Dark = true
+	game.Player.Inventory = []*world.Item{{
+		Name:      "torch",
+		Behaviors: []world.Behavior{{Kind: world.BehaviorLight}},
+	}}
+	game.HandleCommand("go west")
+
+	if msg := game.Look(); !strings.Contains(msg, "pitch dark") || strings.Contains(msg, "test_item") {
+		t.Errorf("Dark room should hide its items, got: %s", msg)
+	}
+	if msg, _ := game.HandleCommand("take test_item"); msg != "It is too dark to find anything." {
+		t.Errorf("Expected darkness to block take, got: %s", msg)
+	}
+
+	if msg, _ := game.HandleCommand("use torch"); msg != "You light the torch." {
+		t.Errorf("Expected torch to light, got: %s", msg)
+	}
+	if msg := game.Look(); !strings.Contains(msg, "This is Room A.") || !strings.Contains(msg, "test_item") {
+		t.Errorf("Lit torch should reveal the room, got: %s", msg)
+	}
+	if msg, _ := game.HandleCommand("take test_item"); msg != "You took the test_item." {
+		t.Errorf("Expected take to work with light, got: %s", msg)
+	}
+
+	if msg, _ := game.HandleCommand("use torch"); msg != "You put out the torch." {
+		t.Errorf("Expected torch to go out, got: %s", msg)
+	}
+}
+
+func TestRead_Readable(t *testing.T) {
+	game := createSimpleLayout()
+	game.Player.Location.Items = []*world.Item{{
+		Name:      "note",
+		Behaviors: []world.Behavior{{Kind: world.BehaviorReadable, Text: "Beware the cellar."}},
+	}}
+
+	msg, _ := game.HandleCommand("read the note")
+	if msg != "Beware the cellar." {
+		t.Errorf("Expected note text, got: %s", msg)
+	}
+	if game.Turns != 0 {
+		t.Error("Reading should not increment turns")
+	}
+}
+
+func TestUse_ReadableConsumable(t *testing.T) {
+	game := createSimpleLayout()
+	game.Player.Inventory = []*world.Item{{
+		Name: "scroll",
+		Behaviors: []world.Behavior{
+			{Kind: world.BehaviorReadable, Text: "The key is west."},
+			{Kind: world.BehaviorConsumable},
+		},
+	}}
+
+	msg, _ := game.HandleCommand("use scroll")
+	if !strings.Contains(msg, "The key is west.") || !strings.Contains(msg, "crumbles") {
+		t.Errorf("Expected text then crumble, got: %s", msg)
+	}
+	if len(game.Player.Inventory) != 0 {
+		t.Error("Consumable scroll should be gone after reading")
+	}
+}
+
+func TestUse_Consumable(t *testing.T) {
+	game := createSimpleLayout()
+	game.Player.Inventory = []*world.Item{{
+		Name:      "apple",
+		Behaviors: []world.Behavior{{Kind: world.BehaviorConsumable, Text: "Crunchy."}},
+	}}
+
+	msg, _ := game.HandleCommand("use apple")
+	if msg != "Crunchy." {
+		t.Errorf("Expected consumable message, got: %s", msg)
+	}
+	if len(game.Player.Inventory) != 0 {
+		t.Error("Consumable should be removed after use")
+	}
+	if game.Turns != 1 {
+		t.Errorf("Successful use should increment turns, got %d", game.Turns)
+	}
+}
+
+func TestUse_NoBehavior(t *testing.T) {
+	game := createSimpleLayout()
+	game.Player.Inventory = []*world.Item{{Name: "sword"}}
+
+	msg, _ := game.HandleCommand("use sword")
+	if msg != "You can't think of a way to use the sword." {
+		t.Errorf("Expected no-use message, got: %s", msg)
+	}
+	if msg, _ := game.HandleCommand("use shield"); msg != "You don't have that." {
+		t.Errorf("Expected not-held message, got: %s", msg)
+	}
+	if game.Turns != 0 {
+		t.Error("Failed use should not increment turns")
+	}
+}
+
+func TestUse_ToolClearsObstacle(t *testing.T) {
+	game := createSimpleLayout()
+	game.AllRooms["Room B"].Exits["east"].Obstacle = "rubble"
+	game.Player.Inventory = []*world.Item{
+		{Name: "machete", Behaviors: []world.Behavior{{Kind: world.BehaviorTool, KeyID: "vines"}}},
+		{Name: "pickaxe", Behaviors: []world.Behavior{{Kind: world.BehaviorTool, KeyID: "rubble"}}},
+	}
+
+	msg, _ := game.HandleCommand("go east")
+	if msg != "The way east is blocked by rubble." {
+		t.Errorf("Expected blocked message, got: %s", msg)
+	}
+	if !strings.Contains(game.Look(), "- east (blocked by rubble)") {
+		t.Errorf("Look should mention the obstacle, got: %s", game.Look())
+	}
+
+	msg, _ = game.HandleCommand("use machete on rubble")
+	if msg != "The machete is no use against the rubble." {
+		t.Errorf("Expected wrong-tool message, got: %s", msg)
+	}
+	msg, _ = game.HandleCommand("use pickaxe on east")
+	if msg != "You clear the rubble with the pickaxe." {
+		t.Errorf("Expected clear message, got: %s", msg)
+	}
+	if msg, _ := game.HandleCommand("go east"); msg != "" || game.Player.Location.Name != "Room C" {
+		t.Errorf("Expected to walk east after clearing, got: %q", msg)
+	}
+}
+
+func TestUse_ConsumableToolIsUsedUp(t *testing.T) {
+	game := createSimpleLayout()
+	game.AllRooms["Room B"].Exits["east"].Obstacle = "rusted gate"
+	game.Player.Inventory = []*world.Item{{
+		Name: "oil flask",
+		Behaviors: []world.Behavior{
+			{Kind: world.BehaviorTool, KeyID: "rusted gate"},
+			{Kind: world.BehaviorConsumable},
+		},
+	}}
+
+	msg, _ := game.HandleCommand("use oil")
+	if !strings.Contains(msg, "The oil flask is used up.") {
+		t.Errorf("Expected the flask to be used up, got: %s", msg)
+	}
+	if len(game.Player.Inventory) != 0 {
+		t.Errorf("Used-up tool should leave the inventory, got %v", game.Player.Inventory)
+	}
+}
+
+func TestDrop_InDark(t *testing.T) {
+	game := createLayoutWithItems()
+	game.AllRooms["Room A"].Dark = true
+	game.Player.Inventory = []*world.Item{
+		{Name: "torch", Behaviors: []world.Behavior{{Kind: world.BehaviorLight}}},
+		testKey(),
+	}
+	game.HandleCommand("go west")
+
+	if msg, ok := game.HandleCommand("drop torch"); ok || msg != "It is too dark to see where it would fall." {
+		t.Errorf("Expected darkness to block drop, got: %s", msg)
+	}
+	if len(game.Player.Inventory) != 2 {
+		t.Fatalf("Nothing should be dropped in the dark, inventory: %v", game.Player.Inventory)
+	}
+
+	game.HandleCommand("use torch")
+	if msg, _ := game.HandleCommand("drop key"); msg != "You dropped the key." {
+		t.Errorf("Expected drop to work with light, got: %s", msg)
+	}
+	if msg, _ := game.HandleCommand("take key"); msg != "You took the key." {
+		t.Errorf("Expected the dropped key to be found again, got: %s", msg)
+	}
+}
+
+// TestSolve_DropsInDarkRooms replays the solver's solution for a world whose key
+// lies in a dark room, dropping and taking back every held item before each
+// step. Solve leaves drops out of its search, so none may strand the player.
+func TestSolve_DropsInDarkRooms(t *testing.T) {
+	game := createLayoutWithWinCondition()
+	game.AllRooms["Room A"].Dark = true
+	game.Player.Location.Items = []*world.Item{{Name: "torch", Behaviors: []world.Behavior{{Kind: world.BehaviorLight}}}}
+	treasure := game.AllRooms["Treasure Room"]
+	treasure.Items = append(treasure.Items, &world.Item{Name: "treasure"})
+
+	solution, err := generator.Solve(game.Player.Location, game.AllRooms)
+	if err != nil {
+		t.Fatalf("Expected a solution, got error: %v", err)
+	}
+	for _, step := range solution.Steps {
+		for _, item := range append([]*world.Item(nil), game.Player.Inventory...) {
+			if _, dropped := game.Drop(item.Name); dropped {
+				if msg, ok := game.Take(item.Name); !ok {
+					t.Fatalf("Dropped the %s before %q and could not take it back: %s", item.Name, step, msg)
+				}
+			}
+		}
+		game.HandleCommand(strings.Replace(step, "light ", "use ", 1))
+	}
+	if !game.IsWon {
+		t.Errorf("Expected %v to win the game", solution.Steps)
+	}
+}
@@ -7,15 +7,16 @@ import (
 )
 
 // buildWorld creates the raw structure of the world (rooms and their connections).
-func buildWorld(config Config) (*world.Room, map[string]*world.Room, error) {
+// Rooms are tracked in creation order so that every random pick depends only on rng.
+func buildWorld(config Config, rng *rand.Rand) (*world.Room, map[string]*world.Room, error) {
 
 	if len(config.RoomNamePool) < config.NumberOfRooms {
 		return nil, nil, errors.New("not enough unique room names in the pool for the number of rooms requested")
 	}
 
 	startRoom := &world.Room{
-		Name:        "Starting Room",
-		Description: "You find yourself in a plain room with a single, sturdy door.",
+		Name:        config.StartRoomName,
+		Description: config.StartRoomDesc,
 		Exits:       make(map[string]*world.Exit),
 		Items:       make([]*world.Item, 0),
 		X:           0,
@@ -24,6 +25,7 @@ func buildWorld(config Config) (*world.Room, map[string]*world.Room, error) {
 
 	allRooms := make(map[string]*world.Room)
 	allRooms[startRoom.Name] = startRoom
+	roomList := []*world.Room{startRoom}
 
 	grid := make(map[int]map[int]*world.Room)
 	grid[0] = make(map[int]*world.Room)
@@ -31,21 +33,17 @@ func buildWorld(config Config) (*world.Room, map[string]*world.Room, error) {
 
 	roomNamePool := make([]string, len(config.RoomNamePool))
 	copy(roomNamePool, config.RoomNamePool)
-	rand.Shuffle(len(roomNamePool), func(i, j int) { roomNamePool[i], roomNamePool[j] = roomNamePool[j], roomNamePool[i] })
+	rng.Shuffle(len(roomNamePool), func(i, j int) { roomNamePool[i], roomNamePool[j] = roomNamePool[j], roomNamePool[i] })
 
 	for i := 1; i < config.NumberOfRooms; i++ {
 		var created bool
 		for !created {
 			// Pick a random existing room to branch off from
-			roomList := make([]*world.Room, 0, len(allRooms))
-			for _, r := range allRooms {
-				roomList = append(roomList, r)
-			}
-			randomRoom := roomList[rand.Intn(len(roomList))]
+			randomRoom := roomList[rng.Intn(len(roomList))]
 
 			// Pick a random direction
 			dirs := []string{"north", "south", "east", "west"}
-			dir := dirs[rand.Intn(len(dirs))]
+			dir := dirs[rng.Intn(len(dirs))]
 
 			dx, dy := 0, 0
 			var oppositeDir string
@@ -70,7 +68,7 @@ func buildWorld(config Config) (*world.Room, map[string]*world.Room, error) {
 			if _, exists := grid[newX][newY]; !exists {
 				// Create new room
 				newName := roomNamePool[i-1]
-				newDesc := config.RoomDescPool[rand.Intn(len(config.RoomDescPool))]
+				newDesc := config.RoomDescPool[rng.Intn(len(config.RoomDescPool))]
 				newRoom := &world.Room{
 					Name:        newName,
 					Description: newDesc,
@@ -86,6 +84,7 @@ func buildWorld(config Config) (*world.Room, map[string]*world.Room, error) {
 
 				// Add to collections
 				allRooms[newRoom.Name] = newRoom
+				roomList = append(roomList, newRoom)
 				if grid[newX] == nil {
 					grid[newX] = make(map[int]*world.Room)
 				}
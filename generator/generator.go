@@ -2,65 +2,139 @@ package generator
 
 import (
 	"fmt"
+	"math/rand"
 	"text-adventure-v2/world"
+	"time"
 )
 
 // Config holds the parameters for map generation.
 type Config struct {
 	NumberOfRooms     int
 	MinPathToTreasure int
-	ExtraItems        []string
-	RoomNamePool      []string
-	RoomDescPool      []string
+	NumberOfItems     int // extra items drawn from ItemPool
+	DarkRooms         int // rooms that need a light source to search
+	NumberOfLocks     int // locked doors chained along the critical path
+	NumberOfObstacles int // exits blocked by an obstacle that takes a tool to clear
+	NumberOfNPCs      int // characters who give hints about the generated world
+	NumberOfFights    int // enemies on the critical path, which must be beaten to win
+	NumberOfGuards    int // enemies guarding side branches, which can be left alone
+	NumberOfTraps     int // rooms that hurt the player the first time they enter
+
+	// Text and pools, normally filled in from a Theme.
+	Theme            string // theme name, used in error messages
+	StartRoomName    string
+	StartRoomDesc    string
+	TreasureRoomDesc string
+	TreasureDesc     string
+	RoomNamePool     []string
+	RoomDescPool     []string
+	ItemPool         []ItemText
+	KeyNamePool      []string
+	LockTextPool     []string // flavor text for locked doors; optional
+	ObstaclePool     []Obstacle
+	NPCPool          []NPCText
+	EnemyPool        []EnemyText
+	TrapPool         []TrapText
+
+	// Seed drives every random choice made during generation. The same seed and
+	// config always produce the same rooms, exits, locks and item placement.
+	Seed int64
+	// Source, if non-nil, is used instead of a source built from Seed.
+	Source rand.Source
 }
 
-// DefaultConfig provides sensible starting values for map generation.
+// PlayerHP is the health the player starts with. Fights and traps are checked
+// against it: the solver only passes a hazard the player can survive.
+const PlayerHP = 5
+
+// Obstacle is something that can block an exit, and the tool that clears it.
+type Obstacle struct {
+	Name   string `json:"name"`              // e.g. "rubble"; also the tool's KeyID
+	Tool   string `json:"tool"`              // the item that clears it, e.g. "pickaxe"
+	UsedUp bool   `json:"used_up,omitempty"` // whether clearing the obstacle uses the tool up
+}
+
+// DefaultConfig provides sensible starting values for map generation, dressed
+// in DefaultTheme.
 func DefaultConfig() Config {
-	return Config{
+	theme, err := LoadTheme(DefaultTheme)
+	if err != nil {
+		// The default theme is embedded; failing to load it is a build error.
+		panic(err)
+	}
+	return theme.Apply(Config{
+		Seed:              time.Now().UnixNano(),
 		NumberOfRooms:     10,
 		MinPathToTreasure: 4,
-		ExtraItems:        []string{"sword"},
-		RoomNamePool: []string{
-			"Dank Cellar",
-			"Dusty Armory",
-			"Forgotten Library",
-			"Echoing Cavern",
-			"Drafty Corridor",
-			"Sunken Grotto",
-			"Crystal Chamber",
-			"Shadowy Antechamber",
-			"Musty Crawlspace",
-			"Alchemist's Laboratory",
-		},
-		RoomDescPool: []string{
-			"You are in a small, damp room. A faint dripping sound echoes from a dark corner.",
-			"The air is thick with the smell of old books and decaying paper. Shelves line the walls.",
-			"A single torch flickers, casting long, dancing shadows across the cold stone floor.",
-			"The ground is uneven and slick with moisture. Strange fungi glow with a soft, eerie light.",
-			"You can feel a cold breeze, though you can't identify its source.",
-			"This room is surprisingly ornate, with faded tapestries hanging on the walls.",
-			"An old suit of armor stands in the corner, its helmet staring at you blankly.",
-			"The ceiling is unusually high here, lost in the oppressive darkness above.",
-		},
+		NumberOfItems:     1,
+		DarkRooms:         1,
+		NumberOfLocks:     2,
+		NumberOfObstacles: 1,
+		NumberOfNPCs:      1,
+		NumberOfFights:    1,
+		NumberOfGuards:    1,
+		NumberOfTraps:     1,
+	})
+}
+
+// Validate checks that the config's pools are big enough for the sizes it
+// asks for, so a theme that is too small fails with a clear error instead of
+// exhausting Generate's retries.
+func (c Config) Validate() error {
+	source := "config"
+	if c.Theme != "" {
+		source = fmt.Sprintf("theme %q", c.Theme)
+	}
+	switch {
+	case c.StartRoomName == "":
+		return fmt.Errorf("%s has no start room name", source)
+	case len(c.RoomNamePool) < c.NumberOfRooms:
+		return fmt.Errorf("%s has %d room names, but NumberOfRooms is %d", source, len(c.RoomNamePool), c.NumberOfRooms)
+	case len(c.RoomDescPool) == 0:
+		return fmt.Errorf("%s has no room descriptions", source)
+	case len(c.ItemPool) < c.NumberOfItems:
+		return fmt.Errorf("%s has %d items, but NumberOfItems is %d", source, len(c.ItemPool), c.NumberOfItems)
+	case len(c.KeyNamePool) < max(1, c.NumberOfLocks):
+		return fmt.Errorf("%s has %d key names, but NumberOfLocks is %d", source, len(c.KeyNamePool), c.NumberOfLocks)
+	case len(c.ObstaclePool) < c.NumberOfObstacles:
+		return fmt.Errorf("%s has %d obstacles, but NumberOfObstacles is %d", source, len(c.ObstaclePool), c.NumberOfObstacles)
+	case len(c.NPCPool) < c.NumberOfNPCs:
+		return fmt.Errorf("%s has %d characters, but NumberOfNPCs is %d", source, len(c.NPCPool), c.NumberOfNPCs)
+	case len(c.EnemyPool) == 0 && c.NumberOfFights+c.NumberOfGuards > 0:
+		return fmt.Errorf("%s has no enemies, but NumberOfFights and NumberOfGuards ask for %d", source, c.NumberOfFights+c.NumberOfGuards)
+	case len(c.TrapPool) == 0 && c.NumberOfTraps > 0:
+		return fmt.Errorf("%s has no traps, but NumberOfTraps is %d", source, c.NumberOfTraps)
 	}
+	return nil
 }
 
 // Generate orchestrates the creation of a new, random, and solvable game world.
 func Generate(config Config) (*world.Room, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	var err error
 	const maxRetries = 10
 
+	// One RNG for the whole run, including retries, so a seed maps to exactly one world.
+	src := config.Source
+	if src == nil {
+		src = rand.NewSource(config.Seed)
+	}
+	rng := rand.New(src)
+
 	for i := 0; i < maxRetries; i++ {
 		// Step 1: Build the raw world structure.
 		var startRoom *world.Room
 		var allRooms map[string]*world.Room
-		startRoom, allRooms, err = buildWorld(config)
+		startRoom, allRooms, err = buildWorld(config, rng)
 		if err != nil {
 			continue // Should be rare, but retry if it happens
 		}
 
 		// Step 2: Place the puzzles and extra items.
-		err = placePuzzles(config, startRoom, allRooms)
+		err = placePuzzles(config, rng, startRoom, allRooms)
 		if err != nil {
 			continue // This can fail if the map is too simple, so we retry
 		}
@@ -1,7 +1,12 @@
 package generator
 
 import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
 	"testing"
+	"text-adventure-v2/world"
 )
 
 // TestGenerate_Success ensures that the generator can produce a valid world without errors.
@@ -30,3 +35,149 @@ func TestGenerate_ConfigValidation(t *testing.T) {
 		t.Fatal("Generate() should have failed with an impossible config, but it did not.")
 	}
 }
+
+// TestGenerate_TooManyItems ensures that asking for more items than there are
+// empty rooms fails instead of searching for a room forever.
+func TestGenerate_TooManyItems(t *testing.T) {
+	config := DefaultConfig()
+	for len(config.ItemPool) < config.NumberOfRooms {
+		config.ItemPool = append(config.ItemPool, config.ItemPool...)
+	}
+	config.NumberOfItems = config.NumberOfRooms
+
+	_, err := Generate(config)
+	if err == nil || !strings.Contains(err.Error(), "NumberOfItems") {
+		t.Fatalf("Generate() should have failed for lack of rooms, got: %v", err)
+	}
+}
+
+// TestGenerate_SameSeedSameWorld ensures a seed always reproduces the identical world.
+func TestGenerate_SameSeedSameWorld(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		config := DefaultConfig()
+		config.Seed = seed
+
+		first, err := Generate(config)
+		if err != nil {
+			t.Fatalf("Generate() failed for seed %d: %v", seed, err)
+		}
+		second, err := Generate(config)
+		if err != nil {
+			t.Fatalf("Generate() failed for seed %d: %v", seed, err)
+		}
+
+		if a, b := dumpWorld(first), dumpWorld(second); a != b {
+			t.Fatalf("seed %d produced different worlds:\n%s\n---\n%s", seed, a, b)
+		}
+	}
+}
+
+// TestGenerate_InjectedSource ensures Source takes precedence over Seed.
+func TestGenerate_InjectedSource(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = 1
+	config.Source = rand.NewSource(42)
+	fromSource, err := Generate(config)
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	config.Source = nil
+	config.Seed = 42
+	fromSeed, err := Generate(config)
+	if err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+
+	if dumpWorld(fromSource) != dumpWorld(fromSeed) {
+		t.Error("Source(42) and Seed 42 should generate the same world")
+	}
+}
+
+// TestGenerate_ObstaclesSolvable ensures generated worlds with obstacles have
+// a shortest solution that opens every lock on the way.
+func TestGenerate_ObstaclesSolvable(t *testing.T) {
+	for seed := int64(1); seed <= 30; seed++ {
+		config := DefaultConfig()
+		config.Seed = seed
+		start, err := Generate(config)
+		if err != nil {
+			t.Fatalf("Generate() failed for seed %d: %v", seed, err)
+		}
+		allRooms := collectRooms(start)
+
+		obstacles := 0
+		for _, room := range allRooms {
+			for _, exit := range room.Exits {
+				if exit.Obstacle != "" {
+					obstacles++
+				}
+			}
+		}
+		if obstacles != config.NumberOfObstacles {
+			t.Errorf("seed %d: expected %d obstacles, got %d", seed, config.NumberOfObstacles, obstacles)
+		}
+
+		solution, err := Solve(start, allRooms)
+		if err != nil {
+			t.Fatalf("seed %d: generated world should be solvable, got: %v", seed, err)
+		}
+		unlocks := 0
+		for _, step := range solution.Steps {
+			if strings.HasPrefix(step, "unlock") {
+				unlocks++
+			}
+		}
+		if unlocks != config.NumberOfLocks {
+			t.Errorf("seed %d: expected the solution to open %d locks, got %v", seed, config.NumberOfLocks, solution.Steps)
+		}
+	}
+}
+
+// collectRooms gathers every room reachable from start, keyed by name.
+func collectRooms(start *world.Room) map[string]*world.Room {
+	rooms := make(map[string]*world.Room)
+	var walk func(r *world.Room)
+	walk = func(r *world.Room) {
+		if _, ok := rooms[r.Name]; ok {
+			return
+		}
+		rooms[r.Name] = r
+		for _, exit := range r.Exits {
+			walk(exit.Room)
+		}
+	}
+	walk(start)
+	return rooms
+}
+
+// dumpWorld renders every room, exit, lock, item, enemy, trap and character in a
+// stable order.
+func dumpWorld(start *world.Room) string {
+	var lines []string
+	for _, r := range collectRooms(start) {
+		line := fmt.Sprintf("%s (%d,%d) %q", r.Name, r.X, r.Y, r.Description)
+		for _, dir := range sortedDirs(r.Exits) {
+			exit := r.Exits[dir]
+			line += fmt.Sprintf(" %s->%s locked=%v key=%q obstacle=%q", dir, exit.Room.Name, exit.Locked, exit.KeyID, exit.Obstacle)
+		}
+		for _, item := range r.Items {
+			line += " item:" + item.Name
+		}
+		if e := r.Enemy; e != nil {
+			line += fmt.Sprintf(" enemy:%s hp=%d damage=%d %s", e.Name, e.HP, e.Damage, e.Profile)
+			for _, item := range e.Loot {
+				line += " loot:" + item.Name
+			}
+		}
+		if trap := r.Trap; trap != nil {
+			line += fmt.Sprintf(" trap:%q damage=%d", trap.Text, trap.Damage)
+		}
+		for _, npc := range r.NPCs {
+			line += " npc:" + npc.Name + " " + strings.Join(npc.Lines, "|")
+		}
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
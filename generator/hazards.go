@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"errors"
+	"math/rand"
+	"text-adventure-v2/world"
+)
+
+// placeEnemies puts config.NumberOfFights enemies on the critical path, where
+// the player has to beat them to reach the treasure, and config.NumberOfGuards
+// at the mouths of side branches, where they guard whatever lies beyond but
+// can be left alone. Enemies never wait in the start or treasure room, and at
+// most one waits in any room. Loot is rolled here, so a seed fixes it.
+func placeEnemies(config Config, rng *rand.Rand, path []*world.Room, allRooms map[string]*world.Room) error {
+	if config.NumberOfFights+config.NumberOfGuards == 0 {
+		return nil
+	}
+
+	// Mandatory fights: the rooms between the start and the treasure room.
+	onPath := make(map[*world.Room]bool, len(path))
+	for _, room := range path {
+		onPath[room] = true
+	}
+	inner := path[1 : len(path)-1]
+	if config.NumberOfFights > len(inner) {
+		return errors.New("path is too short for NumberOfFights")
+	}
+	for _, i := range rng.Perm(len(inner))[:config.NumberOfFights] {
+		inner[i].Enemy = newEnemy(config, rng)
+	}
+
+	// Optional fights: side rooms one step off the path, so the enemy guards
+	// the whole branch behind it.
+	var mouths []*world.Room
+	for _, room := range sortedRooms(allRooms) {
+		if onPath[room] {
+			continue
+		}
+		for _, dir := range sortedDirs(room.Exits) {
+			if neighbour := room.Exits[dir].Room; onPath[neighbour] {
+				mouths = append(mouths, room)
+				break
+			}
+		}
+	}
+	if config.NumberOfGuards > len(mouths) {
+		return errors.New("not enough side branches for NumberOfGuards")
+	}
+	for _, i := range rng.Perm(len(mouths))[:config.NumberOfGuards] {
+		mouths[i].Enemy = newEnemy(config, rng)
+	}
+	return nil
+}
+
+// newEnemy makes an enemy from a random entry in the pool and rolls its loot.
+func newEnemy(config Config, rng *rand.Rand) *world.Enemy {
+	text := config.EnemyPool[rng.Intn(len(config.EnemyPool))]
+	enemy := &world.Enemy{
+		Name:        text.Name,
+		Description: text.Description,
+		HP:          text.HP,
+		Damage:      text.Damage,
+		Profile:     text.Profile,
+	}
+	for _, loot := range text.Loot {
+		if rng.Float64() < loot.Chance {
+			enemy.Loot = append(enemy.Loot, &world.Item{Name: loot.Name, Description: loot.Description})
+		}
+	}
+	return enemy
+}
+
+// placeTraps sets config.NumberOfTraps traps from the pool in rooms other than
+// the start and treasure rooms and rooms with an enemy, so a room holds at
+// most one hazard.
+func placeTraps(config Config, rng *rand.Rand, startRoom, treasureRoom *world.Room, allRooms map[string]*world.Room) error {
+	if config.NumberOfTraps == 0 {
+		return nil
+	}
+	var candidates []*world.Room
+	for _, room := range sortedRooms(allRooms) {
+		if room != startRoom && room != treasureRoom && room.Enemy == nil {
+			candidates = append(candidates, room)
+		}
+	}
+	if config.NumberOfTraps > len(candidates) {
+		return errors.New("not enough rooms for NumberOfTraps")
+	}
+	for _, i := range rng.Perm(len(candidates))[:config.NumberOfTraps] {
+		text := config.TrapPool[rng.Intn(len(config.TrapPool))]
+		candidates[i].Trap = &world.Trap{Text: text.Text, Damage: text.Damage}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"math/rand"
+	"testing"
+	"text-adventure-v2/world"
+)
+
+func enemyConfig(fights, guards int) Config {
+	return Config{
+		NumberOfFights: fights,
+		NumberOfGuards: guards,
+		EnemyPool: []EnemyText{{Name: "goblin", HP: 3, Damage: 1, Profile: world.ProfileAggressive,
+			Loot: []LootText{{Name: "dagger", Chance: 1}}}},
+	}
+}
+
+func TestPlaceEnemies_FightsOnPathGuardsOffIt(t *testing.T) {
+	start, allRooms := buildBranchedRooms()
+	path := []*world.Room{start, allRooms["B"], allRooms["C"], allRooms["D"]}
+
+	if err := placeEnemies(enemyConfig(2, 2), rand.New(rand.NewSource(1)), path, allRooms); err != nil {
+		t.Fatalf("placeEnemies failed: %v", err)
+	}
+	for _, name := range []string{"B", "C"} {
+		if allRooms[name].Enemy == nil {
+			t.Errorf("Expected a mandatory fight in %s", name)
+		}
+	}
+	for _, name := range []string{"E", "G"} {
+		if allRooms[name].Enemy == nil {
+			t.Errorf("Expected a guard at the branch mouth %s", name)
+		}
+	}
+	for _, name := range []string{"A", "D", "F"} {
+		if allRooms[name].Enemy != nil {
+			t.Errorf("Expected no enemy in %s", name)
+		}
+	}
+	if loot := allRooms["B"].Enemy.Loot; len(loot) != 1 || loot[0].Name != "dagger" {
+		t.Errorf("Expected loot with chance 1 to drop, got %v", loot)
+	}
+}
+
+func TestPlaceEnemies_NotEnoughRooms(t *testing.T) {
+	start, allRooms := buildBranchedRooms()
+	path := []*world.Room{start, allRooms["B"], allRooms["C"], allRooms["D"]}
+	rng := rand.New(rand.NewSource(1))
+
+	if err := placeEnemies(enemyConfig(3, 0), rng, path, allRooms); err == nil {
+		t.Error("Expected error for more fights than rooms on the path")
+	}
+	if err := placeEnemies(enemyConfig(0, 3), rng, path, allRooms); err == nil {
+		t.Error("Expected error for more guards than side branches")
+	}
+}
+
+func TestPlaceTraps_AvoidsStartTreasureAndEnemies(t *testing.T) {
+	start, allRooms := buildBranchedRooms()
+	allRooms["B"].Enemy = &world.Enemy{Name: "goblin", HP: 3, Damage: 1}
+	config := Config{NumberOfTraps: 4, TrapPool: []TrapText{{Text: "Spikes!", Damage: 1}}}
+
+	if err := placeTraps(config, rand.New(rand.NewSource(1)), start, allRooms["D"], allRooms); err != nil {
+		t.Fatalf("placeTraps failed: %v", err)
+	}
+	for _, name := range []string{"C", "E", "F", "G"} {
+		if trap := allRooms[name].Trap; trap == nil || trap.Damage != 1 {
+			t.Errorf("Expected a trap in %s, got %+v", name, trap)
+		}
+	}
+	for _, name := range []string{"A", "B", "D"} {
+		if allRooms[name].Trap != nil {
+			t.Errorf("Expected no trap in %s", name)
+		}
+	}
+
+	config.NumberOfTraps = 5
+	if err := placeTraps(config, rand.New(rand.NewSource(1)), start, allRooms["D"], allRooms); err == nil {
+		t.Error("Expected error for more traps than free rooms")
+	}
+}
+
+func TestGenerate_EnemiesPlaced(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		config := DefaultConfig()
+		config.Seed = seed
+		start, err := Generate(config)
+		if err != nil {
+			t.Fatalf("seed %d: Generate failed: %v", seed, err)
+		}
+		enemies := 0
+		for _, room := range collectRooms(start) {
+			if room.Enemy == nil {
+				continue
+			}
+			enemies++
+			if room == start || room.Name == "Treasure Room" {
+				t.Errorf("seed %d: enemy in %s", seed, room.Name)
+			}
+			if len(room.NPCs) > 0 {
+				t.Errorf("seed %d: enemy shares %s with a character", seed, room.Name)
+			}
+		}
+		if want := config.NumberOfFights + config.NumberOfGuards; enemies != want {
+			t.Errorf("seed %d: expected %d enemies, got %d", seed, want, enemies)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"errors"
+	"math/rand"
+	"text-adventure-v2/world"
+)
+
+// placeNPCs puts config.NumberOfNPCs characters from the pool in lit rooms
+// other than the treasure room and rooms with an enemy. Each greets the player and then shares some of
+// the world's hints, so together they describe where everything lies. It runs
+// last, once every item is in place, so the hints are true for this seed.
+func placeNPCs(config Config, rng *rand.Rand, treasureRoom *world.Room, allRooms map[string]*world.Room) error {
+	if config.NumberOfNPCs == 0 {
+		return nil
+	}
+
+	var candidates []*world.Room
+	for _, room := range sortedRooms(allRooms) {
+		if room != treasureRoom && !room.Dark && room.Enemy == nil {
+			candidates = append(candidates, room)
+		}
+	}
+	if len(candidates) == 0 {
+		return errors.New("no lit room available for a character")
+	}
+
+	npcs := make([]*world.NPC, config.NumberOfNPCs)
+	for i, p := range rng.Perm(len(config.NPCPool))[:config.NumberOfNPCs] {
+		text := config.NPCPool[p]
+		npcs[i] = &world.NPC{Name: text.Name, Description: text.Description, Lines: []string{text.Greeting}, Dialogue: text.Dialogue}
+		room := candidates[rng.Intn(len(candidates))]
+		room.NPCs = append(room.NPCs, npcs[i])
+	}
+
+	hints := worldHints(allRooms)
+	rng.Shuffle(len(hints), func(i, j int) { hints[i], hints[j] = hints[j], hints[i] })
+	for i, hint := range hints {
+		npc := npcs[i%len(npcs)]
+		npc.Lines = append(npc.Lines, hint)
+	}
+	return nil
+}
+
+// worldHints describes where each key, tool and light source lies, reading the
+// world as it is rather than what the generator meant to build.
+func worldHints(allRooms map[string]*world.Room) []string {
+	var hints []string
+	for _, room := range sortedRooms(allRooms) {
+		for _, item := range room.Items {
+			if b, ok := item.Behavior(world.BehaviorTool); ok {
+				hints = append(hints, "The "+item.Name+" lies in the "+room.Name+". It will see you past the "+b.KeyID+".")
+				continue
+			}
+			_, key := item.Behavior(world.BehaviorKey)
+			_, light := item.Behavior(world.BehaviorLight)
+			if key || light {
+				hints = append(hints, "The "+item.Name+" lies in the "+room.Name+".")
+			}
+		}
+	}
+	return hints
+}
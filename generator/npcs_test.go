@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"math/rand"
+	"regexp"
+	"testing"
+	"text-adventure-v2/world"
+)
+
+var hintPattern = regexp.MustCompile(`^The (.+) lies in the (.+?)\.`)
+
+// TestPlaceNPCs_HintsAreTrue checks, for every theme and many seeds, that each
+// hint names an item in the room it really lies in, and that every key, tool
+// and light is hinted at.
+func TestPlaceNPCs_HintsAreTrue(t *testing.T) {
+	for _, name := range Themes() {
+		theme, _ := LoadTheme(name)
+		for seed := int64(1); seed <= 20; seed++ {
+			config := theme.Apply(DefaultConfig())
+			config.Seed = seed
+			start, err := Generate(config)
+			if err != nil {
+				t.Fatalf("theme %q seed %d: Generate() failed: %v", name, seed, err)
+			}
+			allRooms := collectRooms(start)
+
+			npcs, hinted := 0, make(map[string]bool)
+			for _, room := range allRooms {
+				for _, npc := range room.NPCs {
+					npcs++
+					if room.Dark || room.Name == "Treasure Room" {
+						t.Errorf("theme %q seed %d: %s placed in %s", name, seed, npc.Name, room.Name)
+					}
+					for _, line := range npc.Lines[1:] {
+						m := hintPattern.FindStringSubmatch(line)
+						if m == nil {
+							t.Fatalf("theme %q seed %d: unexpected hint %q", name, seed, line)
+						}
+						itemRoom, ok := allRooms[m[2]]
+						if !ok || !hasItem(itemRoom.Items, m[1]) {
+							t.Errorf("theme %q seed %d: false hint %q", name, seed, line)
+						}
+						hinted[m[1]] = true
+					}
+				}
+			}
+			if npcs != config.NumberOfNPCs {
+				t.Errorf("theme %q seed %d: expected %d characters, got %d", name, seed, config.NumberOfNPCs, npcs)
+			}
+			for _, hint := range worldHints(allRooms) {
+				if m := hintPattern.FindStringSubmatch(hint); !hinted[m[1]] {
+					t.Errorf("theme %q seed %d: nobody mentions the %s", name, seed, m[1])
+				}
+			}
+		}
+	}
+}
+
+func TestPlaceNPCs_SplitsHints(t *testing.T) {
+	start, allRooms := buildValidWorld()
+	start.Items = append(start.Items, keyItem("iron key", "iron"))
+	config := Config{NumberOfNPCs: 2, NPCPool: []NPCText{
+		{Name: "hermit", Greeting: "Hello."},
+		{Name: "ghost", Greeting: "Boo."},
+	}}
+	if err := placeNPCs(config, rand.New(rand.NewSource(1)), allRooms["Treasure"], allRooms); err != nil {
+		t.Fatalf("placeNPCs failed: %v", err)
+	}
+
+	var lines int
+	for _, room := range allRooms {
+		for _, npc := range room.NPCs {
+			if len(npc.Lines) != 2 {
+				t.Errorf("Expected %s to have a greeting and one hint, got %v", npc.Name, npc.Lines)
+			}
+			lines += len(npc.Lines)
+		}
+	}
+	if lines != 4 {
+		t.Errorf("Expected two greetings and two hints in all, got %d lines", lines)
+	}
+}
+
+func hasItem(items []*world.Item, name string) bool {
+	for _, item := range items {
+		if item.Name == name {
+			return true
+		}
+	}
+	return false
+}
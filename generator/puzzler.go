@@ -3,11 +3,14 @@ package generator
 import (
 	"errors"
 	"math/rand"
+	"sort"
+	"strings"
 	"text-adventure-v2/world"
 )
 
-// placePuzzles finds a path for the main puzzle and places the key and locked door.
-func placePuzzles(config Config, startRoom *world.Room, allRooms map[string]*world.Room) error {
+// placePuzzles finds a path for the main puzzle and places the locked doors,
+// obstacles, and the keys and tools that open them.
+func placePuzzles(config Config, rng *rand.Rand, startRoom *world.Room, allRooms map[string]*world.Room) error {
 	// Find the longest path to a dead end to be the treasure room.
 	path, err := findLongestPath(startRoom, allRooms)
 	if err != nil {
@@ -21,45 +24,273 @@ func placePuzzles(config Config, startRoom *world.Room, allRooms map[string]*wor
 	// The end of the path is the treasure room.
 	treasureRoom := path[len(path)-1]
 	treasureRoom.Name = "Treasure Room"
-	treasureRoom.Description = "You have found the treasure room! A large chest sits in the center."
-	treasureRoom.Items = append(treasureRoom.Items, &world.Item{Name: "treasure", Description: "A chest full of gold!"})
-
-	// Place the locked door right before the treasure room.
-	doorIndex := len(path) - 2
-	doorRoom := path[doorIndex]
-	nextRoomInPath := path[doorIndex+1]
-	for _, exit := range doorRoom.Exits {
-		if exit.Room == nextRoomInPath {
+	treasureRoom.Description = config.TreasureRoomDesc
+	treasureRoom.Items = append(treasureRoom.Items, &world.Item{Name: "treasure", Description: config.TreasureDesc})
+
+	locks, err := lockDoors(config, rng, path)
+	if err != nil {
+		return err
+	}
+	obstacles, err := blockExits(config, rng, startRoom, treasureRoom, allRooms)
+	if err != nil {
+		return err
+	}
+	if _, err := placeGateItems(rng, startRoom, treasureRoom, append(locks, obstacles...)); err != nil {
+		return err
+	}
+
+	if err := placeItems(config, rng, startRoom, allRooms); err != nil {
+		return err
+	}
+	if err := placeDarkness(config, rng, startRoom, treasureRoom, allRooms); err != nil {
+		return err
+	}
+	if err := placeEnemies(config, rng, path, allRooms); err != nil {
+		return err
+	}
+	if err := placeTraps(config, rng, startRoom, treasureRoom, allRooms); err != nil {
+		return err
+	}
+	return placeNPCs(config, rng, treasureRoom, allRooms)
+}
+
+// placeItems puts config.NumberOfItems items from the pool in rooms other than
+// the start room that hold nothing yet, at most one to a room.
+func placeItems(config Config, rng *rand.Rand, startRoom *world.Room, allRooms map[string]*world.Room) error {
+	var candidates []*world.Room
+	for _, room := range sortedRooms(allRooms) {
+		if room != startRoom && len(room.Items) == 0 {
+			candidates = append(candidates, room)
+		}
+	}
+	if config.NumberOfItems > len(candidates) {
+		return errors.New("not enough empty rooms for NumberOfItems")
+	}
+	rooms := rng.Perm(len(candidates))
+	for n, i := range rng.Perm(len(config.ItemPool))[:config.NumberOfItems] {
+		extra := config.ItemPool[i]
+		item := &world.Item{Name: extra.Name, Description: extra.Description}
+		if extra.Heal > 0 {
+			item.Behaviors = []world.Behavior{{Kind: world.BehaviorConsumable, HP: extra.Heal}}
+		}
+		room := candidates[rooms[n]]
+		room.Items = append(room.Items, item)
+	}
+	return nil
+}
+
+// lockDoors locks config.NumberOfLocks doors along the critical path, the last
+// one guarding the treasure room, and returns them as gates with their keys
+// made but not yet placed.
+func lockDoors(config Config, rng *rand.Rand, path []*world.Room) ([]*gate, error) {
+	locks := max(1, config.NumberOfLocks)
+	if locks > len(path)-1 {
+		return nil, errors.New("path is too short for NumberOfLocks")
+	}
+	if locks > len(config.KeyNamePool) {
+		return nil, errors.New("not enough key names in the pool for the number of locks requested")
+	}
+
+	// Pick distinct doors along the path; the final one sits right before the treasure room.
+	doors := rng.Perm(len(path) - 2)[:locks-1]
+	sort.Ints(doors)
+	doors = append(doors, len(path)-2)
+
+	keyNames := make([]string, len(config.KeyNamePool))
+	copy(keyNames, config.KeyNamePool)
+	rng.Shuffle(len(keyNames), func(i, j int) { keyNames[i], keyNames[j] = keyNames[j], keyNames[i] })
+
+	var gates []*gate
+	for i, d := range doors {
+		name := keyNames[i]
+		for _, dir := range sortedDirs(path[d].Exits) {
+			exit := path[d].Exits[dir]
+			if exit.Room != path[d+1] {
+				continue
+			}
 			exit.Locked = true
+			exit.KeyID = keyID(name)
+			if len(config.LockTextPool) > 0 {
+				exit.LockText = config.LockTextPool[rng.Intn(len(config.LockTextPool))]
+			}
+			gates = append(gates, &gate{from: path[d], dir: dir, exit: exit, item: &world.Item{
+				Name:        name,
+				Description: "A small " + name + ".",
+				Behaviors:   []world.Behavior{{Kind: world.BehaviorKey, KeyID: keyID(name)}},
+			}})
 			break
 		}
 	}
+	return gates, nil
+}
+
+// blockExits puts config.NumberOfObstacles obstacles from the pool on exits
+// leading away from the start, never into the treasure room or through a
+// locked door, and returns them as gates with their tools made but not yet placed.
+func blockExits(config Config, rng *rand.Rand, startRoom, treasureRoom *world.Room, allRooms map[string]*world.Room) ([]*gate, error) {
+	if config.NumberOfObstacles == 0 {
+		return nil, nil
+	}
+	if config.NumberOfObstacles > len(config.ObstaclePool) {
+		return nil, errors.New("not enough obstacles in the pool for NumberOfObstacles")
+	}
+
+	depth := make(map[*world.Room]int)
+	for _, room := range sortedRooms(allRooms) {
+		path, err := bfs(startRoom, room)
+		if err != nil {
+			return nil, err
+		}
+		depth[room] = len(path)
+	}
+
+	var candidates []*gate
+	for _, room := range sortedRooms(allRooms) {
+		for _, dir := range sortedDirs(room.Exits) {
+			exit := room.Exits[dir]
+			if depth[exit.Room] == depth[room]+1 && !exit.Locked && exit.Room != treasureRoom {
+				candidates = append(candidates, &gate{from: room, dir: dir, exit: exit, obstacle: true})
+			}
+		}
+	}
+	if len(candidates) < config.NumberOfObstacles {
+		return nil, errors.New("not enough open exits to satisfy NumberOfObstacles")
+	}
+	rng.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
 
-	// Place the key somewhere on the path before the locked door.
-	keyIndex := rand.Intn(doorIndex)
-	keyRoom := path[keyIndex]
-	keyRoom.Items = append(keyRoom.Items, &world.Item{Name: "key", Description: "A small, rusty key."})
+	pool := make([]Obstacle, len(config.ObstaclePool))
+	copy(pool, config.ObstaclePool)
+	rng.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+
+	gates := candidates[:config.NumberOfObstacles]
+	for i, g := range gates {
+		obstacle := pool[i]
+		g.exit.Obstacle = obstacle.Name
+		behaviors := []world.Behavior{{Kind: world.BehaviorTool, KeyID: obstacle.Name}}
+		if obstacle.UsedUp {
+			behaviors = append(behaviors, world.Behavior{Kind: world.BehaviorConsumable})
+		}
+		g.item = &world.Item{
+			Name:        obstacle.Tool,
+			Description: "It looks useful against the " + obstacle.Name + ".",
+			Behaviors:   behaviors,
+		}
+	}
+	return gates, nil
+}
 
-	// Place extra items
-	for _, itemName := range config.ExtraItems {
-		for {
-			var randomRoom *world.Room
-			// Find a random room
-			for _, r := range allRooms {
-				randomRoom = r
-				if rand.Float32() < 0.5 { // Add some randomness to room selection
-					break
-				}
+// placeGateItems builds the puzzle's dependency graph. Starting from the rooms
+// open at the start, it repeatedly picks a gate on the edge of the reachable
+// area, hides that gate's item somewhere already reachable, and opens the gate.
+// Each gate's after field records the gate that revealed its item's room, so the
+// returned order is a topological order of the graph and the world is solvable
+// by construction. Keys always go in the area revealed by the most recent gate,
+// which chains the locks; tools may go anywhere reachable. The door into the
+// treasure room is opened last.
+func placeGateItems(rng *rand.Rand, startRoom, treasureRoom *world.Room, gates []*gate) ([]*gate, error) {
+	held := make(map[string]bool)
+	reach := reachableRooms(startRoom, held)
+	revealedBy := make(map[*world.Room]*gate)
+	for room := range reach {
+		revealedBy[room] = nil
+	}
+
+	closed := append([]*gate(nil), gates...)
+	var order []*gate
+	var last *gate
+	for len(closed) > 0 {
+		var frontier []int
+		for i, g := range closed {
+			if reach[g.from] && (g.exit.Room != treasureRoom || len(closed) == 1) {
+				frontier = append(frontier, i)
+			}
+		}
+		if len(frontier) == 0 {
+			return nil, errors.New("no gate can be reached to place its item")
+		}
+		pick := frontier[rng.Intn(len(frontier))]
+		g := closed[pick]
+		closed = append(closed[:pick], closed[pick+1:]...)
+
+		var region []*world.Room
+		for _, room := range sortRoomSet(reach) {
+			if g.obstacle || revealedBy[room] == last {
+				region = append(region, room)
 			}
+		}
+		if len(region) == 0 {
+			return nil, errors.New("no room available for a key")
+		}
+		itemRoom := region[rng.Intn(len(region))]
+		itemRoom.Items = append(itemRoom.Items, g.item)
+		g.after = revealedBy[itemRoom]
 
-			// Don't place items in the start room or rooms that already have items.
-			if randomRoom != startRoom && len(randomRoom.Items) == 0 {
-				randomRoom.Items = append(randomRoom.Items, &world.Item{Name: itemName, Description: "An extra item."})
-				break
+		held[g.requirement()] = true
+		next := reachableRooms(startRoom, held)
+		for room := range next {
+			if !reach[room] {
+				revealedBy[room] = g
 			}
 		}
+		reach = next
+		last = g
+		order = append(order, g)
+	}
+	return order, nil
+}
+
+// keyID derives a lock ID from a key name: "brass key" opens "brass" locks.
+func keyID(name string) string {
+	return strings.Fields(name)[0]
+}
+
+// sortRoomSet returns the rooms in a set ordered by name, for stable RNG use.
+func sortRoomSet(set map[*world.Room]bool) []*world.Room {
+	rooms := make([]*world.Room, 0, len(set))
+	for room := range set {
+		rooms = append(rooms, room)
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].Name < rooms[j].Name })
+	return rooms
+}
+
+// placeDarkness plunges config.DarkRooms rooms into darkness and places a torch
+// in a lit room the player can reach without unlocking anything. The start and
+// treasure rooms always stay lit.
+func placeDarkness(config Config, rng *rand.Rand, startRoom, treasureRoom *world.Room, allRooms map[string]*world.Room) error {
+	if config.DarkRooms == 0 {
+		return nil
+	}
+
+	var candidates []*world.Room
+	for _, room := range sortedRooms(allRooms) {
+		if room != startRoom && room != treasureRoom {
+			candidates = append(candidates, room)
+		}
+	}
+	if len(candidates) <= config.DarkRooms {
+		return errors.New("not enough rooms to satisfy DarkRooms")
+	}
+	rng.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	for _, room := range candidates[:config.DarkRooms] {
+		room.Dark = true
 	}
 
+	var torchRooms []*world.Room
+	for _, room := range candidates[config.DarkRooms:] {
+		if _, err := validatorBfs(startRoom, room, false); err == nil {
+			torchRooms = append(torchRooms, room)
+		}
+	}
+	if len(torchRooms) == 0 {
+		return errors.New("no lit room reachable for the torch")
+	}
+	torchRoom := torchRooms[rng.Intn(len(torchRooms))]
+	torchRoom.Items = append(torchRoom.Items, &world.Item{
+		Name:        "torch",
+		Description: "A wooden torch, ready to be lit.",
+		Behaviors:   []world.Behavior{{Kind: world.BehaviorLight}},
+	})
 	return nil
 }
 
@@ -67,7 +298,7 @@ func placePuzzles(config Config, startRoom *world.Room, allRooms map[string]*wor
 func findLongestPath(start *world.Room, allRooms map[string]*world.Room) ([]*world.Room, error) {
 	var longestPath []*world.Room
 
-	for _, room := range allRooms {
+	for _, room := range sortedRooms(allRooms) {
 		if room == start {
 			continue
 		}
@@ -102,7 +333,8 @@ func bfs(start, end *world.Room) ([]*world.Room, error) {
 			return path, nil
 		}
 
-		for _, exit := range node.Exits {
+		for _, dir := range sortedDirs(node.Exits) {
+			exit := node.Exits[dir]
 			if !visited[exit.Room] {
 				visited[exit.Room] = true
 				newPath := make([]*world.Room, len(path))
@@ -115,3 +347,28 @@ func bfs(start, end *world.Room) ([]*world.Room, error) {
 
 	return nil, errors.New("no path found between start and end")
 }
+
+// sortedRooms returns the rooms ordered by name. Map iteration order is random
+// in Go, so anything that feeds the RNG must walk rooms in a stable order.
+func sortedRooms(allRooms map[string]*world.Room) []*world.Room {
+	names := make([]string, 0, len(allRooms))
+	for name := range allRooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rooms := make([]*world.Room, len(names))
+	for i, name := range names {
+		rooms[i] = allRooms[name]
+	}
+	return rooms
+}
+
+// sortedDirs returns the exit directions of a room in alphabetical order.
+func sortedDirs(exits map[string]*world.Exit) []string {
+	dirs := make([]string, 0, len(exits))
+	for dir := range exits {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"math/rand"
 	"testing"
 	"text-adventure-v2/world"
 )
@@ -22,6 +23,32 @@ func buildLinearRooms(n int) (*world.Room, map[string]*world.Room) {
 	return rooms[0], allRooms
 }
 
+// buildBranchedRooms creates a main line with two side branches:
+//
+//	A -- B -- C -- D
+//	     |    |
+//	     E    G
+//	     |
+//	     F
+func buildBranchedRooms() (*world.Room, map[string]*world.Room) {
+	allRooms := make(map[string]*world.Room)
+	coords := map[string][2]int{"A": {0, 1}, "B": {1, 1}, "C": {2, 1}, "D": {3, 1}, "E": {1, 2}, "F": {1, 3}, "G": {2, 0}}
+	for name, xy := range coords {
+		allRooms[name] = &world.Room{Name: name, Exits: make(map[string]*world.Exit), X: xy[0], Y: xy[1]}
+	}
+	link := func(from, dir, to, back string) {
+		allRooms[from].Exits[dir] = &world.Exit{Room: allRooms[to]}
+		allRooms[to].Exits[back] = &world.Exit{Room: allRooms[from]}
+	}
+	link("A", "east", "B", "west")
+	link("B", "east", "C", "west")
+	link("C", "east", "D", "west")
+	link("B", "south", "E", "north")
+	link("E", "south", "F", "north")
+	link("C", "north", "G", "south")
+	return allRooms["A"], allRooms
+}
+
 // --- bfs tests ---
 
 func TestBfs_Adjacent(t *testing.T) {
@@ -154,3 +181,152 @@ func TestFindLongestPath_BranchedMap(t *testing.T) {
 		t.Errorf("Expected longest path of length 5 (A to F), got %d", len(path))
 	}
 }
+
+// --- placeDarkness tests ---
+
+func TestPlaceDarkness_TorchReachableAndLit(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		start, allRooms := buildLinearRooms(6)
+		treasure := allRooms["F"]
+		allRooms["E"].Exits["east"].Locked = true
+
+		config := Config{DarkRooms: 2}
+		if err := placeDarkness(config, rand.New(rand.NewSource(seed)), start, treasure, allRooms); err != nil {
+			t.Fatalf("seed %d: placeDarkness failed: %v", seed, err)
+		}
+
+		dark, torches := 0, 0
+		for _, room := range allRooms {
+			if room.Dark {
+				dark++
+				if room == start || room == treasure {
+					t.Errorf("seed %d: start and treasure rooms must stay lit", seed)
+				}
+			}
+			for _, item := range room.Items {
+				if _, ok := item.Behavior(world.BehaviorLight); ok {
+					torches++
+					if room.Dark {
+						t.Errorf("seed %d: torch placed in a dark room", seed)
+					}
+				}
+			}
+		}
+		if dark != 2 || torches != 1 {
+			t.Errorf("seed %d: expected 2 dark rooms and 1 torch, got %d and %d", seed, dark, torches)
+		}
+	}
+}
+
+func TestPlaceDarkness_Disabled(t *testing.T) {
+	start, allRooms := buildLinearRooms(4)
+	if err := placeDarkness(Config{}, rand.New(rand.NewSource(1)), start, allRooms["D"], allRooms); err != nil {
+		t.Fatalf("placeDarkness failed: %v", err)
+	}
+	for _, room := range allRooms {
+		if room.Dark || len(room.Items) > 0 {
+			t.Errorf("DarkRooms 0 should leave room %s untouched", room.Name)
+		}
+	}
+}
+
+// --- lock and obstacle placement tests ---
+
+func TestPlaceGateItems_LockChainSolvable(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		start, allRooms := buildLinearRooms(6)
+		allRooms["F"].Items = []*world.Item{{Name: "treasure"}}
+		path, _ := bfs(start, allRooms["F"])
+		rng := rand.New(rand.NewSource(seed))
+
+		config := Config{NumberOfLocks: 3, KeyNamePool: []string{"brass key", "iron key", "bone key"}}
+		gates, err := lockDoors(config, rng, path)
+		if err != nil {
+			t.Fatalf("seed %d: lockDoors failed: %v", seed, err)
+		}
+		order, err := placeGateItems(rng, start, allRooms["F"], gates)
+		if err != nil {
+			t.Fatalf("seed %d: placeGateItems failed: %v", seed, err)
+		}
+
+		locks := make(map[string]bool)
+		for _, room := range allRooms {
+			for _, exit := range room.Exits {
+				if exit.Locked {
+					locks[exit.KeyID] = true
+				}
+			}
+		}
+		if len(locks) != 3 {
+			t.Errorf("seed %d: expected 3 distinct locks, got %v", seed, locks)
+		}
+		if order[len(order)-1].exit.Room != allRooms["F"] {
+			t.Errorf("seed %d: the door into the treasure room must open last", seed)
+		}
+		// On a line, each key sits behind the lock before it.
+		for i, g := range order {
+			if i > 0 && g.after != order[i-1] {
+				t.Errorf("seed %d: key %d should depend on the previous lock", seed, i)
+			}
+		}
+		if err := validateWorld(start, allRooms); err != nil {
+			t.Errorf("seed %d: chain should be solvable, got: %v", seed, err)
+		}
+	}
+}
+
+func TestLockDoors_TooManyLocks(t *testing.T) {
+	start, allRooms := buildLinearRooms(3)
+	path, _ := bfs(start, allRooms["C"])
+	config := Config{NumberOfLocks: 3, KeyNamePool: []string{"a key", "b key", "c key"}}
+	if _, err := lockDoors(config, rand.New(rand.NewSource(1)), path); err == nil {
+		t.Error("Expected error when the path has fewer doors than locks")
+	}
+}
+
+func TestBlockExits_ToolsPlacedBeforeObstacles(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		start, allRooms := buildBranchedRooms()
+		treasure := allRooms["D"]
+		treasure.Items = []*world.Item{{Name: "treasure"}}
+		path, _ := bfs(start, treasure)
+		rng := rand.New(rand.NewSource(seed))
+
+		config := Config{
+			NumberOfLocks:     1,
+			KeyNamePool:       []string{"brass key"},
+			NumberOfObstacles: 2,
+			ObstaclePool: []Obstacle{
+				{Name: "rubble", Tool: "pickaxe"},
+				{Name: "rusted gate", Tool: "oil flask", UsedUp: true},
+			},
+		}
+		locks, err := lockDoors(config, rng, path)
+		if err != nil {
+			t.Fatalf("seed %d: lockDoors failed: %v", seed, err)
+		}
+		obstacles, err := blockExits(config, rng, start, treasure, allRooms)
+		if err != nil {
+			t.Fatalf("seed %d: blockExits failed: %v", seed, err)
+		}
+		for _, g := range obstacles {
+			if g.exit.Locked || g.exit.Room == treasure || g.exit.Room == start {
+				t.Errorf("seed %d: obstacle placed on a bad exit %s -> %s", seed, g.from.Name, g.exit.Room.Name)
+			}
+		}
+		if _, err := placeGateItems(rng, start, treasure, append(locks, obstacles...)); err != nil {
+			t.Fatalf("seed %d: placeGateItems failed: %v", seed, err)
+		}
+		if err := validateWorld(start, allRooms); err != nil {
+			t.Errorf("seed %d: world with obstacles should be solvable, got: %v", seed, err)
+		}
+	}
+}
+
+func TestBlockExits_NotEnoughInPool(t *testing.T) {
+	start, allRooms := buildLinearRooms(4)
+	config := Config{NumberOfObstacles: 2, ObstaclePool: []Obstacle{{Name: "rubble", Tool: "pickaxe"}}}
+	if _, err := blockExits(config, rand.New(rand.NewSource(1)), start, allRooms["D"], allRooms); err == nil {
+		t.Error("Expected error when the pool has fewer obstacles than requested")
+	}
+}
@@ -0,0 +1,333 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+	"text-adventure-v2/world"
+)
+
+// maxSolverStates bounds the state-space search, so a pathological world
+// fails validation instead of stalling generation.
+const maxSolverStates = 1 << 18
+
+// Solution is the shortest way to win a world, as found by Solve.
+type Solution struct {
+	Steps  []string // player commands in order, e.g. "take brass key", "go east", "unlock east"
+	States int      // distinct player states the search explored
+}
+
+// gate is an exit that only opens with an item: a locked door that takes a
+// key, or an obstacle that takes a tool. An exit can carry both.
+type gate struct {
+	from     *world.Room
+	dir      string
+	exit     *world.Exit
+	obstacle bool // true for an obstacle, false for a lock
+
+	// Set by the generator while placing puzzles.
+	item  *world.Item // the key or tool that opens this gate
+	after *gate       // the gate whose opening revealed item's room; nil if reachable from the start
+}
+
+// requirement is the ID an item must carry to open the gate.
+func (g *gate) requirement() string {
+	if g.obstacle {
+		return g.exit.Obstacle
+	}
+	return g.exit.KeyID
+}
+
+// fits reports whether item opens the gate.
+func (g *gate) fits(item *world.Item) bool {
+	if g.obstacle {
+		b, ok := item.Behavior(world.BehaviorTool)
+		return ok && b.KeyID == g.exit.Obstacle
+	}
+	b, ok := item.Behavior(world.BehaviorKey)
+	return ok && (g.exit.KeyID == "" || b.KeyID == g.exit.KeyID)
+}
+
+// puzzleState is one node of the search: where the player stands, which gates
+// are open, which items have been picked up or used up, whether the player
+// carries a lit light, which hazards (enemies and traps) have been passed and
+// how much health is left.
+type puzzleState struct {
+	room   int
+	opened uint64 // bit per gate
+	taken  uint64 // bit per item
+	usedUp uint64 // bit per item; used-up items are taken but no longer held
+	lit    bool
+	passed uint64 // bit per room with a hazard
+	hp     int
+}
+
+// puzzleItem is an item that can change what the player can do, with the room
+// it starts in.
+type puzzleItem struct {
+	item *world.Item
+	room int
+}
+
+// Solve searches every state the player can reach — position × open gates ×
+// inventory × hazards passed — to prove the world can be won. It returns the
+// shortest winning sequence of commands, or an error if the treasure room is
+// out of reach or if some reachable state is a softlock from which it can never
+// be reached again, such as using up the only tool on the wrong obstacle.
+//
+// Hazards are only estimated: a fight is modelled as costing the player one hit
+// of the enemy's damage and a trap as costing its damage, and the player only
+// walks into what they can survive, starting from PlayerHP. A trap costs
+// exactly that, but a real fight can take more than one hit, so this proves the
+// world can be won by a player who fights well, not that every fight is
+// survivable. Healing is left out, so hazards met on side branches count
+// against the ones on the critical path.
+//
+// Dropping items is not modelled: gates never close again, and the game refuses
+// to drop anything where the player cannot see, so whatever lit the room then
+// can light it again and a dropped item can always be picked back up.
+func Solve(startRoom *world.Room, allRooms map[string]*world.Room) (*Solution, error) {
+	rooms := sortedRooms(allRooms)
+	roomIndex := make(map[*world.Room]int, len(rooms))
+	for i, room := range rooms {
+		roomIndex[room] = i
+	}
+	if _, ok := roomIndex[startRoom]; !ok {
+		return nil, errors.New("validator: the start room is not part of the world")
+	}
+
+	treasure := -1
+	var items []puzzleItem
+	var lights uint64
+	for i, room := range rooms {
+		for _, item := range room.Items {
+			if item.Name == "treasure" {
+				treasure = i
+			}
+			_, key := item.Behavior(world.BehaviorKey)
+			_, tool := item.Behavior(world.BehaviorTool)
+			_, light := item.Behavior(world.BehaviorLight)
+			if key || tool || light {
+				if light {
+					lights |= 1 << len(items)
+				}
+				items = append(items, puzzleItem{item: item, room: i})
+			}
+		}
+	}
+	if treasure < 0 {
+		return nil, errors.New("validator: no treasure room found in the world")
+	}
+
+	hazards := make(map[int]int) // room index -> hazard bit
+	for i, room := range rooms {
+		if room.Enemy != nil || room.Trap != nil {
+			hazards[i] = len(hazards)
+		}
+	}
+
+	gates := findGates(rooms)
+	if len(gates) > 64 || len(items) > 64 || len(hazards) > 64 {
+		return nil, errors.New("validator: too many gates, items or hazards to search")
+	}
+	gatesOn := make(map[*world.Exit]uint64)
+	for i, g := range gates {
+		gatesOn[g.exit] |= 1 << i
+	}
+
+	// Forward search. BFS order means the first goal state found is the
+	// shortest solution; goal states are not expanded further.
+	initial := puzzleState{room: roomIndex[startRoom], hp: PlayerHP}
+	states := []puzzleState{initial}
+	index := map[puzzleState]int{initial: 0}
+	parent := []int{-1}
+	action := []string{""}
+	var preds [][]int
+	preds = append(preds, nil)
+	goal := -1
+	var goals []int
+
+	for head := 0; head < len(states); head++ {
+		s := states[head]
+		if s.room == treasure {
+			goals = append(goals, head)
+			if goal < 0 {
+				goal = head
+			}
+			continue
+		}
+		for _, next := range successors(s, rooms, roomIndex, items, lights, hazards, gates, gatesOn, treasure) {
+			j, seen := index[next.state]
+			if !seen {
+				if len(states) >= maxSolverStates {
+					return nil, fmt.Errorf("validator: more than %d puzzle states to search", maxSolverStates)
+				}
+				j = len(states)
+				index[next.state] = j
+				states = append(states, next.state)
+				parent = append(parent, head)
+				action = append(action, next.action)
+				preds = append(preds, nil)
+			}
+			preds[j] = append(preds[j], head)
+		}
+	}
+
+	if goal < 0 {
+		return nil, errors.New("validator: no sequence of moves reaches the treasure room")
+	}
+
+	// Backward search from the goal states. Any state the player can reach but
+	// that cannot lead to a goal is a softlock.
+	canWin := make([]bool, len(states))
+	queue := goals
+	for _, g := range goals {
+		canWin[g] = true
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for _, p := range preds[s] {
+			if !canWin[p] {
+				canWin[p] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+	for i := range states {
+		if !canWin[i] {
+			return nil, fmt.Errorf("validator: softlock after %q: the treasure room can no longer be reached",
+				strings.Join(stepsTo(i, parent, action), ", "))
+		}
+	}
+
+	return &Solution{Steps: stepsTo(goal, parent, action), States: len(states)}, nil
+}
+
+// transition is a state reachable in one command.
+type transition struct {
+	state  puzzleState
+	action string
+}
+
+// successors lists the states one command away from s, in a stable order.
+func successors(s puzzleState, rooms []*world.Room, roomIndex map[*world.Room]int, items []puzzleItem,
+	lights uint64, hazards map[int]int, gates []*gate, gatesOn map[*world.Exit]uint64, treasure int) []transition {
+	var out []transition
+	room := rooms[s.room]
+	held := s.taken &^ s.usedUp
+
+	for _, dir := range sortedDirs(room.Exits) {
+		exit := room.Exits[dir]
+		if gatesOn[exit]&^s.opened != 0 {
+			continue
+		}
+		next := s
+		next.room = roomIndex[exit.Room]
+		action := "go " + dir
+		if h, ok := hazards[next.room]; ok && s.passed&(1<<h) == 0 {
+			cost, what := hazardCost(exit.Room)
+			if s.hp <= cost {
+				continue // the player would die
+			}
+			next.hp -= cost
+			next.passed |= 1 << h
+			action += " (" + what + ")"
+		}
+		out = append(out, transition{next, action})
+	}
+
+	if !room.Dark || s.lit {
+		for i, it := range items {
+			if it.room != s.room || s.taken&(1<<i) != 0 {
+				continue
+			}
+			next := s
+			next.taken |= 1 << i
+			if lights&(1<<i) != 0 && it.item.Lit {
+				next.lit = true
+			}
+			out = append(out, transition{next, "take " + it.item.Name})
+		}
+	}
+
+	if !s.lit && held&lights != 0 {
+		next := s
+		next.lit = true
+		out = append(out, transition{next, "light " + items[bits.TrailingZeros64(held&lights)].item.Name})
+	}
+
+	for g, gt := range gates {
+		if gt.from != room || s.opened&(1<<g) != 0 {
+			continue
+		}
+		for i, it := range items {
+			if held&(1<<i) == 0 || !gt.fits(it.item) {
+				continue
+			}
+			next := s
+			next.opened |= 1 << g
+			if _, ok := it.item.Behavior(world.BehaviorConsumable); ok && gt.obstacle {
+				next.usedUp |= 1 << i // keys are kept, but consumable tools are used up
+			}
+			verb := "unlock " + gt.dir
+			if gt.obstacle {
+				verb = "use " + it.item.Name + " on " + gt.dir
+			} else if roomIndex[gt.exit.Room] == treasure {
+				// Unlocking the treasure room's door wins on the spot.
+				next.room = treasure
+			}
+			out = append(out, transition{next, verb})
+			if next.usedUp == s.usedUp {
+				break // an item that is kept is as good as any other that fits
+			}
+		}
+	}
+	return out
+}
+
+// hazardCost is the health it costs to enter room the first time, and what
+// happens there, e.g. "spring a trap, fight the goblin".
+func hazardCost(room *world.Room) (int, string) {
+	cost := 0
+	var what []string
+	if room.Trap != nil {
+		cost += room.Trap.Damage
+		what = append(what, "spring a trap")
+	}
+	if room.Enemy != nil {
+		cost += room.Enemy.Damage
+		what = append(what, "fight the "+room.Enemy.Name)
+	}
+	return cost, strings.Join(what, ", ")
+}
+
+// findGates lists every locked door and obstacle, in a stable order.
+func findGates(rooms []*world.Room) []*gate {
+	var gates []*gate
+	for _, room := range rooms {
+		for _, dir := range sortedDirs(room.Exits) {
+			exit := room.Exits[dir]
+			if exit.Locked {
+				gates = append(gates, &gate{from: room, dir: dir, exit: exit})
+			}
+			if exit.Obstacle != "" {
+				gates = append(gates, &gate{from: room, dir: dir, exit: exit, obstacle: true})
+			}
+		}
+	}
+	return gates
+}
+
+// stepsTo rebuilds the commands leading to state i.
+func stepsTo(i int, parent []int, action []string) []string {
+	var steps []string
+	for ; parent[i] >= 0; i = parent[i] {
+		steps = append(steps, action[i])
+	}
+	for l, r := 0, len(steps)-1; l < r; l, r = l+1, r-1 {
+		steps[l], steps[r] = steps[r], steps[l]
+	}
+	return steps
+}
@@ -0,0 +1,167 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"text-adventure-v2/world"
+)
+
+// toolItem creates a tool that clears the named obstacle.
+func toolItem(name, obstacle string, usedUp bool) *world.Item {
+	item := &world.Item{Name: name, Behaviors: []world.Behavior{{Kind: world.BehaviorTool, KeyID: obstacle}}}
+	if usedUp {
+		item.Behaviors = append(item.Behaviors, world.Behavior{Kind: world.BehaviorConsumable})
+	}
+	return item
+}
+
+func TestSolve_ShortestSolution(t *testing.T) {
+	start, allRooms := buildValidWorld()
+
+	solution, err := Solve(start, allRooms)
+	if err != nil {
+		t.Fatalf("Expected a solution, got error: %v", err)
+	}
+	want := []string{"take key", "go east", "unlock east"}
+	if strings.Join(solution.Steps, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Expected %v, got %v", want, solution.Steps)
+	}
+}
+
+func TestSolve_KeyBehindItsOwnDoor(t *testing.T) {
+	start, allRooms := buildValidWorld()
+	start.Items = nil
+	allRooms["Treasure"].Items = append(allRooms["Treasure"].Items, keyItem("key", ""))
+
+	if _, err := Solve(start, allRooms); err == nil {
+		t.Error("Expected error when the key is locked behind its own door")
+	}
+}
+
+func TestSolve_ObstacleNeedsTool(t *testing.T) {
+	// Start -[rubble]-> Middle [key] -[locked]-> Treasure, pickaxe in Start.
+	start, allRooms := buildValidWorld()
+	start.Items = []*world.Item{toolItem("pickaxe", "rubble", false)}
+	start.Exits["east"].Obstacle = "rubble"
+	allRooms["Middle"].Items = []*world.Item{keyItem("key", "")}
+
+	solution, err := Solve(start, allRooms)
+	if err != nil {
+		t.Fatalf("Expected a solution, got error: %v", err)
+	}
+	want := "take pickaxe, use pickaxe on east, go east, take key, unlock east"
+	if got := strings.Join(solution.Steps, ", "); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	start.Items = []*world.Item{toolItem("machete", "thorny vines", false)}
+	if _, err := Solve(start, allRooms); err == nil {
+		t.Error("Expected error when the only tool doesn't fit the obstacle")
+	}
+}
+
+func TestSolve_DetectsSoftlock(t *testing.T) {
+	// Start holds one oil flask and has two rusted gates. The east gate leads
+	// to a dead end; oiling it uses up the flask and strands the player.
+	start, allRooms := buildValidWorld()
+	deadEnd := &world.Room{Name: "DeadEnd", Exits: make(map[string]*world.Exit)}
+	allRooms["DeadEnd"] = deadEnd
+	start.Exits["south"] = &world.Exit{Room: deadEnd, Obstacle: "rusted gate"}
+	deadEnd.Exits["north"] = &world.Exit{Room: start}
+	start.Exits["east"].Obstacle = "rusted gate"
+	start.Items = append(start.Items, toolItem("oil flask", "rusted gate", true))
+
+	_, err := Solve(start, allRooms)
+	if err == nil || !strings.Contains(err.Error(), "softlock") {
+		t.Fatalf("Expected a softlock error, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "use oil flask on south") {
+		t.Errorf("Softlock error should show the moves that lead to it, got: %v", err)
+	}
+
+	// A flask that isn't used up can open both gates.
+	start.Items[len(start.Items)-1] = toolItem("oil flask", "rusted gate", false)
+	if _, err := Solve(start, allRooms); err != nil {
+		t.Errorf("Expected a solution with a reusable tool, got: %v", err)
+	}
+}
+
+func TestSolve_DarkRoomNeedsLight(t *testing.T) {
+	start, allRooms := buildValidWorld()
+	middle := allRooms["Middle"]
+	middle.Dark = true
+	middle.Items = []*world.Item{keyItem("key", "")}
+	start.Items = []*world.Item{{Name: "torch", Behaviors: []world.Behavior{{Kind: world.BehaviorLight}}}}
+
+	solution, err := Solve(start, allRooms)
+	if err != nil {
+		t.Fatalf("Expected a solution, got error: %v", err)
+	}
+	got := strings.Join(solution.Steps, ", ")
+	if len(solution.Steps) != 5 || strings.Index(got, "light torch") > strings.Index(got, "take key") {
+		t.Errorf("Expected to light the torch before taking the key in 5 steps, got %q", got)
+	}
+
+	start.Items = nil
+	if _, err := Solve(start, allRooms); err == nil {
+		t.Error("Expected error when the key is in the dark and there is no light")
+	}
+}
+
+func TestSolve_FightsCostHealth(t *testing.T) {
+	start, allRooms := buildValidWorld()
+	allRooms["Middle"].Enemy = &world.Enemy{Name: "rat", HP: 2, Damage: 1}
+
+	solution, err := Solve(start, allRooms)
+	if err != nil {
+		t.Fatalf("Expected a solution, got error: %v", err)
+	}
+	want := []string{"take key", "go east (fight the rat)", "unlock east"}
+	if strings.Join(solution.Steps, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Expected %v, got %v", want, solution.Steps)
+	}
+}
+
+func TestSolve_TrapsCostHealth(t *testing.T) {
+	start, allRooms := buildValidWorld()
+	allRooms["Middle"].Trap = &world.Trap{Text: "Spikes!", Damage: 2}
+	allRooms["Middle"].Enemy = &world.Enemy{Name: "rat", HP: 2, Damage: 1}
+
+	solution, err := Solve(start, allRooms)
+	if err != nil {
+		t.Fatalf("Expected a solution, got error: %v", err)
+	}
+	if want := "go east (spring a trap, fight the rat)"; solution.Steps[1] != want {
+		t.Errorf("Expected %q, got %v", want, solution.Steps)
+	}
+
+	allRooms["Middle"].Trap.Damage = PlayerHP - 1
+	if _, err := Solve(start, allRooms); err == nil {
+		t.Error("Expected error when the trap and the fight together are deadly")
+	}
+}
+
+func TestSolve_MandatoryFightTooStrong(t *testing.T) {
+	start, allRooms := buildValidWorld()
+	allRooms["Middle"].Enemy = &world.Enemy{Name: "troll", HP: 9, Damage: PlayerHP}
+
+	if _, err := Solve(start, allRooms); err == nil {
+		t.Error("Expected error when the player cannot survive the fight on the way")
+	}
+}
+
+func TestSolve_OptionalFightsCanSoftlock(t *testing.T) {
+	// Start -- Middle -[locked]-> Treasure, with a side room off Start. Picking
+	// the side fight leaves too little health to get past the one in Middle.
+	start, allRooms := buildValidWorld()
+	side := &world.Room{Name: "Side", Exits: map[string]*world.Exit{"north": {Room: start}},
+		Enemy: &world.Enemy{Name: "wolf", HP: 3, Damage: 2}}
+	start.Exits["south"] = &world.Exit{Room: side}
+	allRooms["Side"] = side
+	allRooms["Middle"].Enemy = &world.Enemy{Name: "boar", HP: 3, Damage: PlayerHP - 2}
+
+	_, err := Solve(start, allRooms)
+	if err == nil || !strings.Contains(err.Error(), "softlock") {
+		t.Errorf("Expected a softlock after the side fight, got %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package generator
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text-adventure-v2/dialogue"
+	"text-adventure-v2/world"
+)
+
+// DefaultTheme is the theme DefaultConfig uses.
+const DefaultTheme = "dungeon"
+
+//go:embed themes/*.json
+var themeFiles embed.FS
+
+// Theme is a dungeon pack: the names, descriptions and items a world is
+// dressed in. Themes live as JSON files in the themes directory and are
+// embedded in the binary; the file name (without .json) selects the theme.
+type Theme struct {
+	Name         string       `json:"name"`
+	StartRoom    RoomText     `json:"start_room"`
+	TreasureRoom TreasureText `json:"treasure_room"`
+	RoomNames    []string     `json:"room_names"`
+	RoomDescs    []string     `json:"room_descriptions"`
+	Items        []ItemText   `json:"items"`
+	Keys         []string     `json:"keys"`
+	LockText     []string     `json:"lock_text"`
+	Obstacles    []Obstacle   `json:"obstacles"`
+	NPCs         []NPCText    `json:"npcs"`
+	Enemies      []EnemyText  `json:"enemies"`
+	Traps        []TrapText   `json:"traps"`
+}
+
+// RoomText names and describes a fixed room.
+type RoomText struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// TreasureText describes the treasure room and the treasure in it. The room
+// itself is always called "Treasure Room".
+type TreasureText struct {
+	Description string `json:"description"`
+	Treasure    string `json:"treasure"`
+}
+
+// ItemText names and describes an item. An item with Heal set is a
+// consumable that restores that much health.
+type ItemText struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Heal        int    `json:"heal,omitempty"`
+}
+
+// TrapText describes a trap and how much it hurts.
+type TrapText struct {
+	Text   string `json:"text"`
+	Damage int    `json:"damage"`
+}
+
+// NPCText describes a character. Their hints are written by the generator
+// from the world it builds; the greeting comes first. Dialogue optionally
+// names a built-in dialogue tree (see package dialogue) that talking starts.
+type NPCText struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Greeting    string `json:"greeting"`
+	Dialogue    string `json:"dialogue,omitempty"`
+}
+
+// EnemyText describes a kind of enemy and what it may drop.
+type EnemyText struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	HP          int                `json:"hp"`
+	Damage      int                `json:"damage"`
+	Profile     world.EnemyProfile `json:"profile"`
+	Loot        []LootText         `json:"loot,omitempty"`
+}
+
+// LootText is an item an enemy drops with the given chance, rolled when the
+// world is generated so a seed always yields the same loot.
+type LootText struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Chance      float64 `json:"chance"` // in (0, 1]
+}
+
+// enemyProfiles are the profiles an enemy may have.
+var enemyProfiles = map[world.EnemyProfile]bool{
+	world.ProfileAggressive: true, world.ProfileCautious: true, world.ProfileSentry: true,
+}
+
+// Themes returns the names of the embedded themes in alphabetical order.
+func Themes() []string {
+	entries, err := themeFiles.ReadDir("themes")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadTheme reads and checks an embedded theme by name, e.g. "crypt".
+func LoadTheme(name string) (Theme, error) {
+	name = strings.ToLower(name)
+	data, err := themeFiles.ReadFile(path.Join("themes", name+".json"))
+	if err != nil {
+		return Theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(Themes(), ", "))
+	}
+	return ParseTheme(data)
+}
+
+// ParseTheme decodes a theme from JSON and checks that it is well formed.
+// Whether its pools are big enough depends on the config; see Config.Validate.
+func ParseTheme(data []byte) (Theme, error) {
+	var t Theme
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Theme{}, fmt.Errorf("theme is not valid JSON: %w", err)
+	}
+	if t.Name == "" {
+		return Theme{}, errors.New("theme has no name")
+	}
+	if t.StartRoom.Name == "" || t.StartRoom.Description == "" {
+		return Theme{}, fmt.Errorf("theme %q: start room needs a name and a description", t.Name)
+	}
+	if t.TreasureRoom.Description == "" || t.TreasureRoom.Treasure == "" {
+		return Theme{}, fmt.Errorf("theme %q: treasure room needs a description and a treasure", t.Name)
+	}
+
+	seen := map[string]bool{t.StartRoom.Name: true, "Treasure Room": true}
+	for _, name := range t.RoomNames {
+		if seen[name] {
+			return Theme{}, fmt.Errorf("theme %q: room name %q is used twice or clashes with a fixed room", t.Name, name)
+		}
+		seen[name] = true
+	}
+	// Use picks one behavior per item name, so a name may only be an item, a
+	// key or a tool.
+	pools := make(map[string]string)
+	claim := func(name, pool string) error {
+		if other, ok := pools[name]; ok && other != pool {
+			return fmt.Errorf("theme %q: %q is both %s and %s; give them different names", t.Name, name, other, pool)
+		}
+		pools[name] = pool
+		return nil
+	}
+	for _, item := range t.Items {
+		if err := claim(item.Name, "an item"); err != nil {
+			return Theme{}, err
+		}
+	}
+	for _, key := range t.Keys {
+		if err := claim(key, "a key"); err != nil {
+			return Theme{}, err
+		}
+	}
+	for _, o := range t.Obstacles {
+		if err := claim(o.Tool, "a tool"); err != nil {
+			return Theme{}, err
+		}
+	}
+
+	locks := make(map[string]bool)
+	for _, key := range t.Keys {
+		if len(strings.Fields(key)) == 0 {
+			return Theme{}, fmt.Errorf("theme %q: key names must not be empty", t.Name)
+		}
+		id := keyID(key)
+		if locks[id] {
+			return Theme{}, fmt.Errorf("theme %q: two keys open %q locks; start each key name with a different word", t.Name, id)
+		}
+		locks[id] = true
+	}
+	for _, o := range t.Obstacles {
+		if o.Name == "" || o.Tool == "" {
+			return Theme{}, fmt.Errorf("theme %q: obstacles need a name and a tool", t.Name)
+		}
+	}
+	var trees map[string]*dialogue.Tree
+	for _, npc := range t.NPCs {
+		if npc.Name == "" || npc.Greeting == "" {
+			return Theme{}, fmt.Errorf("theme %q: characters need a name and a greeting", t.Name)
+		}
+		if npc.Dialogue == "" {
+			continue
+		}
+		if trees == nil {
+			var err error
+			if trees, err = dialogue.Builtin(); err != nil {
+				return Theme{}, fmt.Errorf("theme %q: %w", t.Name, err)
+			}
+		}
+		if _, ok := trees[npc.Dialogue]; !ok {
+			return Theme{}, fmt.Errorf("theme %q: the %s has unknown dialogue %q", t.Name, npc.Name, npc.Dialogue)
+		}
+	}
+	for _, e := range t.Enemies {
+		if e.Name == "" || e.HP <= 0 || e.Damage <= 0 {
+			return Theme{}, fmt.Errorf("theme %q: enemies need a name, hp and damage", t.Name)
+		}
+		if !enemyProfiles[e.Profile] {
+			return Theme{}, fmt.Errorf("theme %q: the %s has unknown profile %q", t.Name, e.Name, e.Profile)
+		}
+		for _, loot := range e.Loot {
+			if loot.Name == "" || loot.Chance <= 0 || loot.Chance > 1 {
+				return Theme{}, fmt.Errorf("theme %q: the %s's loot needs a name and a chance in (0, 1]", t.Name, e.Name)
+			}
+		}
+	}
+	for _, trap := range t.Traps {
+		if trap.Text == "" || trap.Damage <= 0 {
+			return Theme{}, fmt.Errorf("theme %q: traps need text and damage", t.Name)
+		}
+	}
+	return t, nil
+}
+
+// Apply dresses config in the theme, replacing its name, text and item pools.
+// Sizes such as NumberOfRooms are left alone.
+func (t Theme) Apply(config Config) Config {
+	config.Theme = t.Name
+	config.StartRoomName = t.StartRoom.Name
+	config.StartRoomDesc = t.StartRoom.Description
+	config.TreasureRoomDesc = t.TreasureRoom.Description
+	config.TreasureDesc = t.TreasureRoom.Treasure
+	config.RoomNamePool = t.RoomNames
+	config.RoomDescPool = t.RoomDescs
+	config.ItemPool = t.Items
+	config.KeyNamePool = t.Keys
+	config.LockTextPool = t.LockText
+	config.ObstaclePool = t.Obstacles
+	config.NPCPool = t.NPCs
+	config.EnemyPool = t.Enemies
+	config.TrapPool = t.Traps
+	return config
+}
@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThemes_AllGenerate(t *testing.T) {
+	names := Themes()
+	if len(names) < 4 {
+		t.Fatalf("Expected at least 4 embedded themes, got %v", names)
+	}
+	for _, name := range names {
+		theme, err := LoadTheme(name)
+		if err != nil {
+			t.Fatalf("LoadTheme(%q) failed: %v", name, err)
+		}
+		for seed := int64(1); seed <= 10; seed++ {
+			config := theme.Apply(DefaultConfig())
+			config.Seed = seed
+			start, err := Generate(config)
+			if err != nil {
+				t.Fatalf("theme %q seed %d: Generate() failed: %v", name, seed, err)
+			}
+			if start.Name != theme.StartRoom.Name {
+				t.Errorf("theme %q: expected start room %q, got %q", name, theme.StartRoom.Name, start.Name)
+			}
+			for _, room := range collectRooms(start) {
+				if room.Name == "Treasure Room" && room.Description != theme.TreasureRoom.Description {
+					t.Errorf("theme %q: treasure room has the wrong description: %q", name, room.Description)
+				}
+			}
+		}
+	}
+}
+
+func TestLoadTheme_Unknown(t *testing.T) {
+	_, err := LoadTheme("moonbase")
+	if err == nil || !strings.Contains(err.Error(), "crypt") {
+		t.Errorf("Expected an error listing the available themes, got: %v", err)
+	}
+}
+
+func TestLoadTheme_CaseInsensitive(t *testing.T) {
+	theme, err := LoadTheme("Crypt")
+	if err != nil || theme.Name != "Crypt" {
+		t.Errorf("Expected the Crypt theme, got %q, %v", theme.Name, err)
+	}
+}
+
+func TestConfigValidate_PoolsTooSmall(t *testing.T) {
+	theme, _ := LoadTheme("crypt")
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"rooms", func(c *Config) { c.NumberOfRooms = 20 }, "room names"},
+		{"items", func(c *Config) { c.NumberOfItems = 9 }, "items"},
+		{"locks", func(c *Config) { c.NumberOfLocks = 9 }, "key names"},
+		{"obstacles", func(c *Config) { c.NumberOfObstacles = 9 }, "obstacles"},
+		{"characters", func(c *Config) { c.NumberOfNPCs = 9 }, "characters"},
+		{"descriptions", func(c *Config) { c.RoomDescPool = nil }, "room descriptions"},
+		{"enemies", func(c *Config) { c.EnemyPool = nil }, "no enemies"},
+		{"traps", func(c *Config) { c.TrapPool = nil }, "no traps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := theme.Apply(DefaultConfig())
+			tt.modify(&config)
+			err := config.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), `theme "Crypt"`) {
+				t.Errorf("Expected an error about %s naming the theme, got: %v", tt.want, err)
+			}
+			if _, err := Generate(config); err == nil {
+				t.Error("Generate() should refuse an invalid config")
+			}
+		})
+	}
+}
+
+func TestParseTheme_Rejects(t *testing.T) {
+	base := `"name":"Test","start_room":{"name":"Gate","description":"A gate."},
+		"treasure_room":{"description":"Gold.","treasure":"Coins."}`
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"not json", `{`},
+		{"no name", `{"start_room":{"name":"Gate","description":"A gate."}}`},
+		{"no start room", `{"name":"Test","treasure_room":{"description":"Gold.","treasure":"Coins."}}`},
+		{"duplicate room", `{` + base + `,"room_names":["Hall","Hall"]}`},
+		{"room named like start", `{` + base + `,"room_names":["Gate"]}`},
+		{"keys share a lock", `{` + base + `,"keys":["iron key","iron bar key"]}`},
+		{"item named like a tool", `{` + base + `,"items":[{"name":"holy water"}],
+			"obstacles":[{"name":"coffin lid","tool":"holy water"}]}`},
+		{"key named like an item", `{` + base + `,"items":[{"name":"bone key"}],"keys":["bone key"]}`},
+		{"obstacle without tool", `{` + base + `,"obstacles":[{"name":"rubble"}]}`},
+		{"character without greeting", `{` + base + `,"npcs":[{"name":"hermit"}]}`},
+		{"enemy without hp", `{` + base + `,"enemies":[{"name":"rat","damage":1,"profile":"cautious"}]}`},
+		{"unknown enemy profile", `{` + base + `,"enemies":[{"name":"rat","hp":2,"damage":1,"profile":"sneaky"}]}`},
+		{"loot that never drops", `{` + base + `,"enemies":[{"name":"rat","hp":2,"damage":1,"profile":"cautious",
+			"loot":[{"name":"tail","chance":0}]}]}`},
+		{"trap without damage", `{` + base + `,"traps":[{"text":"A click."}]}`},
+		{"unknown dialogue", `{` + base + `,"npcs":[{"name":"hermit","greeting":"Hi.","dialogue":"nobody"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseTheme([]byte(tt.json)); err == nil {
+				t.Errorf("Expected ParseTheme to reject %s", tt.json)
+			}
+		})
+	}
+}
@@ -8,11 +8,12 @@ import (
 
 // validateWorld ensures that the generated world is solvable.
 func validateWorld(startRoom *world.Room, allRooms map[string]*world.Room) error {
-	var keyRoom, treasureRoom *world.Room
+	var treasureRoom *world.Room
+	hasKey := false
 	for _, room := range allRooms {
 		for _, item := range room.Items {
-			if item.Name == "key" {
-				keyRoom = room
+			if _, ok := item.Behavior(world.BehaviorKey); ok {
+				hasKey = true
 			}
 			if item.Name == "treasure" {
 				treasureRoom = room
@@ -20,33 +21,99 @@ func validateWorld(startRoom *world.Room, allRooms map[string]*world.Room) error
 		}
 	}
 
-	if keyRoom == nil {
+	if !hasKey {
 		return errors.New("validator: no key found in the world")
 	}
 	if treasureRoom == nil {
 		return errors.New("validator: no treasure room found in the world")
 	}
 
-	// Test 1: Can you get from the start room to the key's room?
-	_, err := validatorBfs(startRoom, keyRoom, false)
-	if err != nil {
-		return errors.New("validator: could not find a path from start to key")
-	}
-
-	// Test 2: Can you get from the start room to the treasure room (ignoring locks)?
-	_, err = validatorBfs(startRoom, treasureRoom, true)
+	// Test 1: Can you get from the start room to the treasure room (ignoring locks)?
+	_, err := validatorBfs(startRoom, treasureRoom, true)
 	if err != nil {
 		return errors.New("validator: could not find a path from start to treasure (ignoring locks)")
 	}
 
-	// Test 3: Is there really no path to the treasure room if locks are considered?
+	// Test 2: Is there really no path to the treasure room if locks are considered?
 	// This ensures the door is actually blocking the path.
 	_, err = validatorBfs(startRoom, treasureRoom, false)
 	if err == nil {
 		return errors.New("validator: a path to treasure exists without needing the key")
 	}
 
-	return nil
+	// Test 3: Fights never start the game or stand between the player and
+	// the treasure once its door is open.
+	if startRoom.Enemy != nil || treasureRoom.Enemy != nil {
+		return errors.New("validator: an enemy waits in the start or treasure room")
+	}
+
+	// Test 4: Search every state the player can reach to prove the treasure
+	// room can be reached, with every key and tool on the way, by a player who
+	// survives the fights on the way, and that no sequence of moves softlocks
+	// the world.
+	if _, err := Solve(startRoom, allRooms); err != nil {
+		return err
+	}
+
+	// Test 5: Items in dark rooms can only be found with a light source, so one
+	// must sit in a lit room reachable without any key.
+	return validateLight(startRoom, allRooms)
+}
+
+// reachableRooms returns every room reachable from start through open exits,
+// locks whose key ID is in held, and obstacles whose name is in held (the
+// player carries the tool). A lock with no key ID opens for any key.
+func reachableRooms(start *world.Room, held map[string]bool) map[*world.Room]bool {
+	visited := map[*world.Room]bool{start: true}
+	queue := []*world.Room{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, exit := range node.Exits {
+			if visited[exit.Room] {
+				continue
+			}
+			if exit.Locked && !held[exit.KeyID] && !(exit.KeyID == "" && len(held) > 0) {
+				continue
+			}
+			if exit.Obstacle != "" && !held[exit.Obstacle] {
+				continue
+			}
+			visited[exit.Room] = true
+			queue = append(queue, exit.Room)
+		}
+	}
+	return visited
+}
+
+// validateLight checks that, if any dark room holds items, a light source can be
+// picked up in a lit room reachable from the start without unlocking anything.
+func validateLight(startRoom *world.Room, allRooms map[string]*world.Room) error {
+	needsLight := false
+	for _, room := range allRooms {
+		if room.Dark && len(room.Items) > 0 {
+			needsLight = true
+			break
+		}
+	}
+	if !needsLight {
+		return nil
+	}
+
+	for _, room := range allRooms {
+		if room.Dark {
+			continue
+		}
+		for _, item := range room.Items {
+			if _, ok := item.Behavior(world.BehaviorLight); !ok {
+				continue
+			}
+			if _, err := validatorBfs(startRoom, room, false); err == nil {
+				return nil
+			}
+		}
+	}
+	return errors.New("validator: items are hidden in the dark but no light source is reachable")
 }
 
 // validateGeometry checks that each exit's target room coordinates match the direction label:
@@ -78,7 +145,8 @@ func validateGeometry(allRooms map[string]*world.Room) error {
 	return nil
 }
 
-// validatorBfs finds if a path exists between two rooms.
+// validatorBfs finds if a path exists between two rooms. Unless ignoreLocks is
+// set, locked doors and obstacles block the way.
 func validatorBfs(start, end *world.Room, ignoreLocks bool) ([]*world.Room, error) {
 	queue := [][]*world.Room{{start}}
 	visited := map[*world.Room]bool{start: true}
@@ -94,7 +162,7 @@ func validatorBfs(start, end *world.Room, ignoreLocks bool) ([]*world.Room, erro
 
 		for _, exit := range node.Exits {
 			if !visited[exit.Room] {
-				if !exit.Locked || ignoreLocks {
+				if (!exit.Locked && exit.Obstacle == "") || ignoreLocks {
 					visited[exit.Room] = true
 					newPath := make([]*world.Room, len(path))
 					copy(newPath, path)
@@ -5,11 +5,17 @@ import (
 	"text-adventure-v2/world"
 )
 
+// keyItem creates a key with the given lock ID ("" fits any unkeyed lock).
+func keyItem(name, id string) *world.Item {
+	return &world.Item{Name: name, Description: "A key.",
+		Behaviors: []world.Behavior{{Kind: world.BehaviorKey, KeyID: id}}}
+}
+
 // buildValidWorld creates a simple 3-room world: Start -> Middle -[locked]-> Treasure
 // with a key in Start. This is the minimal valid world.
 func buildValidWorld() (*world.Room, map[string]*world.Room) {
 	start := &world.Room{Name: "Start", Exits: make(map[string]*world.Exit),
-		Items: []*world.Item{{Name: "key", Description: "A key."}}}
+		Items: []*world.Item{keyItem("key", "")}}
 	middle := &world.Room{Name: "Middle", Exits: make(map[string]*world.Exit)}
 	treasure := &world.Room{Name: "Treasure", Exits: make(map[string]*world.Exit),
 		Items: []*world.Item{{Name: "treasure", Description: "Gold!"}}}
@@ -57,7 +63,7 @@ func TestValidateWorld_NoTreasure(t *testing.T) {
 func TestValidateWorld_TreasureReachableWithoutKey(t *testing.T) {
 	// No locked door — treasure is directly reachable
 	start := &world.Room{Name: "Start", Exits: make(map[string]*world.Exit),
-		Items: []*world.Item{{Name: "key", Description: "A key."}}}
+		Items: []*world.Item{keyItem("key", "")}}
 	treasure := &world.Room{Name: "Treasure", Exits: make(map[string]*world.Exit),
 		Items: []*world.Item{{Name: "treasure", Description: "Gold!"}}}
 
@@ -75,7 +81,7 @@ func TestValidateWorld_TreasureReachableWithoutKey(t *testing.T) {
 func TestValidateWorld_TreasureUnreachable(t *testing.T) {
 	// Treasure is completely disconnected (no path even ignoring locks)
 	start := &world.Room{Name: "Start", Exits: make(map[string]*world.Exit),
-		Items: []*world.Item{{Name: "key", Description: "A key."}}}
+		Items: []*world.Item{keyItem("key", "")}}
 	treasure := &world.Room{Name: "Treasure", Exits: make(map[string]*world.Exit),
 		Items: []*world.Item{{Name: "treasure", Description: "Gold!"}}}
 
@@ -92,7 +98,7 @@ func TestValidateWorld_KeyUnreachable(t *testing.T) {
 	// Key is behind a locked door
 	start := &world.Room{Name: "Start", Exits: make(map[string]*world.Exit)}
 	keyRoom := &world.Room{Name: "KeyRoom", Exits: make(map[string]*world.Exit),
-		Items: []*world.Item{{Name: "key", Description: "A key."}}}
+		Items: []*world.Item{keyItem("key", "")}}
 	treasure := &world.Room{Name: "Treasure", Exits: make(map[string]*world.Exit),
 		Items: []*world.Item{{Name: "treasure", Description: "Gold!"}}}
 
@@ -109,6 +115,53 @@ func TestValidateWorld_KeyUnreachable(t *testing.T) {
 	}
 }
 
+func TestValidateWorld_KeyChain(t *testing.T) {
+	// Start [brass key] -[brass]-> Middle [iron key] -[iron]-> Treasure
+	start, allRooms := buildValidWorld()
+	middle := allRooms["Middle"]
+	start.Items = []*world.Item{keyItem("brass key", "brass")}
+	middle.Items = []*world.Item{keyItem("iron key", "iron")}
+	start.Exits["east"].Locked = true
+	start.Exits["east"].KeyID = "brass"
+	middle.Exits["east"].KeyID = "iron"
+
+	if err := validateWorld(start, allRooms); err != nil {
+		t.Errorf("Expected solvable chain, got error: %v", err)
+	}
+
+	// Swap the keys: the brass key now sits behind the brass door — unsolvable.
+	start.Items = []*world.Item{keyItem("iron key", "iron")}
+	middle.Items = []*world.Item{keyItem("brass key", "brass")}
+	if err := validateWorld(start, allRooms); err == nil {
+		t.Error("Expected error when a key is locked behind its own door")
+	}
+
+	// A key for a lock that doesn't exist in the chain leaves it unsolvable too.
+	start.Items = []*world.Item{keyItem("brass key", "brass")}
+	middle.Items = []*world.Item{keyItem("bone key", "bone")}
+	if err := validateWorld(start, allRooms); err == nil {
+		t.Error("Expected error when the last key is missing")
+	}
+}
+
+// --- reachableRooms tests ---
+
+func TestReachableRooms_HonorsKeyIDs(t *testing.T) {
+	start, allRooms := buildValidWorld()
+	treasure := allRooms["Treasure"]
+	allRooms["Middle"].Exits["east"].KeyID = "iron"
+
+	if reachableRooms(start, nil)[treasure] {
+		t.Error("Treasure should be unreachable with no keys")
+	}
+	if reachableRooms(start, map[string]bool{"brass": true})[treasure] {
+		t.Error("Treasure should be unreachable with the wrong key")
+	}
+	if !reachableRooms(start, map[string]bool{"iron": true})[treasure] {
+		t.Error("Treasure should be reachable with the iron key")
+	}
+}
+
 // --- validatorBfs tests ---
 
 func TestValidatorBfs_DirectPath(t *testing.T) {
@@ -318,3 +371,58 @@ func TestValidatorBfs_MultiHopPath(t *testing.T) {
 		t.Errorf("Expected path of length 4, got %d", len(path))
 	}
 }
+
+func TestValidateWorld_DarkItemsNeedReachableLight(t *testing.T) {
+	start, allRooms := buildValidWorld()
+	middle := allRooms["Middle"]
+	middle.Dark = true
+	middle.Items = []*world.Item{{Name: "sword"}}
+
+	if err := validateWorld(start, allRooms); err == nil {
+		t.Fatal("Expected error when a dark room has items and there is no light")
+	}
+
+	// A torch left in the dark room itself can't be found.
+	middle.Items = append(middle.Items, &world.Item{Name: "torch", Behaviors: []world.Behavior{{Kind: world.BehaviorLight}}})
+	if err := validateWorld(start, allRooms); err == nil {
+		t.Fatal("Expected error when the only light is in the dark")
+	}
+
+	// A torch behind the locked door doesn't help either.
+	middle.Items = middle.Items[:1]
+	treasure := allRooms["Treasure"]
+	treasure.Items = append(treasure.Items, &world.Item{Name: "torch", Behaviors: []world.Behavior{{Kind: world.BehaviorLight}}})
+	if err := validateWorld(start, allRooms); err == nil {
+		t.Fatal("Expected error when the only light is behind a lock")
+	}
+
+	start.Items = append(start.Items, &world.Item{Name: "torch", Behaviors: []world.Behavior{{Kind: world.BehaviorLight}}})
+	if err := validateWorld(start, allRooms); err != nil {
+		t.Errorf("Expected valid world with a reachable torch, got: %v", err)
+	}
+}
+
+func TestValidateWorld_EnemyInStartRoom(t *testing.T) {
+	start, allRooms := buildValidWorld()
+	start.Enemy = &world.Enemy{Name: "rat", HP: 2, Damage: 1}
+
+	if err := validateWorld(start, allRooms); err == nil {
+		t.Error("Expected error for an enemy in the start room")
+	}
+}
+
+func TestValidateWorld_KeyBehindUnbeatableFight(t *testing.T) {
+	// The key lies in Middle, which a fight the player cannot survive guards.
+	start, allRooms := buildValidWorld()
+	start.Items = nil
+	allRooms["Middle"].Items = []*world.Item{keyItem("key", "")}
+	allRooms["Middle"].Enemy = &world.Enemy{Name: "troll", HP: 9, Damage: PlayerHP}
+
+	if err := validateWorld(start, allRooms); err == nil {
+		t.Error("Expected error when the key is behind a fight the player cannot win")
+	}
+	allRooms["Middle"].Enemy.Damage = 1
+	if err := validateWorld(start, allRooms); err != nil {
+		t.Errorf("Expected a survivable fight to pass, got %v", err)
+	}
+}
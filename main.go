@@ -12,10 +12,18 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"text-adventure-v2/game"
+	"text-adventure-v2/generator"
+	"text-adventure-v2/pixelbuf"
 	"text-adventure-v2/renderer"
 )
 
-var debugMode = flag.Bool("debug", false, "enable debug logging to debug.log")
+var (
+	debugMode    = flag.Bool("debug", false, "enable debug logging to debug.log")
+	seedFlag     = flag.Int64("seed", 0, "world generation seed (0 picks a random seed)")
+	themeFlag    = flag.String("theme", generator.DefaultTheme, "dungeon theme: "+strings.Join(generator.Themes(), ", "))
+	colorsFlag   = flag.String("colors", "auto", "color mode for fights: auto (from the terminal), "+strings.Join(pixelbuf.ModeNames(), ", "))
+	encodingFlag = flag.String("encoding", "half", "characters fights are drawn with: "+strings.Join(pixelbuf.EncodingNames(), ", "))
+)
 
 var (
 	hudStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))                                           // blue
@@ -25,19 +33,43 @@ var (
 	msgStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))                                                                                                // pink
 	itemStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("220"))                                                                                     // gold
 	winStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10")).Border(lipgloss.DoubleBorder()).Padding(1, 3).BorderForeground(lipgloss.Color("11")) // green + gold border
+	loseStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9")).Border(lipgloss.DoubleBorder()).Padding(1, 3).BorderForeground(lipgloss.Color("88"))  // red + dark red border
 )
 
-const helpText = "w,a,s,d: move | e: take | u: unlock | i: inventory | h: help | q: quit"
+// helpText is the instant-key legend, derived from the command registry.
+var helpText = game.Commands.KeyLegend()
+
+// conversationHelp replaces the legend while a conversation is running.
+const conversationHelp = "1-9: choose | any other command ends the conversation"
+
 const maxLogLines = 5
 
-type model struct {
+// exploreModel is the text adventure: the map, the room and the command line.
+type exploreModel struct {
 	game      *game.Game
 	textInput textinput.Model
 	messages  []string
-	won       bool
+	over      bool // won or dead; the next key quits
+}
+
+// buildConfig turns the command-line flags into a generator config.
+func buildConfig() (generator.Config, error) {
+	theme, err := generator.LoadTheme(*themeFlag)
+	if err != nil {
+		return generator.Config{}, err
+	}
+	config := theme.Apply(generator.DefaultConfig())
+	if *seedFlag != 0 {
+		config.Seed = *seedFlag
+	}
+	return config, config.Validate()
+}
+
+func initialModel(config generator.Config) model {
+	return model{explore: newExploreModel(config)}
 }
 
-func initialModel() model {
+func newExploreModel(config generator.Config) exploreModel {
 	ti := textinput.New()
 	ti.Prompt = "> "
 	styles := textinput.DefaultDarkStyles()
@@ -46,19 +78,22 @@ func initialModel() model {
 	ti.SetStyles(styles)
 	ti.Focus()
 
-	g := game.NewGame()
+	g := game.NewGame(config)
 
 	if *debugMode {
+		log.Printf("[THEME] %s", config.Theme)
 		logStartupState(g)
 	}
 
-	return model{
+	return exploreModel{
 		game:      g,
 		textInput: ti,
 	}
 }
 
 func logStartupState(g *game.Game) {
+	log.Printf("[SEED] %d", g.Seed)
+
 	names := make([]string, 0, len(g.AllRooms))
 	for name := range g.AllRooms {
 		names = append(names, name)
@@ -77,21 +112,38 @@ func logStartupState(g *game.Game) {
 			log.Printf("[ITEM] %s in %s", item.Name, name)
 		}
 
+		for _, npc := range room.NPCs {
+			log.Printf("[NPC] %s in %s", npc.Name, name)
+		}
+
+		if e := room.Enemy; e != nil {
+			log.Printf("[ENEMY] %s in %s (hp %d, damage %d, %s, %d loot)", e.Name, name, e.HP, e.Damage, e.Profile, len(e.Loot))
+		}
+
+		if trap := room.Trap; trap != nil {
+			log.Printf("[TRAP] %s (damage %d)", name, trap.Damage)
+		}
+
 		for dir, exit := range room.Exits {
 			if exit.Locked {
-				log.Printf("[LOCK] %s -> %s (locked)", name, dir)
+				log.Printf("[LOCK] %s -> %s (locked, key %q)", name, dir, exit.KeyID)
+			}
+			if exit.Obstacle != "" {
+				log.Printf("[BLOCK] %s -> %s (%s)", name, dir, exit.Obstacle)
 			}
 		}
 	}
 
 	log.Printf("[START] %s", g.Player.Location.Name)
-}
 
-func (m model) Init() tea.Cmd {
-	return nil
+	if solution, err := generator.Solve(g.Player.Location, g.AllRooms); err == nil {
+		log.Printf("[SOLUTION] %d steps: %s", len(solution.Steps), strings.Join(solution.Steps, ", "))
+	}
 }
 
-func (m *model) handleCommand(command string) {
+// handleCommand runs a command and records its response. It returns a command
+// that starts a fight when the player walks into a room holding an enemy.
+func (m *exploreModel) handleCommand(command string) tea.Cmd {
 	scoreBefore := m.game.Score()
 	roomBefore := m.game.Player.Location.Name
 
@@ -113,17 +165,49 @@ func (m *model) handleCommand(command string) {
 		m.messages = append(m.messages, response)
 	}
 	if shouldExit {
-		m.won = true
+		m.over = true
 		if *debugMode {
-			log.Printf("[WIN] Player won in %d turns with score %d", m.game.Turns, m.game.Score())
+			if m.game.IsLost {
+				log.Printf("[DEAD] Player died in %s after %d turns", m.game.Player.Location.Name, m.game.Turns)
+			} else {
+				log.Printf("[WIN] Player won in %d turns with score %d", m.game.Turns, m.game.Score())
+			}
 		}
+		return nil
 	}
+
+	if enemy := m.game.Encounter(); enemy != nil && m.game.Player.Location.Name != roomBefore {
+		if *debugMode {
+			log.Printf("[FIGHT] %s in %s, player HP %d", enemy.Name, m.game.Player.Location.Name, m.game.Player.HP)
+		}
+		m.messages = append(m.messages, strings.TrimSpace("The "+enemy.Name+" attacks! "+enemy.Description))
+		start := startCombatMsg{
+			enemy:       enemy,
+			arena:       arenaFor(m.game.Player.Location),
+			playerHP:    m.game.Player.HP,
+			playerMaxHP: m.game.Player.MaxHP,
+		}
+		return func() tea.Msg { return start }
+	}
+	return nil
 }
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+// endFight applies a fight's result to the game and reports it.
+func (m *exploreModel) endFight(result game.FightResult) {
+	msg := m.game.EndFight(result)
+	if *debugMode {
+		log.Printf("[FIGHT] won=%t player HP %d: %s", result.Won, m.game.Player.HP, msg)
+	}
+	if msg != "" {
+		m.messages = append(m.messages, msg)
+	}
+	m.over = m.game.IsLost
+}
+
+func (m exploreModel) Update(msg tea.Msg) (exploreModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
-		if m.won {
+		if m.over {
 			return m, tea.Quit
 		}
 
@@ -133,20 +217,20 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "enter":
 			command := m.textInput.Value()
+			var cmd tea.Cmd
 			if command != "" {
-				m.handleCommand(command)
+				cmd = m.handleCommand(command)
 				m.textInput.Reset()
 			}
-			return m, nil
+			return m, cmd
 
 		default:
 			// Instant commands when input is empty
 			if m.textInput.Value() == "" {
 				key := msg.String()
-				switch key {
-				case "w", "a", "s", "d", "e", "i", "u", "h", "q":
-					m.handleCommand(key)
-					return m, nil
+				isChoice := m.game.Conversation != nil && len(key) == 1 && key >= "1" && key <= "9"
+				if isChoice || game.Commands.IsInstantKey(key) {
+					return m, m.handleCommand(key)
 				}
 			}
 		}
@@ -157,7 +241,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-func (m model) highlightItems(s string) string {
+func (m exploreModel) highlightItems(s string) string {
 	for _, item := range m.game.Player.Location.Items {
 		s = strings.ReplaceAll(s, item.Name, itemStyle.Render(item.Name))
 	}
@@ -167,16 +251,20 @@ func (m model) highlightItems(s string) string {
 	return s
 }
 
-func (m model) View() tea.View {
+func (m exploreModel) View() string {
 	var content string
-	if m.won {
+	if m.over {
 		lastMsg := ""
 		if len(m.messages) > 0 {
 			lastMsg = m.messages[len(m.messages)-1]
 		}
-		content = winStyle.Render(fmt.Sprintf(
-			"%s\n\nTotal Turns: %d\nFinal Score: %d\n\nPress any key to exit.",
-			lastMsg, m.game.Turns, m.game.Score(),
+		style, title := winStyle, ""
+		if m.game.IsLost {
+			style, title = loseStyle, "GAME OVER\n\n"
+		}
+		content = style.Render(fmt.Sprintf(
+			"%s%s\n\nTotal Turns: %d\nFinal Score: %d\nSeed: %d\n\nPress any key to exit.",
+			title, lastMsg, m.game.Turns, m.game.Score(), m.game.Seed,
 		))
 	} else {
 		mapView := renderer.MapView{
@@ -185,6 +273,8 @@ func (m model) View() tea.View {
 			CurrentLocationName: m.game.Player.Location.Name,
 			TurnsTaken:          m.game.Turns,
 			Score:               m.game.Score(),
+			HP:                  m.game.Player.HP,
+			MaxHP:               m.game.Player.MaxHP,
 			VisitedRooms:        m.game.VisitedRooms,
 		}
 
@@ -205,12 +295,19 @@ func (m model) View() tea.View {
 
 		helpStr := helpStyle.Render(helpText)
 
-		content = lipgloss.JoinVertical(lipgloss.Left, hudStr, helpStr, mapStr, lookStr, msgStr, inputStr)
+		parts := []string{hudStr, helpStr, mapStr, lookStr, msgStr}
+		if m.game.Conversation != nil {
+			// Numbered choices sit just above the input line.
+			var choices []string
+			for i, choice := range m.game.Choices() {
+				choices = append(choices, fmt.Sprintf("%d. %s", i+1, choice))
+			}
+			parts[1] = helpStyle.Render(conversationHelp)
+			parts = append(parts, lookStyle.Render(strings.Join(choices, "\n")))
+		}
+		content = lipgloss.JoinVertical(lipgloss.Left, append(parts, inputStr)...)
 	}
-
-	v := tea.NewView(content)
-	v.AltScreen = true
-	return v
+	return content
 }
 
 func main() {
@@ -225,7 +322,23 @@ func main() {
 		}
 		defer f.Close()
 	}
-	p := tea.NewProgram(initialModel())
+	config, err := buildConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	m := initialModel(config)
+	if *colorsFlag != "auto" {
+		m.opts, err = pixelbuf.ParseOptions(*colorsFlag)
+	}
+	if err == nil {
+		m.opts.Encoding, err = pixelbuf.ParseEncoding(*encodingFlag)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	p := tea.NewProgram(m)
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -0,0 +1,206 @@
+package pixelbuf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/colorprofile"
+)
+
+// Depth is how many colors the terminal can show.
+type Depth int
+
+const (
+	TrueColor Depth = iota // 24-bit color
+	Color256               // the xterm 256-color palette
+	Color16                // the 16 ANSI colors
+	Mono                   // no color: ASCII characters by brightness
+)
+
+// Options control how a buffer is written to the terminal. The zero value
+// writes 24-bit color in half blocks.
+type Options struct {
+	Depth    Depth
+	Dither   bool // ordered dithering for Color16, to suggest the colors between
+	Encoding Encoding
+}
+
+// modes are the names ParseOptions accepts, in order of decreasing color.
+var modes = []struct {
+	name string
+	opts Options
+}{
+	{"truecolor", Options{Depth: TrueColor}},
+	{"256", Options{Depth: Color256}},
+	{"16", Options{Depth: Color16}},
+	{"16-dither", Options{Depth: Color16, Dither: true}},
+	{"mono", Options{Depth: Mono}},
+}
+
+// ModeNames returns the names ParseOptions accepts.
+func ModeNames() []string {
+	names := make([]string, len(modes))
+	for i, m := range modes {
+		names[i] = m.name
+	}
+	return names
+}
+
+// ParseOptions returns the options for a color mode named as in
+// ModeNames, for a command-line flag. The encoding is left as HalfBlock.
+func ParseOptions(name string) (Options, error) {
+	for _, m := range modes {
+		if m.name == name {
+			return m.opts, nil
+		}
+	}
+	return Options{}, fmt.Errorf("unknown color mode %q (have: %s)", name, strings.Join(ModeNames(), ", "))
+}
+
+// String returns the name of the options' color mode as ParseOptions takes
+// it, followed by the encoding unless it is HalfBlock.
+func (o Options) String() string {
+	name := fmt.Sprintf("Depth(%d)", o.Depth)
+	for _, m := range modes {
+		if m.opts.Depth == o.Depth && m.opts.Dither == o.Dither {
+			name = m.name
+		}
+	}
+	if o.Encoding != HalfBlock {
+		name += " " + o.Encoding.String()
+	}
+	return name
+}
+
+// OptionsFor picks the options for drawing in encoding e on a terminal with
+// color profile p: dithered where only the 16 ANSI colors are available, and
+// without color where there is none at all.
+func OptionsFor(p colorprofile.Profile, e Encoding) Options {
+	o := Options{Encoding: e}
+	switch p {
+	case colorprofile.TrueColor:
+		o.Depth = TrueColor
+	case colorprofile.ANSI256:
+		o.Depth = Color256
+	case colorprofile.ANSI:
+		o.Depth, o.Dither = Color16, true
+	default:
+		o.Depth = Mono
+	}
+	return o
+}
+
+// inks quantizes each pixel of buf for the options' depth into inks,
+// reusing its storage: a packed RGBA color for TrueColor, a palette index
+// for Color256 and Color16, and a brightness level for Mono. Two pixels
+// look the same exactly when their inks are equal.
+func (o Options) inks(buf *Buffer, inks []uint32) []uint32 {
+	if cap(inks) < len(buf.pixels) {
+		inks = make([]uint32, len(buf.pixels))
+	}
+	inks = inks[:len(buf.pixels)]
+	for i, c := range buf.pixels {
+		inks[i] = o.ink(c, i%buf.Width, i/buf.Width)
+	}
+	return inks
+}
+
+// ink quantizes c, the color of pixel (x, y), as inks does.
+func (o Options) ink(c Color, x, y int) uint32 {
+	switch o.Depth {
+	case Color256:
+		return uint32(nearest256(c))
+	case Color16:
+		if o.Dither {
+			c = dither(c, x, y)
+		}
+		return uint32(nearest16(c))
+	case Mono:
+		return uint32(luma(c)) * uint32(len(asciiRamp)) / 256
+	default:
+		return uint32(c.R)<<24 | uint32(c.G)<<16 | uint32(c.B)<<8 | uint32(c.A)
+	}
+}
+
+// asciiRamp runs from dark to bright, one character per brightness level.
+const asciiRamp = " .:-=+*#%@"
+
+// luma returns the perceived brightness of c, 0–255.
+func luma(c Color) int {
+	return (299*int(c.R) + 587*int(c.G) + 114*int(c.B)) / 1000
+}
+
+// distance is a squared color distance weighted towards green, to which
+// the eye is most sensitive.
+func distance(a, b Color) int {
+	dr, dg, db := int(a.R)-int(b.R), int(a.G)-int(b.G), int(a.B)-int(b.B)
+	return 2*dr*dr + 4*dg*dg + 3*db*db
+}
+
+// ansi16 is the xterm default look of the 16 ANSI colors.
+var ansi16 = [16]Color{
+	{0, 0, 0, 255}, {205, 0, 0, 255}, {0, 205, 0, 255}, {205, 205, 0, 255},
+	{0, 0, 238, 255}, {205, 0, 205, 255}, {0, 205, 205, 255}, {229, 229, 229, 255},
+	{127, 127, 127, 255}, {255, 0, 0, 255}, {0, 255, 0, 255}, {255, 255, 0, 255},
+	{92, 92, 255, 255}, {255, 0, 255, 255}, {0, 255, 255, 255}, {255, 255, 255, 255},
+}
+
+// nearest16 returns the index of the ANSI color closest to c.
+func nearest16(c Color) uint8 {
+	best, bestD := 0, -1
+	for i, p := range ansi16 {
+		if d := distance(c, p); bestD < 0 || d < bestD {
+			best, bestD = i, d
+		}
+	}
+	return uint8(best)
+}
+
+// cubeLevels are the channel values of the 6×6×6 color cube at indexes
+// 16–231 of the xterm 256-color palette.
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// nearest256 returns the index of the xterm 256-color palette entry
+// closest to c, from the color cube or the gray ramp at 232–255. The first
+// 16 entries are skipped: terminals often restyle them.
+func nearest256(c Color) uint8 {
+	level := func(v uint8) int {
+		if v < 48 {
+			return 0
+		}
+		if v < 115 {
+			return 1
+		}
+		return (int(v) - 35) / 40
+	}
+	r, g, b := level(c.R), level(c.G), level(c.B)
+	cube := Color{cubeLevels[r], cubeLevels[g], cubeLevels[b], 255}
+
+	gray := (int(c.R) + int(c.G) + int(c.B)) / 3
+	step := min(max((gray-3)/10, 0), 23)
+	v := uint8(8 + 10*step)
+	if distance(c, Color{v, v, v, 255}) < distance(c, cube) {
+		return uint8(232 + step)
+	}
+	return uint8(16 + 36*r + 6*g + b)
+}
+
+// bayer4 is the 4×4 ordered-dither threshold matrix.
+var bayer4 = [4][4]int{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// ditherSpread is how far dithering can push a channel either way, about
+// half the gap between neighbouring ANSI colors.
+const ditherSpread = 64
+
+// dither nudges c by the threshold for pixel (x, y), so that flat areas
+// between two palette colors come out as a pattern of both.
+func dither(c Color, x, y int) Color {
+	off := (2*bayer4[y&3][x&3] - 15) * ditherSpread / 32
+	nudge := func(v uint8) uint8 { return uint8(min(max(int(v)+off, 0), 255)) }
+	return Color{nudge(c.R), nudge(c.G), nudge(c.B), c.A}
+}
@@ -0,0 +1,147 @@
+package pixelbuf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/colorprofile"
+)
+
+func TestParseOptions(t *testing.T) {
+	for _, name := range ModeNames() {
+		o, err := ParseOptions(name)
+		if err != nil {
+			t.Fatalf("ParseOptions(%q) failed: %v", name, err)
+		}
+		if o.String() != name {
+			t.Errorf("ParseOptions(%q).String() = %q", name, o.String())
+		}
+	}
+	if o, _ := ParseOptions("16-dither"); o != (Options{Depth: Color16, Dither: true}) {
+		t.Errorf("ParseOptions(\"16-dither\") = %+v", o)
+	}
+	if _, err := ParseOptions("8"); err == nil {
+		t.Error("ParseOptions(\"8\") should fail")
+	}
+}
+
+func TestOptionsFor(t *testing.T) {
+	tests := []struct {
+		p    colorprofile.Profile
+		want string
+	}{
+		{colorprofile.TrueColor, "truecolor"},
+		{colorprofile.ANSI256, "256"},
+		{colorprofile.ANSI, "16-dither"},
+		{colorprofile.Ascii, "mono"},
+		{colorprofile.NoTTY, "mono"},
+	}
+	for _, tt := range tests {
+		if got := OptionsFor(tt.p, HalfBlock).String(); got != tt.want {
+			t.Errorf("OptionsFor(%v) = %q, want %q", tt.p, got, tt.want)
+		}
+	}
+	if got := OptionsFor(colorprofile.ANSI256, Braille); got.Encoding != Braille {
+		t.Errorf("OptionsFor kept encoding %v, want Braille", got.Encoding)
+	}
+}
+
+func TestNearest256(t *testing.T) {
+	tests := []struct {
+		c    Color
+		want uint8
+	}{
+		{black, 16},
+		{white, 231},
+		{red, 196},
+		{blue, 21},
+		{Color{255, 160, 60, 255}, 215},  // orange: the cube's 5,3,1
+		{Color{128, 128, 128, 255}, 244}, // mid gray is on the gray ramp
+		{Color{20, 20, 30, 255}, 234},
+	}
+	for _, tt := range tests {
+		if got := nearest256(tt.c); got != tt.want {
+			t.Errorf("nearest256(%v) = %d, want %d", tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestNearest16(t *testing.T) {
+	tests := []struct {
+		c    Color
+		want uint8
+	}{
+		{black, 0},
+		{Color{200, 10, 10, 255}, 1},
+		{red, 9},
+		{blue, 4},
+		{Color{130, 120, 125, 255}, 8},
+		{white, 15},
+	}
+	for _, tt := range tests {
+		if got := nearest16(tt.c); got != tt.want {
+			t.Errorf("nearest16(%v) = %d, want %d", tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestRenderWith(t *testing.T) {
+	redOverBlue := MustParseArt(testPalette, "r", "b")
+	tests := []struct {
+		name string
+		buf  *Buffer
+		o    Options
+		want string
+	}{
+		{"truecolor", redOverBlue, Options{}, Render(redOverBlue)},
+		{"256", redOverBlue, Options{Depth: Color256}, "\x1b[38;5;196m\x1b[48;5;21m▀" + reset},
+		{"16", redOverBlue, Options{Depth: Color16}, "\x1b[91m\x1b[44m▀" + reset},
+		{"16_same_cell", solidBuffer(2, 2, Color{200, 0, 0, 255}), Options{Depth: Color16}, "\x1b[41m  " + reset},
+		{"mono", MustParseArt(Palette{'w': white, 'k': black}, "wkw", "wkk", "kkk", "kkk"), Options{Depth: Mono}, "@ =\n   "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderWith(tt.buf, tt.o); got != tt.want {
+				t.Errorf("RenderWith(%v) = %q, want %q", tt.o, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderWithDither(t *testing.T) {
+	// Mid gray lies between the ANSI grays: flat without dithering, a
+	// pattern of neighbours with it.
+	buf := solidBuffer(8, 8, Color{180, 180, 180, 255})
+	flat := Options{Depth: Color16}
+	if inks := flat.inks(buf, nil); countDistinct(inks) != 1 {
+		t.Errorf("undithered inks = %v, want one color", inks)
+	}
+	dithered := Options{Depth: Color16, Dither: true}
+	if inks := dithered.inks(buf, nil); countDistinct(inks) < 2 {
+		t.Errorf("dithered inks = %v, want a mix of colors", inks)
+	}
+	if got := RenderWith(buf, dithered); strings.Count(got, "\n") != 3 {
+		t.Errorf("RenderWith dithered has %d lines, want 4", strings.Count(got, "\n")+1)
+	}
+}
+
+func countDistinct(inks []uint32) int {
+	seen := map[uint32]bool{}
+	for _, ink := range inks {
+		seen[ink] = true
+	}
+	return len(seen)
+}
+
+func TestRendererOptionsChangeRedraws(t *testing.T) {
+	r := NewRenderer()
+	buf := solidBuffer(2, 2, red)
+	r.Render(buf)
+	r.Options = Options{Depth: Color256}
+	if got, want := r.Render(buf), at(1, 1)+"\x1b[48;5;196m  "+reset; got != want {
+		t.Errorf("frame after changing Options = %q, want %q", got, want)
+	}
+	if got := r.Render(buf); got != "" {
+		t.Errorf("unchanged frame = %q, want \"\"", got)
+	}
+}
@@ -0,0 +1,221 @@
+package pixelbuf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Encoding is how pixels are packed into terminal cells.
+type Encoding int
+
+const (
+	HalfBlock Encoding = iota // 1×2 pixels a cell, in two colors each
+	Quadrant                  // 2×2 pixels a cell, fitted to two colors
+	Braille                   // 2×4 pixels a cell, fitted to two colors
+)
+
+var encodingNames = []string{"half", "quadrant", "braille"}
+
+// EncodingNames returns the names ParseEncoding accepts.
+func EncodingNames() []string {
+	return encodingNames
+}
+
+// ParseEncoding returns the encoding named as in EncodingNames, for a
+// command-line flag.
+func ParseEncoding(name string) (Encoding, error) {
+	for i, n := range encodingNames {
+		if n == name {
+			return Encoding(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown encoding %q (have: %s)", name, strings.Join(encodingNames, ", "))
+}
+
+func (e Encoding) String() string {
+	if e < 0 || int(e) >= len(encodingNames) {
+		return fmt.Sprintf("Encoding(%d)", int(e))
+	}
+	return encodingNames[e]
+}
+
+// CellSize returns how many pixels across and down one terminal cell shows.
+func (e Encoding) CellSize() (w, h int) {
+	switch e {
+	case Quadrant:
+		return 2, 2
+	case Braille:
+		return 2, 4
+	default:
+		return 1, 2
+	}
+}
+
+// cell is what one terminal cell shows: a glyph in the foreground ink over
+// the background ink. A space shows only the background; in Mono the inks
+// are unused.
+type cell struct {
+	glyph  rune
+	fg, bg uint32
+}
+
+// quadrantGlyphs are the quadrant block characters, indexed by which
+// quarters are foreground: 1 top left, 2 top right, 4 bottom left and 8
+// bottom right.
+var quadrantGlyphs = [16]rune{' ', '▘', '▝', '▀', '▖', '▌', '▞', '▛', '▗', '▚', '▐', '▜', '▄', '▙', '▟', '█'}
+
+// brailleDots are the dot bits of a braille pattern for the pixels of a
+// 2×4 cell, row by row. The patterns start at U+2800.
+var brailleDots = [8]rune{0x01, 0x08, 0x02, 0x10, 0x04, 0x20, 0x40, 0x80}
+
+// monoThreshold is the brightness below which Mono quadrant and braille
+// cells leave pixels blank: dark backgrounds stay empty while mid-grays,
+// like platforms, still show.
+const monoThreshold = 48
+
+// encoder turns buffers into cells. Its scratch storage is reused from
+// frame to frame.
+type encoder struct {
+	inks  []uint32
+	cells []cell
+	px    [8]Color
+}
+
+// encode returns the cells showing buf under o, row by row, and how many
+// columns and rows of them there are. Pixels past the edge of buf, in a
+// part-filled cell, count as transparent black.
+func (en *encoder) encode(buf *Buffer, o Options) (cells []cell, cols, rows int) {
+	cw, ch := o.Encoding.CellSize()
+	cols, rows = (buf.Width+cw-1)/cw, (buf.Height+ch-1)/ch
+	if cap(en.cells) < cols*rows {
+		en.cells = make([]cell, cols*rows)
+	}
+	cells = en.cells[:cols*rows]
+
+	if o.Encoding == HalfBlock {
+		en.inks = o.inks(buf, en.inks)
+		for row := range rows {
+			top, bottom := inkRows(en.inks, buf, row)
+			for x := range cols {
+				cells[row*cols+x] = halfBlock(top[x], inkAt(bottom, x), o.Depth)
+			}
+		}
+		return cells, cols, rows
+	}
+
+	px := en.px[:cw*ch]
+	for row := range rows {
+		for col := range cols {
+			x0, y0 := col*cw, row*ch
+			for i := range px {
+				px[i] = buf.At(x0+i%cw, y0+i/cw)
+			}
+			cells[row*cols+col] = o.fitCell(px, x0, y0)
+		}
+	}
+	return cells, cols, rows
+}
+
+// halfBlock returns the cell showing a top and a bottom pixel: the top as
+// the foreground of '▀', or only a background when they look the same.
+func halfBlock(top, bottom uint32, d Depth) cell {
+	switch {
+	case d == Mono:
+		return cell{glyph: rune(asciiRamp[(top+bottom)/2])}
+	case top == bottom:
+		return cell{glyph: ' ', bg: bottom}
+	default:
+		return cell{glyph: '▀', fg: top, bg: bottom}
+	}
+}
+
+// fitCell returns the cell that best shows the pixels px of a quadrant or
+// braille cell whose top-left pixel is (x0, y0). It picks the pair of the
+// pixels' colors that leaves the least total error when every pixel takes
+// the nearer of the two, then draws the less common of them as the
+// glyph over the other. In Mono the brighter of the pair is the glyph,
+// unless it is darker than monoThreshold.
+func (o Options) fitCell(px []Color, x0, y0 int) cell {
+	dist := distance
+	if o.Depth == Mono {
+		dist = func(a, b Color) int { d := luma(a) - luma(b); return d * d }
+	}
+
+	// seen reports whether px[k] repeats an earlier pixel's color, so that
+	// each pair of colors is tried once.
+	seen := func(k int) bool {
+		for i := range k {
+			if px[i] == px[k] {
+				return true
+			}
+		}
+		return false
+	}
+	a, b, best := 0, 0, -1 // a == b: one color for the whole cell
+	for i := range px {
+		if seen(i) {
+			continue
+		}
+		for j := i + 1; j < len(px); j++ {
+			if seen(j) {
+				continue
+			}
+			err := 0
+			for _, p := range px {
+				err += min(dist(p, px[i]), dist(p, px[j]))
+			}
+			if best < 0 || err < best {
+				a, b, best = i, j, err
+			}
+		}
+	}
+
+	// mask has a bit for each pixel nearer b than a.
+	var mask, n int
+	for k, p := range px {
+		if a != b && dist(p, px[b]) < dist(p, px[a]) {
+			mask |= 1 << k
+			n++
+		}
+	}
+	all := 1<<len(px) - 1
+	if o.Depth == Mono {
+		bright := px[b]
+		if a == b || luma(px[a]) > luma(px[b]) {
+			bright, mask = px[a], ^mask&all
+		}
+		if luma(bright) < monoThreshold {
+			mask = 0
+		}
+		return cell{glyph: o.glyph(mask)}
+	}
+	if 2*n > len(px) {
+		a, b, mask = b, a, ^mask&all
+	}
+
+	cw, _ := o.Encoding.CellSize()
+	ink := func(k int) uint32 { return o.ink(px[k], x0+k%cw, y0+k/cw) }
+	bg := ink(a)
+	if mask == 0 {
+		return cell{glyph: ' ', bg: bg}
+	}
+	fg := ink(b)
+	if fg == bg {
+		return cell{glyph: ' ', bg: bg}
+	}
+	return cell{glyph: o.glyph(mask), fg: fg, bg: bg}
+}
+
+// glyph returns the character with the pixels in mask in the foreground.
+func (o Options) glyph(mask int) rune {
+	if o.Encoding == Quadrant {
+		return quadrantGlyphs[mask]
+	}
+	g := rune(0x2800)
+	for k, dot := range brailleDots {
+		if mask&(1<<k) != 0 {
+			g |= dot
+		}
+	}
+	return g
+}
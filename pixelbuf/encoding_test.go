@@ -0,0 +1,107 @@
+package pixelbuf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEncoding(t *testing.T) {
+	for _, name := range EncodingNames() {
+		e, err := ParseEncoding(name)
+		if err != nil || e.String() != name {
+			t.Errorf("ParseEncoding(%q) = %v, %v", name, e, err)
+		}
+	}
+	if _, err := ParseEncoding("sixel"); err == nil {
+		t.Error("ParseEncoding(\"sixel\") should fail")
+	}
+}
+
+func TestEncodingCellSize(t *testing.T) {
+	tests := []struct {
+		e    Encoding
+		w, h int
+	}{
+		{HalfBlock, 1, 2},
+		{Quadrant, 2, 2},
+		{Braille, 2, 4},
+	}
+	for _, tt := range tests {
+		if w, h := tt.e.CellSize(); w != tt.w || h != tt.h {
+			t.Errorf("%v.CellSize() = %d, %d, want %d, %d", tt.e, w, h, tt.w, tt.h)
+		}
+	}
+}
+
+func TestRenderWithQuadrant(t *testing.T) {
+	quad := Options{Encoding: Quadrant}
+	pal := Palette{'r': red, 'b': blue, 'd': {200, 0, 0, 255}, 'k': black}
+	tests := []struct {
+		name string
+		art  []string
+		o    Options
+		want string
+	}{
+		{"one_corner", []string{"rb", "bb"}, quad, fg(red) + bg(blue) + "▘" + reset},
+		{"majority_is_background", []string{"rr", "rb"}, quad, fg(blue) + bg(red) + "▗" + reset},
+		{"diagonal", []string{"rb", "br"}, quad, fg(blue) + bg(red) + "▞" + reset},
+		{"one_color", []string{"bb", "bb"}, quad, bg(blue) + " " + reset},
+		// The dark red is nearer red than blue, so it joins the red.
+		{"best_two_colors", []string{"rr", "db"}, quad, fg(blue) + bg(red) + "▗" + reset},
+		// A tie keeps the top-left pixel's color as the background.
+		{"two_cells", []string{"rbbb", "rbbb"}, quad, fg(blue) + bg(red) + "▐" + bg(blue) + " " + reset},
+		{"256_colors", []string{"rb", "bb"}, Options{Depth: Color256, Encoding: Quadrant}, "\x1b[38;5;196m\x1b[48;5;21m▘" + reset},
+		{"mono", []string{"rr", "kk"}, Options{Depth: Mono, Encoding: Quadrant}, "▀"},
+		{"mono_dark_blank", []string{"kk", "kk"}, Options{Depth: Mono, Encoding: Quadrant}, " "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderWith(MustParseArt(pal, tt.art...), tt.o)
+			if got != tt.want {
+				t.Errorf("RenderWith = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderWithQuadrantPartCells(t *testing.T) {
+	// A 3×3 buffer needs 2×2 cells; the missing pixels are transparent
+	// black, as in half blocks.
+	got := RenderWith(solidBuffer(3, 3, red), Options{Encoding: Quadrant})
+	want := bg(red) + " " + fg(transparent) + "▐" + reset + "\n" +
+		fg(transparent) + bg(red) + "▄" + fg(red) + bg(transparent) + "▘" + reset
+	if got != want {
+		t.Errorf("RenderWith = %q, want %q", got, want)
+	}
+}
+
+func TestRenderWithBraille(t *testing.T) {
+	buf := solidBuffer(2, 4, black)
+	buf.Set(0, 0, white)
+	buf.Set(1, 3, white)
+	if got, want := RenderWith(buf, Options{Encoding: Braille}), fg(white)+bg(black)+"⢁"+reset; got != want {
+		t.Errorf("RenderWith = %q, want %q", got, want)
+	}
+	if got := RenderWith(buf, Options{Depth: Mono, Encoding: Braille}); got != "⢁" {
+		t.Errorf("RenderWith mono = %q, want \"⢁\"", got)
+	}
+
+	// 8×8 pixels make 4 cells across, 2 down.
+	got := RenderWith(solidBuffer(8, 8, green), Options{Encoding: Braille})
+	if lines := strings.Split(got, "\n"); len(lines) != 2 || strings.Count(lines[0], " ") != 4 {
+		t.Errorf("RenderWith 8x8 = %q, want 2 lines of 4 cells", got)
+	}
+}
+
+func TestRendererQuadrant(t *testing.T) {
+	r := NewRenderer()
+	r.Options.Encoding = Quadrant
+	buf := solidBuffer(4, 4, blue)
+	if got, want := r.Render(buf), at(1, 1)+bg(blue)+"  "+at(2, 1)+"  "+reset; got != want {
+		t.Errorf("first frame = %q, want %q", got, want)
+	}
+	buf.Set(3, 3, red)
+	if got, want := r.Render(buf), at(2, 2)+fg(red)+bg(blue)+"▗"+reset; got != want {
+		t.Errorf("one changed pixel = %q, want %q", got, want)
+	}
+}
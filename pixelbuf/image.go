@@ -0,0 +1,181 @@
+package pixelbuf
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+)
+
+// FromImage copies img into a new buffer. The buffer's (0, 0) is the
+// image's top-left corner, whatever its bounds.
+func FromImage(img image.Image) *Buffer {
+	r := img.Bounds()
+	buf := NewBuffer(r.Dx(), r.Dy())
+	for y := 0; y < buf.Height; y++ {
+		for x := 0; x < buf.Width; x++ {
+			c := color.NRGBAModel.Convert(img.At(r.Min.X+x, r.Min.Y+y)).(color.NRGBA)
+			buf.pixels[y*buf.Width+x] = Color{c.R, c.G, c.B, c.A}
+		}
+	}
+	return buf
+}
+
+// Image copies b into a new image.
+func (b *Buffer) Image() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, b.Width, b.Height))
+	for i, c := range b.pixels {
+		copy(img.Pix[i*4:], []uint8{c.R, c.G, c.B, c.A})
+	}
+	return img
+}
+
+// DecodePNG reads a PNG image into a new buffer.
+func DecodePNG(r io.Reader) (*Buffer, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return FromImage(img), nil
+}
+
+// EncodePNG writes b as a PNG image, keeping its transparency.
+func EncodePNG(w io.Writer, b *Buffer) error {
+	return png.Encode(w, b.Image())
+}
+
+// LoadPNG reads the PNG file at path into a new buffer.
+func LoadPNG(path string) (*Buffer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf, err := DecodePNG(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return buf, nil
+}
+
+// SavePNG writes b to a PNG file at path.
+func SavePNG(path string, b *Buffer) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := EncodePNG(f, b); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// maxPPMSize is the largest width or height DecodePPM accepts, so a crafted
+// header can't ask for more memory than any sprite sheet needs.
+const maxPPMSize = 4096
+
+// DecodePPM reads a binary (P6) PPM image into a new, fully opaque buffer.
+func DecodePPM(r io.Reader) (*Buffer, error) {
+	br := bufio.NewReader(r)
+	var magic string
+	var w, h, maxVal int
+	if _, err := fmt.Fscan(br, &magic); err != nil || magic != "P6" {
+		return nil, fmt.Errorf("ppm: not a binary PPM")
+	}
+	for _, v := range []*int{&w, &h, &maxVal} {
+		if err := skipPPMComments(br); err != nil {
+			return nil, fmt.Errorf("ppm: bad header: %w", err)
+		}
+		if _, err := fmt.Fscan(br, v); err != nil {
+			return nil, fmt.Errorf("ppm: bad header: %w", err)
+		}
+	}
+	if w < 1 || h < 1 || w > maxPPMSize || h > maxPPMSize || maxVal < 1 || maxVal > 255 {
+		return nil, fmt.Errorf("ppm: unsupported %dx%d image with maximum %d", w, h, maxVal)
+	}
+	if _, err := br.ReadByte(); err != nil { // the single space before the pixels
+		return nil, fmt.Errorf("ppm: bad header: %w", err)
+	}
+
+	// Read a row at a time, so a file that ends early fails before the whole
+	// image its header promised has been allocated.
+	scale := func(v byte) uint8 { return uint8(int(v) * 255 / maxVal) }
+	pixels := make([]Color, 0, w)
+	row := make([]byte, 3*w)
+	for y := 0; y < h; y++ {
+		if _, err := io.ReadFull(br, row); err != nil {
+			return nil, fmt.Errorf("ppm: short pixel data in row %d: %w", y, err)
+		}
+		for x := 0; x < w; x++ {
+			pixels = append(pixels, Color{scale(row[3*x]), scale(row[3*x+1]), scale(row[3*x+2]), 255})
+		}
+	}
+	return &Buffer{Width: w, Height: h, pixels: pixels}, nil
+}
+
+// skipPPMComments skips whitespace and '#' comments up to the next header
+// field.
+func skipPPMComments(br *bufio.Reader) error {
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch {
+		case c == '#':
+			if _, err := br.ReadString('\n'); err != nil {
+				return err
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		default:
+			return br.UnreadByte()
+		}
+	}
+}
+
+// EncodePPM writes b as a binary (P6) PPM image. PPM has no transparency:
+// pixels are composited over black.
+func EncodePPM(w io.Writer, b *Buffer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P6\n%d %d\n255\n", b.Width, b.Height)
+	for _, c := range b.pixels {
+		c = blend(c, black)
+		bw.Write([]byte{c.R, c.G, c.B})
+	}
+	return bw.Flush()
+}
+
+// Crop returns a copy of the w×h region of b at (x, y). Parts of the region
+// outside b are transparent.
+func Crop(b *Buffer, x, y, w, h int) *Buffer {
+	out := NewBuffer(w, h)
+	for cy := 0; cy < h; cy++ {
+		for cx := 0; cx < w; cx++ {
+			out.pixels[cy*w+cx] = b.At(x+cx, y+cy)
+		}
+	}
+	return out
+}
+
+// SliceGrid cuts a sprite sheet into cellW×cellH frames, left to right and
+// then top to bottom. The sheet must be a whole number of cells each way.
+func SliceGrid(sheet *Buffer, cellW, cellH int) ([]*Buffer, error) {
+	if cellW < 1 || cellH < 1 {
+		return nil, fmt.Errorf("sprite sheet cells must be at least 1x1, not %dx%d", cellW, cellH)
+	}
+	if sheet.Width%cellW != 0 || sheet.Height%cellH != 0 {
+		return nil, fmt.Errorf("sprite sheet is %dx%d, not a grid of %dx%d cells",
+			sheet.Width, sheet.Height, cellW, cellH)
+	}
+	var frames []*Buffer
+	for y := 0; y < sheet.Height; y += cellH {
+		for x := 0; x < sheet.Width; x += cellW {
+			frames = append(frames, Crop(sheet, x, y, cellW, cellH))
+		}
+	}
+	return frames, nil
+}
@@ -0,0 +1,166 @@
+package pixelbuf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPNGRoundTrip(t *testing.T) {
+	src := MustParseArt(testPalette, "rg.", ".bb")
+	src.Set(2, 0, Color{10, 20, 30, 128}) // half transparent survives too
+
+	var w bytes.Buffer
+	if err := EncodePNG(&w, src); err != nil {
+		t.Fatalf("EncodePNG failed: %v", err)
+	}
+	got, err := DecodePNG(&w)
+	if err != nil {
+		t.Fatalf("DecodePNG failed: %v", err)
+	}
+	if got.Width != 3 || got.Height != 2 || !slices.Equal(got.pixels, src.pixels) {
+		t.Errorf("PNG round trip = %v, want %v", got.pixels, src.pixels)
+	}
+}
+
+func TestSaveLoadPNG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "frame.png")
+	src := MustParseArt(testPalette, "rb", "gr")
+	if err := SavePNG(path, src); err != nil {
+		t.Fatalf("SavePNG failed: %v", err)
+	}
+	got, err := LoadPNG(path)
+	if err != nil {
+		t.Fatalf("LoadPNG failed: %v", err)
+	}
+	if !slices.Equal(art(got), art(src)) {
+		t.Errorf("LoadPNG = %q, want %q", art(got), art(src))
+	}
+	if _, err := LoadPNG(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("LoadPNG of a missing file should fail")
+	}
+}
+
+func TestDecodePNGErrors(t *testing.T) {
+	if _, err := DecodePNG(strings.NewReader("not a png")); err == nil {
+		t.Error("DecodePNG of garbage should fail")
+	}
+}
+
+func TestFromImage(t *testing.T) {
+	// A sub-image whose bounds don't start at (0, 0), in a premultiplied
+	// color model.
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(2, 1, color.RGBA{255, 0, 0, 255})
+	img.Set(3, 1, color.RGBA{0, 0, 128, 128}) // 50% blue
+	buf := FromImage(img.SubImage(image.Rect(2, 1, 4, 3)))
+
+	if buf.Width != 2 || buf.Height != 2 {
+		t.Fatalf("FromImage size = %dx%d, want 2x2", buf.Width, buf.Height)
+	}
+	if buf.At(0, 0) != red {
+		t.Errorf("FromImage (0,0) = %v, want red", buf.At(0, 0))
+	}
+	if got, want := buf.At(1, 0), (Color{0, 0, 255, 128}); got != want {
+		t.Errorf("FromImage (1,0) = %v, want %v (straight alpha)", got, want)
+	}
+}
+
+func TestPPMRoundTrip(t *testing.T) {
+	src := MustParseArt(testPalette, "rg.", "bbr")
+	var w bytes.Buffer
+	if err := EncodePPM(&w, src); err != nil {
+		t.Fatalf("EncodePPM failed: %v", err)
+	}
+	if !bytes.HasPrefix(w.Bytes(), []byte("P6\n3 2\n255\n")) {
+		t.Errorf("EncodePPM header = %q", w.Bytes()[:11])
+	}
+	got, err := DecodePPM(&w)
+	if err != nil {
+		t.Fatalf("DecodePPM failed: %v", err)
+	}
+	if got.At(2, 0) != black {
+		t.Errorf("DecodePPM transparent pixel = %v, want black", got.At(2, 0))
+	}
+	got.Set(2, 0, transparent)
+	if !slices.Equal(art(got), art(src)) {
+		t.Errorf("PPM round trip = %q, want %q", art(got), art(src))
+	}
+}
+
+func TestDecodePPM(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []Color
+		err  bool
+	}{
+		{"comments", "P6 # made by hand\n1 # wide\n1\n255\n\xff\x00\x00", []Color{red}, false},
+		{"small_maximum", "P6 2 1 1\n\x01\x01\x01\x00\x00\x01", []Color{white, blue}, false},
+		{"ascii_ppm", "P3 1 1 255\n255 0 0", nil, true},
+		{"short_pixels", "P6 2 1 255\n\xff\x00\x00", nil, true},
+		{"large_maximum", "P6 1 1 65535\n\x00\x00\x00\x00\x00\x00", nil, true},
+		{"zero_width", "P6 0 1 255\n", nil, true},
+		{"negative_height", "P6 1 -1 255\n", nil, true},
+		{"too_large", "P6 5000 1 255\n\x00\x00\x00", nil, true},
+		{"overflowing_size", "P6 99999999999999999999 1 255\n\x00\x00\x00", nil, true},
+		{"huge_but_empty", "P6 4096 4096 255\n\x00\x00\x00", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodePPM(strings.NewReader(tt.in))
+			if tt.err {
+				if err == nil {
+					t.Errorf("DecodePPM(%q) should fail", tt.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodePPM(%q) failed: %v", tt.in, err)
+			}
+			if !slices.Equal(got.pixels, tt.want) {
+				t.Errorf("DecodePPM(%q) = %v, want %v", tt.in, got.pixels, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrop(t *testing.T) {
+	src := MustParseArt(testPalette, "rgb", "bgr")
+	if got, want := art(Crop(src, 1, 0, 2, 2)), []string{"gb", "gr"}; !slices.Equal(got, want) {
+		t.Errorf("Crop = %q, want %q", got, want)
+	}
+	if got, want := art(Crop(src, 2, 1, 2, 2)), []string{"r.", ".."}; !slices.Equal(got, want) {
+		t.Errorf("Crop past the edge = %q, want %q", got, want)
+	}
+}
+
+func TestSliceGrid(t *testing.T) {
+	sheet := MustParseArt(testPalette,
+		"rrgg",
+		"rrgg",
+		"bb..",
+		"bb..",
+	)
+	frames, err := SliceGrid(sheet, 2, 2)
+	if err != nil {
+		t.Fatalf("SliceGrid failed: %v", err)
+	}
+	var got []string
+	for _, f := range frames {
+		got = append(got, art(f)[0])
+	}
+	if want := []string{"rr", "gg", "bb", ".."}; !slices.Equal(got, want) {
+		t.Errorf("SliceGrid first rows = %q, want %q", got, want)
+	}
+
+	for _, cell := range [][2]int{{3, 2}, {2, 3}, {0, 2}} {
+		if _, err := SliceGrid(sheet, cell[0], cell[1]); err == nil {
+			t.Errorf("SliceGrid(%dx%d) of a 4x4 sheet should fail", cell[0], cell[1])
+		}
+	}
+}
@@ -15,9 +15,13 @@ func init() {
 	}
 }
 
-// renderBuf is reused across Render calls. Grow pre-allocates once per call.
-// Single-goroutine game loop — no concurrency concern.
-var renderBuf strings.Builder
+// renderBuf and renderEnc are reused across Render calls. Grow
+// pre-allocates once per call. Single-goroutine game loop — no concurrency
+// concern.
+var (
+	renderBuf strings.Builder
+	renderEnc encoder
+)
 
 // Render converts the buffer to an ANSI string using half-block characters.
 // Each terminal row represents two pixel rows: the upper pixel as the
@@ -25,79 +29,132 @@ var renderBuf strings.Builder
 // Odd-height buffers pair the last row with transparent black.
 // A zero-size buffer returns an empty string.
 func Render(buf *Buffer) string {
+	return RenderWith(buf, Options{})
+}
+
+// RenderWith is Render with a choice of encoding, for more pixels to a cell,
+// and of color depth, for terminals with fewer colors; see Options. Mono
+// half blocks have no escapes at all: each cell is an ASCII character for
+// the brightness of its two pixels.
+func RenderWith(buf *Buffer, o Options) string {
 	if buf.Width == 0 || buf.Height == 0 {
 		return ""
 	}
+	cells, cols, rows := renderEnc.encode(buf, o)
 
 	renderBuf.Reset()
-	renderBuf.Grow(buf.Width * (buf.Height/2 + 1) * 40)
-
-	var lastFG, lastBG Color
-	fgSet, bgSet := false, false
-
-	rows := buf.Height / 2
-	if buf.Height%2 != 0 {
-		rows++
-	}
+	renderBuf.Grow(cols * (rows + 1) * 40)
+	p := painter{sb: &renderBuf, depth: o.Depth}
 
 	for row := 0; row < rows; row++ {
-		y := row * 2
-		for x := 0; x < buf.Width; x++ {
-			top := buf.pixels[y*buf.Width+x]
-
-			var bottom Color
-			if y+1 < buf.Height {
-				bottom = buf.pixels[(y+1)*buf.Width+x]
-			}
-
-			if top == bottom {
-				writeBGCode(&renderBuf, top, &lastBG, &bgSet)
-				renderBuf.WriteByte(' ')
-			} else {
-				writeFGCode(&renderBuf, top, &lastFG, &fgSet)
-				writeBGCode(&renderBuf, bottom, &lastBG, &bgSet)
-				renderBuf.WriteString("▀")
-			}
+		for _, c := range cells[row*cols : (row+1)*cols] {
+			p.cell(c)
 		}
-		renderBuf.WriteString("\x1b[0m")
+		p.reset()
 		if row < rows-1 {
 			renderBuf.WriteByte('\n')
 		}
-		lastFG, lastBG = Color{}, Color{}
-		fgSet, bgSet = false, false
 	}
 
 	return renderBuf.String()
 }
 
-// writeFGCode writes a foreground ANSI escape only if the color changed.
-func writeFGCode(sb *strings.Builder, c Color, last *Color, set *bool) {
-	if *set && c == *last {
-		return
+// inkRows returns the inks of the two pixel rows shown in terminal row row.
+// The bottom row is nil for the last row of an odd-height buffer.
+func inkRows(inks []uint32, buf *Buffer, row int) (top, bottom []uint32) {
+	y := row * 2
+	top = inks[y*buf.Width : (y+1)*buf.Width]
+	if y+1 < buf.Height {
+		bottom = inks[(y+1)*buf.Width : (y+2)*buf.Width]
 	}
-	sb.WriteString("\x1b[38;2;")
-	sb.WriteString(itoa[c.R])
-	sb.WriteByte(';')
-	sb.WriteString(itoa[c.G])
-	sb.WriteByte(';')
-	sb.WriteString(itoa[c.B])
-	sb.WriteByte('m')
-	*last = c
-	*set = true
+	return top, bottom
+}
+
+// inkAt returns inks[x], or the ink of transparent black past the bottom
+// of an odd-height buffer, which is 0 at every depth.
+func inkAt(inks []uint32, x int) uint32 {
+	if inks == nil {
+		return 0
+	}
+	return inks[x]
+}
+
+// painter writes cells at one depth, only sending a color escape
+// when the color changes.
+type painter struct {
+	sb             *strings.Builder
+	depth          Depth
+	lastFG, lastBG uint32
+	fgSet, bgSet   bool
 }
 
-// writeBGCode writes a background ANSI escape only if the color changed.
-func writeBGCode(sb *strings.Builder, c Color, last *Color, set *bool) {
-	if *set && c == *last {
+// cell writes one terminal cell.
+func (p *painter) cell(c cell) {
+	switch {
+	case p.depth == Mono:
+		p.sb.WriteRune(c.glyph)
+	case c.glyph == ' ':
+		p.color(c.bg, true)
+		p.sb.WriteByte(' ')
+	default:
+		p.color(c.fg, false)
+		p.color(c.bg, true)
+		p.sb.WriteRune(c.glyph)
+	}
+}
+
+// reset ends the colors of a run of cells.
+func (p *painter) reset() {
+	if p.depth != Mono && (p.fgSet || p.bgSet) {
+		p.sb.WriteString("\x1b[0m")
+	}
+	p.fgSet, p.bgSet = false, false
+}
+
+// color sets the foreground, or the background if bg is set, to ink, if it
+// isn't already.
+func (p *painter) color(ink uint32, bg bool) {
+	last, set := &p.lastFG, &p.fgSet
+	if bg {
+		last, set = &p.lastBG, &p.bgSet
+	}
+	if *set && ink == *last {
 		return
 	}
-	sb.WriteString("\x1b[48;2;")
-	sb.WriteString(itoa[c.R])
-	sb.WriteByte(';')
-	sb.WriteString(itoa[c.G])
-	sb.WriteByte(';')
-	sb.WriteString(itoa[c.B])
+	*last, *set = ink, true
+
+	sb := p.sb
+	switch p.depth {
+	case TrueColor:
+		if bg {
+			sb.WriteString("\x1b[48;2;")
+		} else {
+			sb.WriteString("\x1b[38;2;")
+		}
+		sb.WriteString(itoa[ink>>24])
+		sb.WriteByte(';')
+		sb.WriteString(itoa[ink>>16&0xff])
+		sb.WriteByte(';')
+		sb.WriteString(itoa[ink>>8&0xff])
+	case Color256:
+		if bg {
+			sb.WriteString("\x1b[48;5;")
+		} else {
+			sb.WriteString("\x1b[38;5;")
+		}
+		sb.WriteString(itoa[ink])
+	case Color16:
+		// 30–37 and 90–97 set the foreground, 40–47 and 100–107 the
+		// background.
+		code := 30 + int(ink)
+		if ink >= 8 {
+			code = 90 + int(ink) - 8
+		}
+		if bg {
+			code += 10
+		}
+		sb.WriteString("\x1b[")
+		writeInt(sb, code)
+	}
 	sb.WriteByte('m')
-	*last = c
-	*set = true
 }
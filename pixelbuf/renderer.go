@@ -0,0 +1,125 @@
+package pixelbuf
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxGap is the longest run of unchanged cells a Renderer repaints rather
+// than skipping with a cursor move, which costs about as much.
+const maxGap = 3
+
+// Renderer draws successive frames to a terminal, like Render, but writes
+// only the cells that changed since the frame before, each run placed with
+// a cursor-positioning escape. The output assumes the terminal shows the
+// previous frame untouched, so it suits writing straight to a terminal
+// rather than through a line-based framework that repaints for itself.
+//
+// Options may be changed between frames; the next frame is then drawn in
+// full.
+type Renderer struct {
+	// Row and Col are where the frame's top-left cell sits on the screen,
+	// counting from 0.
+	Row, Col int
+	Options  Options
+
+	prev        []cell  // the frame on screen
+	prevOptions Options // what the frame on screen was drawn with
+	cols, rows  int     // of the frame on screen; 0 until the first frame
+	enc         encoder
+	changed     []bool // scratch: which cells of a row changed
+	sb          strings.Builder
+}
+
+// NewRenderer creates a renderer whose first frame is drawn in full, in
+// 24-bit color, at the top-left of the screen.
+func NewRenderer() *Renderer {
+	return &Renderer{}
+}
+
+// Invalidate makes the next frame draw in full, for when the screen has
+// been cleared or drawn over.
+func (r *Renderer) Invalidate() {
+	r.cols, r.rows = 0, 0
+}
+
+// Render returns the escapes that turn the previous frame on screen into
+// buf: every cell for the first frame, after Invalidate or when buf changes
+// size, and only the changed cells after that. It returns "" when nothing
+// changed.
+func (r *Renderer) Render(buf *Buffer) string {
+	cells, cols, rows := r.enc.encode(buf, r.Options)
+	full := cols != r.cols || rows != r.rows || r.Options != r.prevOptions
+	if full {
+		r.prev = make([]cell, cols*rows)
+		r.changed = make([]bool, cols)
+		r.cols, r.rows, r.prevOptions = cols, rows, r.Options
+	}
+
+	sb := &r.sb
+	n := sb.Len()
+	sb.Reset()
+	sb.Grow(n)
+	p := painter{sb: sb, depth: r.Options.Depth}
+
+	for row := 0; row < rows; row++ {
+		cur, prev := cells[row*cols:(row+1)*cols], r.prev[row*cols:(row+1)*cols]
+		if !full && slices.Equal(cur, prev) {
+			continue
+		}
+		changed := r.changed
+		for x := range changed {
+			changed[x] = full || cur[x] != prev[x]
+		}
+		copy(prev, cur)
+
+		for x := 0; x < cols; {
+			if !changed[x] {
+				x++
+				continue
+			}
+			// Run on past gaps of up to maxGap unchanged cells.
+			end := x + 1
+			for end < cols {
+				next := end
+				for next < cols && !changed[next] && next-end < maxGap {
+					next++
+				}
+				if next == cols || !changed[next] {
+					break
+				}
+				end = next + 1
+			}
+
+			writeCursor(sb, r.Row+row, r.Col+x)
+			for ; x < end; x++ {
+				p.cell(cur[x])
+			}
+		}
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	p.reset()
+	return sb.String()
+}
+
+// writeCursor writes an escape moving the cursor to the 0-based row and
+// column.
+func writeCursor(sb *strings.Builder, row, col int) {
+	sb.WriteString("\x1b[")
+	writeInt(sb, row+1)
+	sb.WriteByte(';')
+	writeInt(sb, col+1)
+	sb.WriteByte('H')
+}
+
+// writeInt writes n in decimal, from the itoa table when it can.
+func writeInt(sb *strings.Builder, n int) {
+	if n < len(itoa) {
+		sb.WriteString(itoa[n])
+	} else {
+		sb.WriteString(strconv.Itoa(n))
+	}
+}
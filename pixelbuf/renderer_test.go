@@ -0,0 +1,142 @@
+package pixelbuf
+
+import (
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// at returns a cursor-positioning escape for the 1-based row and column.
+func at(row, col int) string {
+	return "\x1b[" + strconv.Itoa(row) + ";" + strconv.Itoa(col) + "H"
+}
+
+func TestRenderer(t *testing.T) {
+	r := NewRenderer()
+	buf := solidBuffer(2, 4, red)
+	if got, want := r.Render(buf), at(1, 1)+bg(red)+"  "+at(2, 1)+"  "+reset; got != want {
+		t.Errorf("first frame = %q, want %q", got, want)
+	}
+	if got := r.Render(buf); got != "" {
+		t.Errorf("unchanged frame = %q, want \"\"", got)
+	}
+
+	buf.Set(1, 3, blue)
+	if got, want := r.Render(buf), at(2, 2)+fg(red)+bg(blue)+"▀"+reset; got != want {
+		t.Errorf("one changed pixel = %q, want %q", got, want)
+	}
+
+	r.Row, r.Col = 5, 10
+	r.Invalidate()
+	if got := r.Render(buf); !strings.HasPrefix(got, at(6, 11)) || !strings.Contains(got, at(7, 11)) {
+		t.Errorf("offset frame after Invalidate = %q, want it drawn in full from row 6, column 11", got)
+	}
+
+	if got := r.Render(solidBuffer(1, 1, red)); got != at(6, 11)+fg(red)+bg(transparent)+"▀"+reset {
+		t.Errorf("resized odd-height frame = %q", got)
+	}
+}
+
+func TestRendererRuns(t *testing.T) {
+	tests := []struct {
+		name    string
+		changed []int // columns changed in a 12-wide row
+		want    string
+	}{
+		{"one_run", []int{2, 3, 4}, at(1, 3) + bg(red) + "   " + reset},
+		{"short_gap_repainted", []int{2, 6}, at(1, 3) + bg(red) + " " + bg(black) + "   " + bg(red) + " " + reset},
+		{"long_gap_skipped", []int{2, 7}, at(1, 3) + bg(red) + " " + at(1, 8) + " " + reset},
+		{"last_column", []int{11}, at(1, 12) + bg(red) + " " + reset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRenderer()
+			buf := solidBuffer(12, 2, black)
+			r.Render(buf)
+			for _, x := range tt.changed {
+				FillRect(buf, x, 0, 1, 2, red)
+			}
+			if got := r.Render(buf); got != tt.want {
+				t.Errorf("Render = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// screenCell is what a terminal cell shows: the two pixels of a half block.
+type screenCell struct {
+	top, bottom Color
+}
+
+// screen is a terminal that understands just what a Renderer writes.
+type screen struct {
+	cells    [][]screenCell
+	row, col int
+	fg, bg   Color
+}
+
+var escape = regexp.MustCompile(`^\x1b\[(\d+);(\d+)H|^\x1b\[(38|48);2;(\d+);(\d+);(\d+)m|^\x1b\[0m`)
+
+func (s *screen) write(t *testing.T, out string) {
+	t.Helper()
+	for out != "" {
+		if m := escape.FindStringSubmatch(out); m != nil {
+			n := func(i int) int { v, _ := strconv.Atoi(m[i]); return v }
+			switch {
+			case m[1] != "":
+				s.row, s.col = n(1)-1, n(2)-1
+			case m[3] == "38":
+				s.fg = Color{uint8(n(4)), uint8(n(5)), uint8(n(6)), 255}
+			case m[3] == "48":
+				s.bg = Color{uint8(n(4)), uint8(n(5)), uint8(n(6)), 255}
+			}
+			out = out[len(m[0]):]
+			continue
+		}
+		switch {
+		case strings.HasPrefix(out, " "):
+			s.cells[s.row][s.col] = screenCell{s.bg, s.bg}
+			out = out[1:]
+		case strings.HasPrefix(out, "▀"):
+			s.cells[s.row][s.col] = screenCell{s.fg, s.bg}
+			out = out[len("▀"):]
+		default:
+			t.Fatalf("unexpected output %q", out)
+		}
+		s.col++
+	}
+}
+
+// TestRendererKeepsScreenInStep draws a run of random frames, each a small
+// change from the last, and checks that the screen always ends up showing
+// the whole of the latest frame.
+func TestRendererKeepsScreenInStep(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	colors := []Color{black, red, green, blue, white}
+	buf := solidBuffer(20, 10, black)
+	s := &screen{cells: make([][]screenCell, 5)}
+	for i := range s.cells {
+		s.cells[i] = make([]screenCell, 20)
+	}
+
+	r := NewRenderer()
+	for frame := range 50 {
+		for range rng.Intn(6) {
+			FillRect(buf, rng.Intn(20), rng.Intn(10), 1+rng.Intn(4), 1+rng.Intn(3), colors[rng.Intn(len(colors))])
+		}
+		s.write(t, r.Render(buf))
+		for y := range 10 {
+			for x := range 20 {
+				got := s.cells[y/2][x].top
+				if y%2 == 1 {
+					got = s.cells[y/2][x].bottom
+				}
+				if got != buf.At(x, y) {
+					t.Fatalf("frame %d: screen (%d,%d) = %v, want %v", frame, x, y, got, buf.At(x, y))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package pixelbuf
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// Palette maps the characters of string art to colors. '.' and ' ' are
+// transparent unless the palette gives them a color.
+type Palette map[rune]Color
+
+// ParseArt builds a buffer from string art: one string per pixel row, one
+// character per pixel, each looked up in p. Every row must be the same width.
+func ParseArt(p Palette, rows ...string) (*Buffer, error) {
+	if len(rows) == 0 {
+		return NewBuffer(0, 0), nil
+	}
+	w := utf8.RuneCountInString(rows[0])
+	buf := NewBuffer(w, len(rows))
+	for y, row := range rows {
+		if n := utf8.RuneCountInString(row); n != w {
+			return nil, fmt.Errorf("art row %d is %d pixels wide, want %d", y, n, w)
+		}
+		x := 0
+		for _, r := range row {
+			c, ok := p[r]
+			if !ok && r != '.' && r != ' ' {
+				return nil, fmt.Errorf("art row %d: %q is not in the palette", y, r)
+			}
+			buf.pixels[y*w+x] = c
+			x++
+		}
+	}
+	return buf, nil
+}
+
+// MustParseArt is ParseArt for art built into the program. It panics if
+// the art is malformed.
+func MustParseArt(p Palette, rows ...string) *Buffer {
+	buf, err := ParseArt(p, rows...)
+	if err != nil {
+		panic("pixelbuf: " + err.Error())
+	}
+	return buf
+}
+
+// FlipH returns a copy of b mirrored left to right.
+func FlipH(b *Buffer) *Buffer {
+	out := NewBuffer(b.Width, b.Height)
+	for y := 0; y < b.Height; y++ {
+		row := y * b.Width
+		for x := 0; x < b.Width; x++ {
+			out.pixels[row+x] = b.pixels[row+b.Width-1-x]
+		}
+	}
+	return out
+}
+
+// BlitScaled draws src onto dst at (dx, dy), stretched or shrunk to w×h
+// pixels by taking the nearest source pixel. Transparency and clipping work
+// as in Blit.
+func BlitScaled(dst, src *Buffer, dx, dy, w, h int) {
+	if w == src.Width && h == src.Height {
+		Blit(dst, src, dx, dy)
+		return
+	}
+	if src.Width == 0 || src.Height == 0 {
+		return
+	}
+	x0, y0 := max(0, -dx), max(0, -dy)
+	x1, y1 := min(w, dst.Width-dx), min(h, dst.Height-dy)
+
+	for y := y0; y < y1; y++ {
+		srcRow := y * src.Height / h * src.Width
+		dstRow := (dy+y)*dst.Width + dx
+		for x := x0; x < x1; x++ {
+			pixel := src.pixels[srcRow+x*src.Width/w]
+			if pixel.A == 0 {
+				continue
+			}
+			if pixel.A == 255 {
+				dst.pixels[dstRow+x] = pixel
+			} else {
+				dst.pixels[dstRow+x] = blend(pixel, dst.pixels[dstRow+x])
+			}
+		}
+	}
+}
+
+// Step is one frame of an animation and how long it shows.
+type Step struct {
+	Frame    string
+	Duration time.Duration
+}
+
+// Animation is a run of a sprite's frames. A looping animation starts over
+// after its last step; any other holds its last frame.
+type Animation struct {
+	Steps []Step
+	Loop  bool
+}
+
+// Duration returns how long the animation takes to play through once.
+func (a Animation) Duration() time.Duration {
+	var d time.Duration
+	for _, s := range a.Steps {
+		d += s.Duration
+	}
+	return d
+}
+
+// Sprite is a character's art: named frames, all the same size, and named
+// animations over them. Each frame is kept mirrored too, so that a
+// character can face either way.
+type Sprite struct {
+	Width, Height int
+
+	frames     map[string][2]*Buffer // as drawn, and mirrored
+	animations map[string]Animation
+}
+
+// NewSprite creates a sprite with no frames.
+func NewSprite() *Sprite {
+	return &Sprite{
+		frames:     make(map[string][2]*Buffer),
+		animations: make(map[string]Animation),
+	}
+}
+
+// AddFrame adds img as the frame called name, replacing any frame of that
+// name. The first frame sets the sprite's size; it panics if a later one
+// is a different size.
+func (s *Sprite) AddFrame(name string, img *Buffer) {
+	if len(s.frames) == 0 {
+		s.Width, s.Height = img.Width, img.Height
+	} else if img.Width != s.Width || img.Height != s.Height {
+		panic(fmt.Sprintf("pixelbuf: frame %q is %dx%d, want %dx%d",
+			name, img.Width, img.Height, s.Width, s.Height))
+	}
+	s.frames[name] = [2]*Buffer{img, FlipH(img)}
+}
+
+// AddAnimation adds a as the animation called name. It panics if a step
+// names a frame the sprite doesn't have or doesn't last any time.
+func (s *Sprite) AddAnimation(name string, a Animation) {
+	if len(a.Steps) == 0 {
+		panic(fmt.Sprintf("pixelbuf: animation %q has no steps", name))
+	}
+	for _, step := range a.Steps {
+		if _, ok := s.frames[step.Frame]; !ok {
+			panic(fmt.Sprintf("pixelbuf: animation %q uses unknown frame %q", name, step.Frame))
+		}
+		if step.Duration <= 0 {
+			panic(fmt.Sprintf("pixelbuf: animation %q shows frame %q for %v", name, step.Frame, step.Duration))
+		}
+	}
+	s.animations[name] = a
+}
+
+// Frame returns the frame called name, mirrored left to right if flip is
+// set, or nil if the sprite has no such frame.
+func (s *Sprite) Frame(name string, flip bool) *Buffer {
+	f, ok := s.frames[name]
+	if !ok {
+		return nil
+	}
+	if flip {
+		return f[1]
+	}
+	return f[0]
+}
+
+// At returns the frame showing t into the animation called name, mirrored
+// if flip is set, or nil if the sprite has no such animation.
+func (s *Sprite) At(name string, t time.Duration, flip bool) *Buffer {
+	a, ok := s.animations[name]
+	if !ok {
+		return nil
+	}
+	if a.Loop {
+		t %= a.Duration()
+	}
+	for _, step := range a.Steps {
+		if t < step.Duration {
+			return s.Frame(step.Frame, flip)
+		}
+		t -= step.Duration
+	}
+	return s.Frame(a.Steps[len(a.Steps)-1].Frame, flip)
+}
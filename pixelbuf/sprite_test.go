@@ -0,0 +1,213 @@
+package pixelbuf
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testPalette = Palette{'r': red, 'g': green, 'b': blue}
+
+// art turns a buffer back into string art in testPalette, with '.' for
+// transparent pixels and '?' for colors outside it.
+func art(b *Buffer) []string {
+	var rows []string
+	for y := 0; y < b.Height; y++ {
+		var sb strings.Builder
+		for x := 0; x < b.Width; x++ {
+			c, ch := b.At(x, y), '?'
+			if c == transparent {
+				ch = '.'
+			}
+			for r, pc := range testPalette {
+				if c == pc {
+					ch = r
+				}
+			}
+			sb.WriteRune(ch)
+		}
+		rows = append(rows, sb.String())
+	}
+	return rows
+}
+
+// --- String art ---
+
+func TestParseArt(t *testing.T) {
+	buf, err := ParseArt(testPalette,
+		"r.g",
+		"b g",
+	)
+	if err != nil {
+		t.Fatalf("ParseArt failed: %v", err)
+	}
+	if buf.Width != 3 || buf.Height != 2 {
+		t.Fatalf("ParseArt size = %dx%d, want 3x2", buf.Width, buf.Height)
+	}
+	if got, want := art(buf), []string{"r.g", "b.g"}; !slices.Equal(got, want) {
+		t.Errorf("ParseArt = %q, want %q", got, want)
+	}
+}
+
+func TestParseArtPaletteOverridesDot(t *testing.T) {
+	buf := MustParseArt(Palette{'.': white}, "..")
+	if buf.At(1, 0) != white {
+		t.Errorf("ParseArt '.' with a palette entry: (1,0) = %v, want white", buf.At(1, 0))
+	}
+}
+
+func TestParseArtUnicode(t *testing.T) {
+	buf := MustParseArt(Palette{'█': red, '░': blue}, "█░", "░█")
+	if got, want := art(buf), []string{"rb", "br"}; !slices.Equal(got, want) {
+		t.Errorf("ParseArt = %q, want %q", got, want)
+	}
+}
+
+func TestParseArtErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []string
+		want string
+	}{
+		{"ragged_rows", []string{"rr", "r"}, "row 1"},
+		{"unknown_character", []string{"rx"}, `'x'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseArt(testPalette, tt.rows...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseArt(%q) error = %v, want one mentioning %s", tt.rows, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMustParseArtPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustParseArt with bad art did not panic")
+		}
+	}()
+	MustParseArt(testPalette, "x")
+}
+
+// --- Flipping and scaling ---
+
+func TestFlipH(t *testing.T) {
+	src := MustParseArt(testPalette, "rg.", "b..")
+	got := art(FlipH(src))
+	if want := []string{".gr", "..b"}; !slices.Equal(got, want) {
+		t.Errorf("FlipH = %q, want %q", got, want)
+	}
+	if art(src)[0] != "rg." {
+		t.Errorf("FlipH changed its source: %q", art(src))
+	}
+}
+
+func TestBlitScaled(t *testing.T) {
+	src := MustParseArt(testPalette, "r.", "gb")
+	tests := []struct {
+		name         string
+		dx, dy, w, h int
+		want         []string
+	}{
+		{"same_size", 0, 0, 2, 2, []string{"r...", "gb..", "....", "...."}},
+		{"doubled", 0, 0, 4, 4, []string{"rr..", "rr..", "ggbb", "ggbb"}},
+		{"halved", 1, 1, 1, 1, []string{"....", ".r..", "....", "...."}},
+		{"stretched_wide", 0, 2, 4, 2, []string{"....", "....", "rr..", "ggbb"}},
+		{"clipped", -2, 0, 4, 4, []string{"....", "....", "bb..", "bb.."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := NewBuffer(4, 4)
+			BlitScaled(dst, src, tt.dx, tt.dy, tt.w, tt.h)
+			if got := art(dst); !slices.Equal(got, tt.want) {
+				t.Errorf("BlitScaled(%d, %d, %d, %d) = %q, want %q", tt.dx, tt.dy, tt.w, tt.h, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlitScaledEmpty(t *testing.T) {
+	dst := solidBuffer(2, 2, blue)
+	BlitScaled(dst, NewBuffer(0, 0), 0, 0, 2, 2) // should not panic
+	BlitScaled(dst, solidBuffer(1, 1, red), 0, 0, 0, 0)
+	if dst.At(0, 0) != blue {
+		t.Errorf("BlitScaled empty: (0,0) = %v, want blue", dst.At(0, 0))
+	}
+}
+
+// --- Sprites and animation ---
+
+func testSprite() *Sprite {
+	s := NewSprite()
+	s.AddFrame("a", MustParseArt(testPalette, "r."))
+	s.AddFrame("b", MustParseArt(testPalette, "g."))
+	s.AddFrame("c", MustParseArt(testPalette, "b."))
+	steps := []Step{{"a", 100 * time.Millisecond}, {"b", 50 * time.Millisecond}, {"c", 100 * time.Millisecond}}
+	s.AddAnimation("loop", Animation{Steps: steps, Loop: true})
+	s.AddAnimation("once", Animation{Steps: steps})
+	return s
+}
+
+func TestSpriteAt(t *testing.T) {
+	s := testSprite()
+	tests := []struct {
+		anim string
+		t    time.Duration
+		want string
+	}{
+		{"loop", 0, "r."},
+		{"loop", 99 * time.Millisecond, "r."},
+		{"loop", 100 * time.Millisecond, "g."},
+		{"loop", 200 * time.Millisecond, "b."},
+		{"loop", 250 * time.Millisecond, "r."}, // starts over
+		{"loop", 360 * time.Millisecond, "g."},
+		{"once", 250 * time.Millisecond, "b."}, // holds the last frame
+		{"once", time.Hour, "b."},
+	}
+	for _, tt := range tests {
+		if got := art(s.At(tt.anim, tt.t, false))[0]; got != tt.want {
+			t.Errorf("At(%q, %v) = %q, want %q", tt.anim, tt.t, got, tt.want)
+		}
+	}
+	if got := art(s.At("loop", 120*time.Millisecond, true))[0]; got != ".g" {
+		t.Errorf("At flipped = %q, want \".g\"", got)
+	}
+	if s.At("missing", 0, false) != nil || s.Frame("missing", false) != nil {
+		t.Error("At and Frame of a missing name should be nil")
+	}
+	if d := s.animations["loop"].Duration(); d != 250*time.Millisecond {
+		t.Errorf("Duration() = %v, want 250ms", d)
+	}
+}
+
+func TestSpriteSize(t *testing.T) {
+	s := testSprite()
+	if s.Width != 2 || s.Height != 1 {
+		t.Errorf("sprite size = %dx%d, want 2x1", s.Width, s.Height)
+	}
+}
+
+func TestSpritePanics(t *testing.T) {
+	tests := []struct {
+		name string
+		add  func(s *Sprite)
+	}{
+		{"frame_wrong_size", func(s *Sprite) { s.AddFrame("d", solidBuffer(3, 1, red)) }},
+		{"unknown_frame", func(s *Sprite) { s.AddAnimation("x", Animation{Steps: []Step{{"d", time.Second}}}) }},
+		{"zero_duration", func(s *Sprite) { s.AddAnimation("x", Animation{Steps: []Step{{"a", 0}}}) }},
+		{"no_steps", func(s *Sprite) { s.AddAnimation("x", Animation{Loop: true}) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s did not panic", tt.name)
+				}
+			}()
+			tt.add(testSprite())
+		})
+	}
+}
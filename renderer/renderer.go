@@ -14,6 +14,7 @@ type MapView struct {
 	CurrentLocationName string
 	TurnsTaken          int
 	Score               int
+	HP, MaxHP           int
 	VisitedRooms        map[string]bool
 }
 
@@ -191,6 +192,7 @@ func RenderHUD(view MapView) string {
 	b.WriteString(fmt.Sprintf("Location: %s\n", view.CurrentLocationName))
 	b.WriteString(fmt.Sprintf("Turns: %d\n", view.TurnsTaken))
 	b.WriteString(fmt.Sprintf("Score: %d\n", view.Score))
+	b.WriteString(fmt.Sprintf("HP: %d/%d\n", view.HP, view.MaxHP))
 	b.WriteString(strings.Repeat("-", 50)) // A separator line
 	return b.String()
 }
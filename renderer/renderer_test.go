@@ -205,12 +205,15 @@ func TestRenderHUD(t *testing.T) {
 		CurrentLocationName: "Test Room",
 		TurnsTaken:          42,
 		Score:               75,
+		HP:                  3,
+		MaxHP:               5,
 	}
 
 	actual := RenderHUD(view)
 	expected := "Location: Test Room\n" +
 		"Turns: 42\n" +
 		"Score: 75\n" +
+		"HP: 3/5\n" +
 		"--------------------------------------------------"
 
 	if actual != expected {
@@ -296,12 +299,15 @@ func TestRenderHUD_ZeroScore(t *testing.T) {
 		CurrentLocationName: "Start",
 		TurnsTaken:          0,
 		Score:               5,
+		HP:                  5,
+		MaxHP:               5,
 	}
 
 	actual := RenderHUD(view)
 	expected := "Location: Start\n" +
 		"Turns: 0\n" +
 		"Score: 5\n" +
+		"HP: 5/5\n" +
 		"--------------------------------------------------"
 
 	if actual != expected {
@@ -0,0 +1,95 @@
+package main
+
+import (
+	tea "charm.land/bubbletea/v2"
+	"text-adventure-v2/game"
+	"text-adventure-v2/pixelbuf"
+	"text-adventure-v2/world"
+)
+
+// mode is which sub-model the root model is showing.
+type mode int
+
+const (
+	modeExplore mode = iota
+	modeCombat
+)
+
+// startCombatMsg asks the root model to leave exploration for a fight.
+type startCombatMsg struct {
+	enemy       *world.Enemy
+	arena       string // see arenaFor
+	playerHP    int
+	playerMaxHP int
+}
+
+// combatResultMsg hands a finished fight back to exploration.
+type combatResultMsg struct {
+	result game.FightResult
+}
+
+// model is the root model. It owns the exploration and combat sub-models and
+// switches between them on startCombatMsg and combatResultMsg; only the active
+// one sees input.
+type model struct {
+	mode    mode
+	explore exploreModel
+	combat  combatModel
+
+	// Remembered for fights started later: the terminal size, whether it
+	// reports key releases, and how to draw on it.
+	width, height  int
+	hasKeyReleases bool
+	opts           pixelbuf.Options
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+
+	case tea.KeyboardEnhancementsMsg:
+		m.hasKeyReleases = msg.SupportsEventTypes()
+
+	case tea.ColorProfileMsg:
+		if *colorsFlag == "auto" {
+			m.opts = pixelbuf.OptionsFor(msg.Profile, m.opts.Encoding)
+		}
+
+	case startCombatMsg:
+		m.mode = modeCombat
+		m.combat = newCombatModel(msg, m.width, m.height, m.hasKeyReleases, m.opts)
+		return m, m.combat.Init()
+
+	case combatResultMsg:
+		m.mode = modeExplore
+		m.explore.endFight(msg.result)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	switch m.mode {
+	case modeCombat:
+		m.combat, cmd = m.combat.Update(msg)
+	default:
+		m.explore, cmd = m.explore.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m model) View() tea.View {
+	var v tea.View
+	switch m.mode {
+	case modeCombat:
+		v = tea.NewView(m.combat.View())
+		v.KeyboardEnhancements.ReportEventTypes = true
+	default:
+		v = tea.NewView(m.explore.View())
+	}
+	v.AltScreen = true
+	return v
+}
@@ -1,15 +1,90 @@
 package world
 
+// BehaviorKind identifies something an item can do when used.
+type BehaviorKind string
+
+const (
+	BehaviorKey        BehaviorKind = "key"        // unlocks a door
+	BehaviorLight      BehaviorKind = "light"      // lights up dark rooms while lit
+	BehaviorConsumable BehaviorKind = "consumable" // used up after one use
+	BehaviorReadable   BehaviorKind = "readable"   // shows Text when read
+	BehaviorTool       BehaviorKind = "tool"       // clears an obstacle blocking an exit
+)
+
+// Behavior is one typed effect of an item. An item may carry several, e.g. a
+// scroll that is both readable and consumable.
+type Behavior struct {
+	Kind  BehaviorKind
+	KeyID string // key: which locks this key fits (see Exit.KeyID); tool: which obstacle it clears
+	Text  string // readable: the writing; consumable: message shown when used
+	HP    int    // consumable: health it restores
+}
+
 // Item represents an object that can be picked up and dropped.
 type Item struct {
 	Name        string
 	Description string
+	Behaviors   []Behavior
+	Lit         bool // light sources only: whether it is currently lit
+}
+
+// Behavior returns the item's behavior of the given kind, if it has one.
+func (i *Item) Behavior(kind BehaviorKind) (Behavior, bool) {
+	for _, b := range i.Behaviors {
+		if b.Kind == kind {
+			return b, true
+		}
+	}
+	return Behavior{}, false
 }
 
 // Exit represents a connection from one room to another.
 type Exit struct {
 	Room   *Room
 	Locked bool
+	KeyID  string // the key that opens this lock; empty means any key fits
+
+	// LockText describes the locked door, e.g. "A heavy oak door bars the way."
+	// Empty means a plain door.
+	LockText string
+
+	// Obstacle blocks the way until cleared with a tool whose KeyID matches,
+	// e.g. "rubble". Empty means nothing is in the way.
+	Obstacle string
+}
+
+// NPC is a character the player can talk to.
+type NPC struct {
+	Name        string
+	Description string
+	Lines       []string // said one per conversation, in order; the last one repeats
+	Said        int      // how many times the player has talked to them
+	Dialogue    string   // ID of their dialogue tree; empty if they only say their lines
+}
+
+// EnemyProfile is how an enemy behaves in a fight.
+type EnemyProfile string
+
+const (
+	ProfileAggressive EnemyProfile = "aggressive" // spots the player from afar and presses the attack
+	ProfileCautious   EnemyProfile = "cautious"   // keeps its distance and strikes when the player is close
+	ProfileSentry     EnemyProfile = "sentry"     // holds its ground and only attacks within reach
+)
+
+// Enemy is a hostile that fights the player when they walk into its room.
+type Enemy struct {
+	Name        string
+	Description string
+	HP          int
+	Damage      int // health a hit from it costs the player
+	Profile     EnemyProfile
+	Loot        []*Item // dropped in its room when it is defeated
+}
+
+// Trap hurts the player the first time they walk into its room.
+type Trap struct {
+	Text   string // what happens, e.g. "A dart whistles out of the wall."
+	Damage int
 }
 
 // Room represents a location in the game world.
@@ -18,7 +93,11 @@ type Room struct {
 	Description string
 	Exits       map[string]*Exit
 	Items       []*Item
+	NPCs        []*NPC
+	Enemy       *Enemy // fights the player on entry; nil once defeated
+	Trap        *Trap  // springs on entry; nil once sprung
 	X, Y        int
+	Dark        bool // items, characters and description are hidden without a lit light source
 }
 
 // Player represents the user in the game.
@@ -26,4 +105,6 @@ type Player struct {
 	Name      string
 	Location  *Room
 	Inventory []*Item
+	HP        int
+	MaxHP     int
 }